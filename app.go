@@ -2,16 +2,23 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"adaru-db-tool/internal/connection"
 	"adaru-db-tool/internal/migration"
+	"adaru-db-tool/internal/schema/converter"
 	"adaru-db-tool/internal/storage"
 	"adaru-db-tool/internal/types"
 	"adaru-db-tool/internal/validation"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gopkg.in/yaml.v3"
 )
 
 // App struct holds the application state
@@ -248,12 +255,36 @@ func (a *App) PauseMigration() error {
 	return nil
 }
 
-// ResumeMigration resumes the current migration
-func (a *App) ResumeMigration() error {
-	if a.migrationEngine == nil {
-		return fmt.Errorf("no active migration")
+// ResumeMigration resumes a paused migration, or restarts an interrupted one from its checkpoints
+func (a *App) ResumeMigration(migrationID string) error {
+	if a.migrationEngine != nil && a.migrationEngine.MigrationID() == migrationID {
+		if state := a.migrationEngine.GetStatus(); state != nil {
+			switch state.Status {
+			case types.MigrationStatusPaused:
+				a.migrationEngine.Resume()
+				return nil
+			case types.MigrationStatusRunning:
+				return fmt.Errorf("migration %s is still running", migrationID)
+			}
+		}
+	}
+
+	record, config, err := a.loadMigrationConfig(migrationID)
+	if err != nil {
+		return err
+	}
+	if record.Status == types.MigrationStatusCompleted {
+		return fmt.Errorf("migration %s already completed", migrationID)
 	}
-	a.migrationEngine.Resume()
+
+	engine := migration.NewEngine(a.ctx, a.storage)
+	if err := engine.Configure(config); err != nil {
+		return err
+	}
+	if err := engine.StartResume(migrationID); err != nil {
+		return err
+	}
+	a.migrationEngine = engine
 	return nil
 }
 
@@ -266,6 +297,22 @@ func (a *App) CancelMigration() error {
 	return nil
 }
 
+// SetThrottle changes the source read limits of the active migration; zero removes a limit
+func (a *App) SetThrottle(rowsPerSec int, mbPerSec float64) error {
+	if a.migrationEngine == nil {
+		return fmt.Errorf("no active migration")
+	}
+	return a.migrationEngine.SetThrottle(rowsPerSec, mbPerSec)
+}
+
+// Cutover ends continuous replication after applying the final changes
+func (a *App) Cutover() error {
+	if a.migrationEngine == nil {
+		return fmt.Errorf("no active migration")
+	}
+	return a.migrationEngine.Cutover()
+}
+
 // GetMigrationStatus returns the current migration status
 func (a *App) GetMigrationStatus() *migration.MigrationState {
 	if a.migrationEngine == nil {
@@ -300,6 +347,275 @@ func (a *App) GetMigrationTables(migrationID string) ([]types.TableMigrationStat
 	return a.storage.GetTableMigrations(migrationID)
 }
 
+// GetDDLScript returns the DDL script generated by a dry-run migration
+func (a *App) GetDDLScript(migrationID string) ([]types.ScriptFile, error) {
+	return a.storage.GetDDLScript(migrationID)
+}
+
+// ExportDDLScript writes the DDL script of a dry-run migration as .sql files into a directory chosen by the user.
+// It returns the directory, or "" when the dialog was cancelled.
+func (a *App) ExportDDLScript(migrationID string) (string, error) {
+	files, err := a.storage.GetDDLScript(migrationID)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("migration %s has no DDL script", migrationID)
+	}
+
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:                "Export DDL script",
+		CanCreateDirectories: true,
+	})
+	if err != nil || dir == "" {
+		return "", err
+	}
+
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f.Name), []byte(f.Content), 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", f.Name, err)
+		}
+	}
+	return dir, nil
+}
+
+// GetForeignKeyChecks returns the foreign keys a migration created or validated, with the orphaned rows found
+func (a *App) GetForeignKeyChecks(migrationID string) ([]types.ForeignKeyCheck, error) {
+	return a.storage.GetForeignKeyChecks(migrationID)
+}
+
+// GetIndexBuilds returns the deferred index builds of a migration with their durations and errors
+func (a *App) GetIndexBuilds(migrationID string) ([]types.IndexBuild, error) {
+	return a.storage.GetIndexBuilds(migrationID)
+}
+
+// GetQuarantinedRows returns the rows a migration quarantined because the target rejected them
+func (a *App) GetQuarantinedRows(migrationID string) ([]types.QuarantinedRow, error) {
+	return a.storage.GetQuarantinedRows(migrationID, 1000)
+}
+
+// GetTextIssues returns the non-Unicode string values of a migration that held undecodable bytes or end-user-defined characters
+func (a *App) GetTextIssues(migrationID string) ([]types.TextIssue, error) {
+	return a.storage.GetTextIssues(migrationID, 1000)
+}
+
+// GetRetryTables returns the tables of a migration whose last status was failed, cancelled or interrupted
+// (for rerunning only those tables, ordered by migrate_order)
+func (a *App) GetRetryTables(migrationID string) ([]string, error) {
+	_, config, err := a.loadMigrationConfig(migrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 未指定表格清單的遷移會處理所有表格，從來源重新列出才能找到尚未執行到的表格
+	planned := config.IncludeTables
+	if len(planned) == 0 {
+		tables, err := a.GetTables(config.SourceConnectionString, config.SourceDatabase)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tables {
+			planned = append(planned, t.Schema+"."+t.Name)
+		}
+	}
+
+	results, err := a.storage.GetTableResults(migrationID)
+	if err != nil {
+		return nil, err
+	}
+	return migration.RetryTables(planned, results), nil
+}
+
+// loadMigrationConfig restores the config of a migration record, including its table list
+func (a *App) loadMigrationConfig(migrationID string) (*types.MigrationRecord, *types.MigrationConfig, error) {
+	record, err := a.storage.GetMigration(migrationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if record == nil {
+		return nil, nil, fmt.Errorf("migration %s not found", migrationID)
+	}
+
+	var config types.MigrationConfig
+	if err := json.Unmarshal([]byte(record.Config), &config); err != nil {
+		return nil, nil, fmt.Errorf("invalid migration config: %w", err)
+	}
+
+	// IncludeTables 另存於 migration_tables，依原順序還原
+	tables, err := a.storage.GetTableMigrations(migrationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, t := range tables {
+		fullName := t.TableName
+		if t.SchemaName != "" {
+			fullName = t.SchemaName + "." + t.TableName
+		}
+		config.IncludeTables = append(config.IncludeTables, fullName)
+	}
+	return record, &config, nil
+}
+
+// ========== Type Mapping Methods ==========
+
+// GetTypeMappingRuleSets returns the stored type mapping rule sets
+func (a *App) GetTypeMappingRuleSets() ([]types.TypeMappingRuleSet, error) {
+	return a.storage.GetTypeMappingRuleSets()
+}
+
+// ImportTypeMappingRules imports a rule set from a JSON or YAML file the user picks, replacing the set with the same name.
+// The set is named after the file when the document has no name. Returns nil when the dialog is cancelled.
+func (a *App) ImportTypeMappingRules() (*types.TypeMappingRuleSet, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Import type mapping rules",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Rule sets (*.yaml, *.yml, *.json)", Pattern: "*.yaml;*.yml;*.json"},
+		},
+	})
+	if err != nil || path == "" {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set, err := converter.ParseTypeRuleSet(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if set.Name == "" {
+		set.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := a.storage.SaveTypeMappingRuleSet(set); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// ExportTypeMappingRuleSet writes a rule set to a file the user picks, as YAML or as JSON by its extension
+func (a *App) ExportTypeMappingRuleSet(id string) (string, error) {
+	set, err := a.storage.GetTypeMappingRuleSet(id)
+	if err != nil {
+		return "", err
+	}
+	if set == nil {
+		return "", fmt.Errorf("type mapping rule set %s not found", id)
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export type mapping rules",
+		DefaultFilename: set.Name + ".yaml",
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	// 只匯出可再匯入的欄位
+	doc := struct {
+		Name        string                  `json:"name" yaml:"name"`
+		Description string                  `json:"description,omitempty" yaml:"description,omitempty"`
+		Rules       []types.TypeMappingRule `json:"rules" yaml:"rules"`
+	}{set.Name, set.Description, set.Rules}
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err = json.MarshalIndent(doc, "", "  ")
+	} else {
+		data, err = yaml.Marshal(doc)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// DeleteTypeMappingRuleSet deletes a rule set; migrations that used it keep a copy of its rules
+func (a *App) DeleteTypeMappingRuleSet(id string) error {
+	return a.storage.DeleteTypeMappingRuleSet(id)
+}
+
+// ========== Identifier Naming Methods ==========
+
+// ExportNameMappings writes the mapping document of a migration config to a file the user picks:
+// every schema, table, column, index and foreign key of the selected tables with the name it gets on the target.
+// It is written as CSV, or as JSON when the file has a .json extension. Returns "" when the dialog is cancelled.
+func (a *App) ExportNameMappings(config *types.MigrationConfig) (string, error) {
+	names, err := converter.NewNamer(config.NamingPolicy, config.IdentifierRenames)
+	if err != nil {
+		return "", err
+	}
+
+	conn := connection.NewMSSQLConnection(config.SourceConnectionString)
+	if err := conn.Connect(a.ctx); err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if err := conn.SetDatabase(config.SourceDatabase); err != nil {
+		return "", err
+	}
+	tables, err := conn.GetTables(a.ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// 與遷移相同：指定表格時依其順序
+	if len(config.IncludeTables) > 0 {
+		byName := make(map[string]types.TableInfo)
+		for _, t := range tables {
+			byName[t.Schema+"."+t.Name] = t
+		}
+		tables = nil
+		for _, name := range config.IncludeTables {
+			if t, ok := byName[name]; ok {
+				tables = append(tables, t)
+			}
+		}
+	}
+	details := make([]types.TableInfo, 0, len(tables))
+	for _, t := range tables {
+		d, err := conn.GetTableDetails(a.ctx, t.Schema, t.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s.%s: %w", t.Schema, t.Name, err)
+		}
+		details = append(details, *d)
+	}
+	mappings := names.NameMappings(details)
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export name mappings",
+		DefaultFilename: config.SourceDatabase + "-names.csv",
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if data, err = json.MarshalIndent(mappings, "", "  "); err != nil {
+			return "", err
+		}
+	} else {
+		var sb strings.Builder
+		w := csv.NewWriter(&sb)
+		w.Write([]string{"kind", "source", "target"})
+		for _, m := range mappings {
+			w.Write([]string{m.Kind, m.Source, m.Target})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return "", err
+		}
+		data = []byte(sb.String())
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
 // ========== Validation Methods ==========
 
 // StartValidation starts data validation
@@ -2,16 +2,17 @@ import { useTranslation } from 'react-i18next';
 
 interface RerunBannerProps {
   name: string | null;
+  retryFailed?: boolean;
   onClear: () => void;
 }
 
-export default function RerunBanner({ name, onClear }: RerunBannerProps) {
+export default function RerunBanner({ name, retryFailed, onClear }: RerunBannerProps) {
   const { t } = useTranslation();
 
   return (
     <div className="bg-accent-light/30 border border-accent/50 rounded-lg px-4 py-3 mb-5 flex items-center justify-between gap-4">
       <span className="text-text-primary text-sm">
-        {t('migration.rerunBanner')}
+        {t(retryFailed ? 'migration.retryFailedBanner' : 'migration.rerunBanner')}
         {name ? `: ${name}` : ''}
       </span>
       <button
@@ -1,6 +1,6 @@
 import { useState, useEffect, useCallback } from 'react';
 import { useNavigate } from 'react-router-dom';
-import { GetMigration, GetMigrationTables } from '../../wailsjs/go/main/App';
+import { GetMigration, GetMigrationTables, GetRetryTables } from '../../wailsjs/go/main/App';
 import type { MigrationConfig } from '../types';
 
 export interface RerunTableItem {
@@ -11,6 +11,7 @@ export interface RerunTableItem {
 
 export interface UseRerunMigrationResult {
   isRerunMode: boolean;
+  retryFailed: boolean;
   originalName: string | null;
   config: MigrationConfig | null;
   tables: string[];
@@ -36,8 +37,9 @@ function tableListToFullNames(items: RerunTableItem[]): string[] {
 /**
  * 依 rerunId 載入歷史遷移設定，供 Migration 頁面預填表單。
  * 回傳 config、表格清單（依 migrate_order）、originalName，及 clearRerun 清除參數。
+ * retryFailed 為 true 時只保留上次失敗、取消或中斷的表格。
  */
-export function useRerunMigration(rerunId: string | null): UseRerunMigrationResult {
+export function useRerunMigration(rerunId: string | null, retryFailed = false): UseRerunMigrationResult {
   const navigate = useNavigate();
   const [config, setConfig] = useState<MigrationConfig | null>(null);
   const [tables, setTables] = useState<string[]>([]);
@@ -60,8 +62,14 @@ export function useRerunMigration(rerunId: string | null): UseRerunMigrationResu
     setIsLoading(true);
     setError(null);
 
-    Promise.all([GetMigration(rerunId), GetMigrationTables(rerunId)])
-      .then(([record, tableStates]) => {
+    const loadTables = retryFailed
+      ? GetRetryTables(rerunId)
+      : GetMigrationTables(rerunId).then((tableStates) =>
+          tableListToFullNames((tableStates || []) as RerunTableItem[])
+        );
+
+    Promise.all([GetMigration(rerunId), loadTables])
+      .then(([record, fullNames]) => {
         if (cancelled) return;
         if (!record) {
           setError('Migration record not found');
@@ -73,10 +81,7 @@ export function useRerunMigration(rerunId: string | null): UseRerunMigrationResu
         const parsed = parseConfigJson(record.config || '{}');
         setConfig(parsed);
         setOriginalName(record.name || null);
-        const fullNames = tableListToFullNames(
-          (tableStates || []) as RerunTableItem[]
-        );
-        setTables(fullNames);
+        setTables(fullNames || []);
       })
       .catch((e: unknown) => {
         if (!cancelled) {
@@ -93,7 +98,7 @@ export function useRerunMigration(rerunId: string | null): UseRerunMigrationResu
     return () => {
       cancelled = true;
     };
-  }, [rerunId]);
+  }, [rerunId, retryFailed]);
 
   const clearRerun = useCallback(() => {
     navigate('/migration', { replace: true, state: {} });
@@ -105,6 +110,7 @@ export function useRerunMigration(rerunId: string | null): UseRerunMigrationResu
 
   return {
     isRerunMode,
+    retryFailed,
     originalName,
     config,
     tables,
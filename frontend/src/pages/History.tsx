@@ -6,16 +6,48 @@ import { useMigrationStore } from '../stores/migrationStore';
 export default function History() {
   const { t, i18n } = useTranslation();
   const navigate = useNavigate();
-  const { history, logs, loadHistory, loadLogs } = useMigrationStore();
+  const {
+    history,
+    logs,
+    script,
+    quarantined,
+    textIssues,
+    indexBuilds,
+    migrationTables,
+    foreignKeyChecks,
+    loadHistory,
+    loadLogs,
+    loadScript,
+    loadQuarantined,
+    loadTextIssues,
+    loadIndexBuilds,
+    loadMigrationTables,
+    loadForeignKeyChecks,
+    exportScript,
+    resumeMigration
+  } = useMigrationStore();
   const [selectedMigration, setSelectedMigration] = useState<string | null>(null);
+  const [scriptFile, setScriptFile] = useState(0);
+  const [exportedTo, setExportedTo] = useState('');
 
   useEffect(() => {
     loadHistory();
   }, [loadHistory]);
 
+  // 依主鍵分頁是常態，只列出無主鍵而改用其他策略的表格
+  const heapTables = migrationTables.filter((table) => table.readStrategy && table.readStrategy !== 'keyset');
+
   const handleSelectMigration = async (id: string) => {
     setSelectedMigration(id);
-    await loadLogs(id);
+    setScriptFile(0);
+    setExportedTo('');
+    await Promise.all([loadLogs(id), loadScript(id), loadQuarantined(id), loadTextIssues(id), loadIndexBuilds(id), loadMigrationTables(id), loadForeignKeyChecks(id)]);
+  };
+
+  const handleExportScript = async () => {
+    if (!selectedMigration) return;
+    const dir = await exportScript(selectedMigration);
+    if (dir) setExportedTo(dir);
   };
 
   const getStatusClass = (status: string) => {
@@ -86,6 +118,11 @@ export default function History() {
                   <div className="text-sm text-text-muted mb-1">
                     <span>{migration.sourceDatabase} → {migration.targetDatabase}</span>
                   </div>
+                  {migration.parentId && (
+                    <div className="text-sm text-text-muted mb-1">
+                      {t('history.rerunOf')}: {history.find((h) => h.id === migration.parentId)?.name || migration.parentId}
+                    </div>
+                  )}
                   <div className="flex gap-4 text-sm text-text-muted mb-1">
                     <span>
                       {migration.completedTables}/{migration.totalTables} {t('history.tables')}
@@ -98,16 +135,44 @@ export default function History() {
                     <span className="text-sm text-text-muted">
                       {formatDate(migration.createdAt)}
                     </span>
-                    <button
-                      type="button"
-                      onClick={(e) => {
-                        e.stopPropagation();
-                        navigate('/migration', { state: { rerunId: migration.id } });
-                      }}
-                      className="text-xs px-2 py-1 rounded border border-border bg-card-bg text-text-secondary hover:bg-accent hover:text-white transition-colors"
-                    >
-                      {t('history.rerun')}
-                    </button>
+                    <div className="flex gap-2">
+                      {['failed', 'cancelled', 'paused'].includes(migration.status) && (
+                        <button
+                          type="button"
+                          onClick={async (e) => {
+                            e.stopPropagation();
+                            await resumeMigration(migration.id);
+                            navigate('/migration');
+                          }}
+                          className="text-xs px-2 py-1 rounded border border-border bg-card-bg text-text-secondary hover:bg-accent hover:text-white transition-colors"
+                        >
+                          {t('history.resume')}
+                        </button>
+                      )}
+                      <button
+                        type="button"
+                        onClick={(e) => {
+                          e.stopPropagation();
+                          navigate('/migration', { state: { rerunId: migration.id } });
+                        }}
+                        className="text-xs px-2 py-1 rounded border border-border bg-card-bg text-text-secondary hover:bg-accent hover:text-white transition-colors"
+                      >
+                        {t('history.rerun')}
+                      </button>
+                      {/* 整體完成的遷移仍可能有個別表格失敗 */}
+                      {migration.status !== 'running' && (
+                        <button
+                          type="button"
+                          onClick={(e) => {
+                            e.stopPropagation();
+                            navigate('/migration', { state: { rerunId: migration.id, retryFailed: true } });
+                          }}
+                          className="text-xs px-2 py-1 rounded border border-border bg-card-bg text-text-secondary hover:bg-accent hover:text-white transition-colors"
+                        >
+                          {t('history.retryFailed')}
+                        </button>
+                      )}
+                    </div>
                   </div>
                 </div>
               ))}
@@ -117,6 +182,156 @@ export default function History() {
 
         {/* Log Detail */}
         <div className="bg-card-bg rounded-xl shadow-sm overflow-hidden flex flex-col">
+          {/* 試執行產生的 DDL 腳本 */}
+          {selectedMigration && script.length > 0 && (
+            <div className="border-b border-border-light">
+              <div className="flex items-center justify-between p-5 pb-3">
+                <h2 className="text-lg font-semibold text-text-secondary m-0">{t('history.ddlScript')}</h2>
+                <button
+                  type="button"
+                  onClick={handleExportScript}
+                  className="text-xs px-2 py-1 rounded border border-border bg-card-bg text-text-secondary hover:bg-accent hover:text-white transition-colors"
+                >
+                  {t('history.exportScript')}
+                </button>
+              </div>
+              {exportedTo && (
+                <p className="px-5 text-sm text-text-muted">{t('history.scriptExported', { dir: exportedTo })}</p>
+              )}
+              <div className="flex gap-2 px-5">
+                {script.map((file, i) => (
+                  <button
+                    key={file.name}
+                    type="button"
+                    onClick={() => setScriptFile(i)}
+                    className={`text-xs px-2 py-1 rounded border border-border transition-colors ${i === scriptFile ? 'bg-accent text-white' : 'bg-card-bg text-text-secondary hover:bg-accent-light'}`}
+                  >
+                    {file.name}
+                  </button>
+                ))}
+              </div>
+              <pre className="m-5 mt-3 p-3 max-h-80 overflow-auto text-xs bg-panel-bg rounded-md text-text-primary whitespace-pre">
+                {script[scriptFile]?.content}
+              </pre>
+            </div>
+          )}
+          {/* 無主鍵表格的讀取策略：唯一索引分頁或單次讀取 */}
+          {selectedMigration && heapTables.length > 0 && (
+            <div className="border-b border-border-light">
+              <h2 className="text-lg font-semibold text-text-secondary p-5 pb-3 m-0">
+                {t('history.readStrategies', { count: heapTables.length })}
+              </h2>
+              <div className="m-5 mt-0 max-h-80 overflow-auto text-xs">
+                {heapTables.map((table) => (
+                  <div
+                    key={`${table.schemaName}.${table.tableName}`}
+                    className="py-2 border-b border-border-light flex justify-between gap-3"
+                  >
+                    <span className="text-text-primary">
+                      {table.schemaName}.{table.tableName}
+                    </span>
+                    <span className={table.readStrategy === 'single-pass' ? 'text-warning' : 'text-text-muted'}>
+                      {t(`history.readStrategy_${table.readStrategy}`)}
+                    </span>
+                  </div>
+                ))}
+              </div>
+            </div>
+          )}
+          {/* 資料載入後建立的索引與耗時 */}
+          {selectedMigration && indexBuilds.length > 0 && (
+            <div className="border-b border-border-light">
+              <h2 className="text-lg font-semibold text-text-secondary p-5 pb-3 m-0">
+                {t('history.indexBuilds', { count: indexBuilds.length })}
+              </h2>
+              <div className="m-5 mt-0 max-h-80 overflow-auto text-xs">
+                {indexBuilds.map((build) => (
+                  <div
+                    key={`${build.schemaName}.${build.tableName}.${build.indexName}`}
+                    className="py-2 border-b border-border-light flex justify-between gap-3"
+                  >
+                    <span className="text-text-primary">
+                      {build.schemaName}.{build.tableName} {build.indexName}
+                    </span>
+                    <span className={build.error ? 'text-error' : 'text-text-muted'}>
+                      {build.error || t('history.indexBuildSeconds', { seconds: (build.durationMs / 1000).toFixed(1) })}
+                    </span>
+                  </div>
+                ))}
+              </div>
+            </div>
+          )}
+          {/* 外鍵建立與驗證結果，未通過時列出孤兒資料的外鍵值 */}
+          {selectedMigration && foreignKeyChecks.length > 0 && (
+            <div className="border-b border-border-light">
+              <h2 className="text-lg font-semibold text-text-secondary p-5 pb-3 m-0">
+                {t('history.foreignKeyChecks', {
+                  count: foreignKeyChecks.length,
+                  invalid: foreignKeyChecks.filter((check) => !check.valid).length
+                })}
+              </h2>
+              <div className="m-5 mt-0 max-h-80 overflow-auto text-xs">
+                {foreignKeyChecks.map((check) => (
+                  <div key={`${check.schemaName}.${check.tableName}.${check.constraintName}`} className="py-2 border-b border-border-light">
+                    <div className="flex justify-between gap-3">
+                      <span className="text-text-primary">
+                        {check.schemaName}.{check.tableName} {check.constraintName} → {check.referencedTable}
+                      </span>
+                      <span className={check.valid ? 'text-success' : 'text-error'}>
+                        {check.valid
+                          ? t('history.foreignKeyValid')
+                          : check.orphanCount > 0
+                            ? t('history.foreignKeyOrphans', { count: check.orphanCount })
+                            : t('history.foreignKeyFailed')}
+                      </span>
+                    </div>
+                    {check.sampleKeys && <code className="block text-text-muted break-all">{check.sampleKeys}</code>}
+                    {!check.valid && check.orphanCount === 0 && <div className="text-error">{check.error}</div>}
+                  </div>
+                ))}
+              </div>
+            </div>
+          )}
+          {/* 目標拒收而隔離的資料列 */}
+          {selectedMigration && quarantined.length > 0 && (
+            <div className="border-b border-border-light">
+              <h2 className="text-lg font-semibold text-text-secondary p-5 pb-3 m-0">
+                {t('history.quarantinedRows', { count: quarantined.length })}
+              </h2>
+              <div className="m-5 mt-0 max-h-80 overflow-auto text-xs">
+                {quarantined.map((row) => (
+                  <div key={row.id} className="py-2 border-b border-border-light">
+                    <div className="font-medium text-text-primary">
+                      {row.schemaName}.{row.tableName} {row.sourceKey}
+                    </div>
+                    <div className="text-error">{row.error}</div>
+                    <code className="block text-text-muted break-all">{row.rowData}</code>
+                  </div>
+                ))}
+              </div>
+            </div>
+          )}
+          {/* 舊字碼頁字串含無法解碼位元組或造字的資料列 */}
+          {selectedMigration && textIssues.length > 0 && (
+            <div className="border-b border-border-light">
+              <h2 className="text-lg font-semibold text-text-secondary p-5 pb-3 m-0">
+                {t('history.textIssues', { count: textIssues.length })}
+              </h2>
+              <div className="m-5 mt-0 max-h-80 overflow-auto text-xs">
+                {textIssues.map((issue) => (
+                  <div key={issue.id} className="py-2 border-b border-border-light">
+                    <div className="font-medium text-text-primary">
+                      {issue.schemaName}.{issue.tableName}.{issue.columnName} {issue.sourceKey}
+                    </div>
+                    <div className={issue.kind === 'undecodable' ? 'text-error' : 'text-text-secondary'}>
+                      {t(`history.textIssue_${issue.kind}`, { codePage: issue.codePage })}
+                    </div>
+                    <code className="block text-text-muted break-all">0x{issue.rawBytes.toUpperCase()} → {issue.text}</code>
+                  </div>
+                ))}
+              </div>
+            </div>
+          )}
           <h2 className="text-lg font-semibold text-text-secondary p-5 border-b border-border-light m-0">{t('history.detailLogs')}</h2>
           {!selectedMigration ? (
             <div className="p-10 text-center text-text-muted">
@@ -7,12 +7,32 @@ import { useRerunMigration } from '../hooks/useRerunMigration';
 import RerunBanner from '../components/migration/RerunBanner';
 import type { MigrationConfig } from '../types';
 
+// 更名清單每行一筆「來源 = 目標」，來源為 schema、schema.table 或 schema.table.column
+function parseRenames(text: string): Record<string, string> | undefined {
+  const renames: Record<string, string> = {};
+  for (const line of text.split('\n')) {
+    const i = line.indexOf('=');
+    if (i < 0) continue;
+    const source = line.slice(0, i).trim();
+    const target = line.slice(i + 1).trim();
+    if (source && target) renames[source] = target;
+  }
+  return Object.keys(renames).length > 0 ? renames : undefined;
+}
+
+function formatRenames(renames?: Record<string, string>): string {
+  return Object.entries(renames ?? {})
+    .map(([source, target]) => `${source} = ${target}`)
+    .join('\n');
+}
+
 export default function Migration() {
   const { t } = useTranslation();
   const location = useLocation();
   const [searchParams] = useSearchParams();
   const rerunId = (location.state as { rerunId?: string })?.rerunId ?? searchParams.get('rerun') ?? null;
-  const rerun = useRerunMigration(rerunId);
+  const retryFailed = (location.state as { retryFailed?: boolean })?.retryFailed ?? searchParams.get('retry') === 'failed';
+  const rerun = useRerunMigration(rerunId, retryFailed);
   const appliedRerunRef = useRef(false);
 
   const {
@@ -27,6 +47,8 @@ export default function Migration() {
     pauseMigration,
     resumeMigration,
     cancelMigration,
+    cutover,
+    setThrottle,
     toggleTableSelection,
     selectAllTables,
     deselectAllTables,
@@ -34,11 +56,19 @@ export default function Migration() {
     moveTableToTop,
     moveTableToBottom,
     clearError,
-    setRerunTables
+    setRerunTables,
+    typeMappingRuleSets,
+    loadTypeMappingRuleSets,
+    importTypeMappingRules,
+    exportTypeMappingRuleSet,
+    deleteTypeMappingRuleSet,
+    exportNameMappings
   } = useMigrationStore();
 
   const [dragIndex, setDragIndex] = useState<number | null>(null);
   const [dragOverIndex, setDragOverIndex] = useState<number | null>(null);
+  // 執行中調整的讀取限制，套用前以此暫存，未編輯時顯示目前的值
+  const [liveThrottle, setLiveThrottle] = useState<{ rows: number; mb: number } | null>(null);
 
   const {
     connections,
@@ -62,7 +92,36 @@ export default function Migration() {
     includeFunctions: false,
     includeTriggers: false,
     dropTargetIfExists: false,
-    batchSize: 10000
+    dryRun: false,
+    stagingSwap: false,
+    quarantineBadRows: false,
+    quarantineMaxRows: 1000,
+    deferIndexes: false,
+    foreignKeysNotValid: false,
+    bulkLoadProfile: false,
+    unloggedTables: false,
+    skipForeignKeyChecks: false,
+    analyzeTables: true,
+    vacuumTables: false,
+    clusterTables: false,
+    indexParallelism: 4,
+    maintenanceWorkMemMB: 0,
+    throttleRowsPerSec: 0,
+    throttleMBPerSec: 0,
+    timeWindows: '',
+    batchSize: 10000,
+    syncMode: 'full',
+    loadMode: 'append',
+    tableOrder: 'manual',
+    snapshotMode: 'off',
+    undecodableText: 'replace',
+    sourceTimeZone: '',
+    timestampTz: false,
+    typeMappingRuleSet: '',
+    namingPolicy: 'preserve',
+    identifierRenames: '',
+    replicationMethod: 'ct',
+    replicationPollSeconds: 5
   });
   const [sourceSelectionId, setSourceSelectionId] = useState('');
   const [targetSelectionId, setTargetSelectionId] = useState('');
@@ -71,6 +130,10 @@ export default function Migration() {
     loadConnections();
   }, [loadConnections]);
 
+  useEffect(() => {
+    loadTypeMappingRuleSets();
+  }, [loadTypeMappingRuleSets]);
+
   // 套用 Rerun 載入的設定到表單（僅套用一次）
   useEffect(() => {
     if (!rerun.isRerunMode || !rerun.config || rerun.isLoading || appliedRerunRef.current) return;
@@ -90,7 +153,36 @@ export default function Migration() {
       includeFunctions: c.includeFunctions,
       includeTriggers: c.includeTriggers,
       dropTargetIfExists: c.dropTargetIfExists,
-      batchSize: c.batchSize ?? 10000
+      dryRun: c.dryRun ?? false,
+      stagingSwap: c.stagingSwap ?? false,
+      quarantineBadRows: c.quarantineBadRows ?? false,
+      quarantineMaxRows: c.quarantineMaxRows || 1000,
+      deferIndexes: c.deferIndexes ?? false,
+      foreignKeysNotValid: c.foreignKeysNotValid ?? false,
+      bulkLoadProfile: c.bulkLoadProfile ?? false,
+      unloggedTables: c.unloggedTables ?? false,
+      skipForeignKeyChecks: c.skipForeignKeyChecks ?? false,
+      analyzeTables: c.analyzeTables ?? false,
+      vacuumTables: c.vacuumTables ?? false,
+      clusterTables: c.clusterTables ?? false,
+      indexParallelism: c.indexParallelism || 4,
+      maintenanceWorkMemMB: c.maintenanceWorkMemMB ?? 0,
+      throttleRowsPerSec: c.throttleRowsPerSec ?? 0,
+      throttleMBPerSec: c.throttleMBPerSec ?? 0,
+      timeWindows: c.timeWindows ?? '',
+      batchSize: c.batchSize ?? 10000,
+      syncMode: c.syncMode || 'full',
+      loadMode: c.loadMode || 'append',
+      tableOrder: c.tableOrder || 'manual',
+      snapshotMode: c.snapshotMode || 'off',
+      undecodableText: c.undecodableText || 'replace',
+      sourceTimeZone: c.sourceTimeZone ?? '',
+      timestampTz: c.timestampTz ?? false,
+      typeMappingRuleSet: c.typeMappingRuleSet ?? '',
+      namingPolicy: c.namingPolicy || 'preserve',
+      identifierRenames: formatRenames(c.identifierRenames),
+      replicationMethod: c.replicationMethod || 'ct',
+      replicationPollSeconds: c.replicationPollSeconds || 5
     });
     if (rerun.originalName) setMigrationName(rerun.originalName);
     if (rerun.tables.length > 0) setRerunTables(rerun.tables);
@@ -166,18 +258,13 @@ export default function Migration() {
     setDragOverIndex(null);
   };
 
-  const handleStartMigration = async () => {
-    if (selectedTables.length === 0) {
-      alert(t('migration.alertSelectTable'));
-      return;
-    }
-
+  const buildConfig = (): MigrationConfig => {
     // 按照 tables 的順序（拖曳後順序）排列 selectedTables
     const orderedTables = tables
       .map(t => `${t.schema}.${t.name}`)
       .filter(name => selectedTables.includes(name));
 
-    const config: MigrationConfig = {
+    return {
       sourceConnectionString: sourceConnString,
       targetConnectionString: targetConnString,
       sourceDatabase,
@@ -191,16 +278,64 @@ export default function Migration() {
       includeTriggers: options.includeTriggers,
       batchSize: options.batchSize,
       parallelTables: 1,
-      dropTargetIfExists: options.dropTargetIfExists
+      dropTargetIfExists: options.dropTargetIfExists,
+      dryRun: options.dryRun,
+      stagingSwap: options.stagingSwap,
+      quarantineBadRows: options.quarantineBadRows,
+      quarantineMaxRows: options.quarantineMaxRows,
+      deferIndexes: options.deferIndexes,
+      foreignKeysNotValid: options.foreignKeysNotValid,
+      bulkLoadProfile: options.bulkLoadProfile,
+      unloggedTables: options.unloggedTables,
+      skipForeignKeyChecks: options.skipForeignKeyChecks,
+      analyzeTables: options.analyzeTables,
+      vacuumTables: options.vacuumTables,
+      clusterTables: options.clusterTables,
+      indexParallelism: options.indexParallelism,
+      maintenanceWorkMemMB: options.maintenanceWorkMemMB,
+      throttleRowsPerSec: options.throttleRowsPerSec,
+      throttleMBPerSec: options.throttleMBPerSec,
+      timeWindows: options.timeWindows.trim(),
+      parentMigrationId: rerunId ?? undefined,
+      syncMode: options.syncMode,
+      loadMode: options.loadMode,
+      tableOrder: options.tableOrder,
+      // 一致性快照只適用於完整複製
+      snapshotMode: options.syncMode === 'full' ? options.snapshotMode : 'off',
+      undecodableText: options.undecodableText,
+      sourceTimeZone: options.sourceTimeZone.trim(),
+      timestampTz: options.timestampTz,
+      typeMappingRuleSet: options.typeMappingRuleSet,
+      namingPolicy: options.namingPolicy,
+      identifierRenames: parseRenames(options.identifierRenames),
+      replicationMethod: options.replicationMethod,
+      replicationPollSeconds: options.replicationPollSeconds
     };
+  };
+
+  const handleStartMigration = async () => {
+    if (selectedTables.length === 0) {
+      alert(t('migration.alertSelectTable'));
+      return;
+    }
 
     try {
-      await startMigration(config, migrationName);
+      await startMigration(buildConfig(), migrationName);
     } catch {
       // Error handled in store
     }
   };
 
+  const handleImportRules = async () => {
+    const imported = await importTypeMappingRules();
+    if (imported) setOptions((prev) => ({ ...prev, typeMappingRuleSet: imported.id }));
+  };
+
+  const handleDeleteRules = async () => {
+    await deleteTypeMappingRuleSet(options.typeMappingRuleSet);
+    setOptions((prev) => ({ ...prev, typeMappingRuleSet: '' }));
+  };
+
   const isRunning = status?.Status === 'running';
   const isPaused = status?.Status === 'paused';
   const isCompleted = status?.Status === 'completed';
@@ -231,7 +366,7 @@ export default function Migration() {
             <div className="bg-error-bg text-error-text px-4 py-3 rounded-lg mb-5">{rerun.error}</div>
           )}
           {!rerun.isLoading && !rerun.error && (
-            <RerunBanner name={rerun.originalName} onClear={rerun.clearRerun} />
+            <RerunBanner name={rerun.originalName} retryFailed={rerun.retryFailed} onClear={rerun.clearRerun} />
           )}
         </>
       )}
@@ -355,6 +490,127 @@ export default function Migration() {
                 />
                 {t('migration.dropTargetIfExists')}
               </label>
+              <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary">
+                <input
+                  type="checkbox"
+                  checked={options.dryRun}
+                  onChange={(e) =>
+                    setOptions({ ...options, dryRun: e.target.checked })
+                  }
+                  className="w-4 h-4"
+                />
+                {t('migration.dryRun')}
+              </label>
+              <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary" title={t('migration.stagingSwapHint')}>
+                <input
+                  type="checkbox"
+                  checked={options.stagingSwap}
+                  onChange={(e) =>
+                    setOptions({ ...options, stagingSwap: e.target.checked })
+                  }
+                  className="w-4 h-4"
+                />
+                {t('migration.stagingSwap')}
+              </label>
+              <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary" title={t('migration.quarantineBadRowsHint', { max: options.quarantineMaxRows })}>
+                <input
+                  type="checkbox"
+                  checked={options.quarantineBadRows}
+                  onChange={(e) =>
+                    setOptions({ ...options, quarantineBadRows: e.target.checked })
+                  }
+                  className="w-4 h-4"
+                />
+                {t('migration.quarantineBadRows')}
+              </label>
+              <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary" title={t('migration.deferIndexesHint')}>
+                <input
+                  type="checkbox"
+                  checked={options.deferIndexes}
+                  onChange={(e) =>
+                    setOptions({ ...options, deferIndexes: e.target.checked })
+                  }
+                  className="w-4 h-4"
+                />
+                {t('migration.deferIndexes')}
+              </label>
+              <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary" title={t('migration.foreignKeysNotValidHint')}>
+                <input
+                  type="checkbox"
+                  checked={options.foreignKeysNotValid}
+                  onChange={(e) =>
+                    setOptions({ ...options, foreignKeysNotValid: e.target.checked })
+                  }
+                  className="w-4 h-4"
+                />
+                {t('migration.foreignKeysNotValid')}
+              </label>
+              <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary" title={t('migration.bulkLoadProfileHint')}>
+                <input
+                  type="checkbox"
+                  checked={options.bulkLoadProfile}
+                  onChange={(e) =>
+                    setOptions({ ...options, bulkLoadProfile: e.target.checked })
+                  }
+                  className="w-4 h-4"
+                />
+                {t('migration.bulkLoadProfile')}
+              </label>
+              <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary" title={t('migration.unloggedTablesHint')}>
+                <input
+                  type="checkbox"
+                  checked={options.unloggedTables}
+                  onChange={(e) =>
+                    setOptions({ ...options, unloggedTables: e.target.checked })
+                  }
+                  className="w-4 h-4"
+                />
+                {t('migration.unloggedTables')}
+              </label>
+              <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary" title={t('migration.skipForeignKeyChecksHint')}>
+                <input
+                  type="checkbox"
+                  checked={options.skipForeignKeyChecks}
+                  onChange={(e) =>
+                    setOptions({ ...options, skipForeignKeyChecks: e.target.checked })
+                  }
+                  className="w-4 h-4"
+                />
+                {t('migration.skipForeignKeyChecks')}
+              </label>
+              <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary" title={t('migration.analyzeTablesHint')}>
+                <input
+                  type="checkbox"
+                  checked={options.analyzeTables}
+                  onChange={(e) =>
+                    setOptions({ ...options, analyzeTables: e.target.checked })
+                  }
+                  className="w-4 h-4"
+                />
+                {t('migration.analyzeTables')}
+              </label>
+              <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary" title={t('migration.vacuumTablesHint')}>
+                <input
+                  type="checkbox"
+                  checked={options.vacuumTables}
+                  onChange={(e) =>
+                    setOptions({ ...options, vacuumTables: e.target.checked })
+                  }
+                  className="w-4 h-4"
+                />
+                {t('migration.vacuumTables')}
+              </label>
+              <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary" title={t('migration.clusterTablesHint')}>
+                <input
+                  type="checkbox"
+                  checked={options.clusterTables}
+                  onChange={(e) =>
+                    setOptions({ ...options, clusterTables: e.target.checked })
+                  }
+                  className="w-4 h-4"
+                />
+                {t('migration.clusterTables')}
+              </label>
             </div>
 
             <div className="mb-5">
@@ -371,6 +627,305 @@ export default function Migration() {
               />
             </div>
 
+            {options.quarantineBadRows && (
+              <div className="mb-5">
+                <label className="block mb-2 font-medium text-text-secondary">{t('migration.quarantineMaxRows')}</label>
+                <input
+                  type="number"
+                  value={options.quarantineMaxRows}
+                  onChange={(e) =>
+                    setOptions({ ...options, quarantineMaxRows: parseInt(e.target.value) || 1000 })
+                  }
+                  min={1}
+                  className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                />
+              </div>
+            )}
+
+            {(options.deferIndexes || options.bulkLoadProfile) && (
+              <div className="mb-5 flex gap-5">
+                {options.deferIndexes && (
+                  <div>
+                    <label className="block mb-2 font-medium text-text-secondary">{t('migration.indexParallelism')}</label>
+                    <input
+                      type="number"
+                      value={options.indexParallelism}
+                      onChange={(e) =>
+                        setOptions({ ...options, indexParallelism: parseInt(e.target.value) || 4 })
+                      }
+                      min={1}
+                      max={32}
+                      className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                    />
+                  </div>
+                )}
+                <div>
+                  <label className="block mb-2 font-medium text-text-secondary">{t('migration.maintenanceWorkMemMB')}</label>
+                  <input
+                    type="number"
+                    value={options.maintenanceWorkMemMB}
+                    onChange={(e) =>
+                      setOptions({ ...options, maintenanceWorkMemMB: parseInt(e.target.value) || 0 })
+                    }
+                    min={0}
+                    max={65536}
+                    className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                  />
+                </div>
+              </div>
+            )}
+
+            <div className="mb-5">
+              <div className="flex gap-5">
+                <div>
+                  <label className="block mb-2 font-medium text-text-secondary">{t('migration.throttleRowsPerSec')}</label>
+                  <input
+                    type="number"
+                    value={options.throttleRowsPerSec}
+                    onChange={(e) =>
+                      setOptions({ ...options, throttleRowsPerSec: parseInt(e.target.value) || 0 })
+                    }
+                    min={0}
+                    className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                  />
+                </div>
+                <div>
+                  <label className="block mb-2 font-medium text-text-secondary">{t('migration.throttleMBPerSec')}</label>
+                  <input
+                    type="number"
+                    value={options.throttleMBPerSec}
+                    onChange={(e) =>
+                      setOptions({ ...options, throttleMBPerSec: parseFloat(e.target.value) || 0 })
+                    }
+                    min={0}
+                    step={0.5}
+                    className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                  />
+                </div>
+                <div>
+                  <label className="block mb-2 font-medium text-text-secondary">{t('migration.timeWindows')}</label>
+                  <input
+                    type="text"
+                    value={options.timeWindows}
+                    onChange={(e) => setOptions({ ...options, timeWindows: e.target.value })}
+                    placeholder="22:00-06:00"
+                    className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                  />
+                </div>
+              </div>
+              <p className="mt-2 text-sm text-text-muted">{t('migration.throttleHint')}</p>
+            </div>
+
+            <div className="mb-5">
+              <label className="block mb-2 font-medium text-text-secondary">{t('migration.syncMode')}</label>
+              <select
+                value={options.syncMode}
+                onChange={(e) => setOptions({ ...options, syncMode: e.target.value })}
+                className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+              >
+                <option value="full">{t('migration.syncModeFull')}</option>
+                <option value="delta">{t('migration.syncModeDelta')}</option>
+                <option value="replicate">{t('migration.syncModeReplicate')}</option>
+              </select>
+              {options.syncMode === 'delta' && (
+                <p className="mt-2 text-sm text-text-muted">{t('migration.syncModeDeltaHint')}</p>
+              )}
+              {options.syncMode === 'replicate' && (
+                <p className="mt-2 text-sm text-text-muted">{t('migration.syncModeReplicateHint')}</p>
+              )}
+            </div>
+
+            {options.syncMode === 'full' && (
+              <div className="mb-5">
+                <label className="block mb-2 font-medium text-text-secondary">{t('migration.loadMode')}</label>
+                <select
+                  value={options.loadMode}
+                  onChange={(e) => setOptions({ ...options, loadMode: e.target.value })}
+                  className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                >
+                  <option value="append">{t('migration.loadModeAppend')}</option>
+                  <option value="truncate">{t('migration.loadModeTruncate')}</option>
+                  <option value="upsert">{t('migration.loadModeUpsert')}</option>
+                </select>
+                <p className="mt-2 text-sm text-text-muted">{t('migration.loadModeHint')}</p>
+              </div>
+            )}
+
+            {options.syncMode === 'full' && (
+              <div className="mb-5">
+                <label className="block mb-2 font-medium text-text-secondary">{t('migration.snapshotMode')}</label>
+                <select
+                  value={options.snapshotMode}
+                  onChange={(e) => setOptions({ ...options, snapshotMode: e.target.value })}
+                  className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                >
+                  <option value="off">{t('migration.snapshotModeOff')}</option>
+                  <option value="isolation">{t('migration.snapshotModeIsolation')}</option>
+                  <option value="database">{t('migration.snapshotModeDatabase')}</option>
+                </select>
+                {options.snapshotMode === 'isolation' && (
+                  <p className="mt-2 text-sm text-text-muted">{t('migration.snapshotModeIsolationHint')}</p>
+                )}
+                {options.snapshotMode === 'database' && (
+                  <p className="mt-2 text-sm text-text-muted">{t('migration.snapshotModeDatabaseHint')}</p>
+                )}
+              </div>
+            )}
+
+            <div className="mb-5">
+              <label className="block mb-2 font-medium text-text-secondary">{t('migration.undecodableText')}</label>
+              <select
+                value={options.undecodableText}
+                onChange={(e) => setOptions({ ...options, undecodableText: e.target.value })}
+                className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+              >
+                <option value="replace">{t('migration.undecodableTextReplace')}</option>
+                <option value="reject">{t('migration.undecodableTextReject')}</option>
+              </select>
+              <p className="mt-2 text-sm text-text-muted">
+                {t(options.undecodableText === 'reject' ? 'migration.undecodableTextRejectHint' : 'migration.undecodableTextReplaceHint')}
+              </p>
+            </div>
+
+            <div className="mb-5">
+              <label className="block mb-2 font-medium text-text-secondary">{t('migration.sourceTimeZone')}</label>
+              <div className="flex items-center gap-4">
+                <input
+                  type="text"
+                  value={options.sourceTimeZone}
+                  onChange={(e) => setOptions({ ...options, sourceTimeZone: e.target.value })}
+                  placeholder="Asia/Taipei"
+                  className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                />
+                <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary">
+                  <input
+                    type="checkbox"
+                    checked={options.timestampTz}
+                    onChange={(e) => setOptions({ ...options, timestampTz: e.target.checked })}
+                    className="w-4 h-4"
+                  />
+                  {t('migration.timestampTz')}
+                </label>
+              </div>
+              <p className="mt-2 text-sm text-text-muted">
+                {t(options.timestampTz ? 'migration.timestampTzHint' : 'migration.sourceTimeZoneHint')}
+              </p>
+            </div>
+
+            <div className="mb-5">
+              <label className="block mb-2 font-medium text-text-secondary">{t('migration.typeMapping')}</label>
+              <div className="flex items-center gap-2">
+                <select
+                  value={options.typeMappingRuleSet}
+                  onChange={(e) => setOptions({ ...options, typeMappingRuleSet: e.target.value })}
+                  className="w-64 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                >
+                  <option value="">{t('migration.typeMappingBuiltIn')}</option>
+                  {typeMappingRuleSets.map((ruleSet) => (
+                    <option key={ruleSet.id} value={ruleSet.id}>
+                      {t('migration.typeMappingRuleSet', { name: ruleSet.name, count: ruleSet.rules.length })}
+                    </option>
+                  ))}
+                </select>
+                <button
+                  className="px-3 py-1.5 bg-accent hover:bg-accent-hover text-white rounded text-xs font-medium transition-colors"
+                  onClick={handleImportRules}
+                >
+                  {t('migration.typeMappingImport')}
+                </button>
+                {options.typeMappingRuleSet && (
+                  <>
+                    <button
+                      className="px-3 py-1.5 bg-accent hover:bg-accent-hover text-white rounded text-xs font-medium transition-colors"
+                      onClick={() => exportTypeMappingRuleSet(options.typeMappingRuleSet)}
+                    >
+                      {t('migration.typeMappingExport')}
+                    </button>
+                    <button
+                      className="px-3 py-1.5 bg-error hover:bg-error-hover text-white rounded text-xs font-medium transition-colors"
+                      onClick={handleDeleteRules}
+                    >
+                      {t('common.delete')}
+                    </button>
+                  </>
+                )}
+              </div>
+              <p className="mt-2 text-sm text-text-muted">{t('migration.typeMappingHint')}</p>
+            </div>
+
+            <div className="mb-5">
+              <label className="block mb-2 font-medium text-text-secondary">{t('migration.namingPolicy')}</label>
+              <div className="flex items-center gap-2">
+                <select
+                  value={options.namingPolicy}
+                  onChange={(e) => setOptions({ ...options, namingPolicy: e.target.value })}
+                  className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                >
+                  <option value="preserve">{t('migration.namingPolicyPreserve')}</option>
+                  <option value="lowercase">{t('migration.namingPolicyLowercase')}</option>
+                  <option value="snake_case">{t('migration.namingPolicySnakeCase')}</option>
+                </select>
+                <button
+                  className="px-3 py-1.5 bg-accent hover:bg-accent-hover text-white rounded text-xs font-medium transition-colors"
+                  onClick={() => exportNameMappings(buildConfig())}
+                >
+                  {t('migration.nameMappingsExport')}
+                </button>
+              </div>
+              <textarea
+                value={options.identifierRenames}
+                onChange={(e) => setOptions({ ...options, identifierRenames: e.target.value })}
+                placeholder={'dbo.OrderDetails = order_lines\ndbo.OrderDetails.CustomerID = customer_ref'}
+                rows={3}
+                className="mt-2 w-full px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm font-mono focus:outline-none focus:ring-2 focus:ring-accent"
+              />
+              <p className="mt-2 text-sm text-text-muted">{t('migration.namingPolicyHint')}</p>
+            </div>
+
+            <div className="mb-5">
+              <label className="block mb-2 font-medium text-text-secondary">{t('migration.tableOrder')}</label>
+              <select
+                value={options.tableOrder}
+                onChange={(e) => setOptions({ ...options, tableOrder: e.target.value })}
+                className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+              >
+                <option value="manual">{t('migration.tableOrderManual')}</option>
+                <option value="fk">{t('migration.tableOrderForeignKey')}</option>
+              </select>
+              {options.tableOrder === 'fk' && (
+                <p className="mt-2 text-sm text-text-muted">{t('migration.tableOrderForeignKeyHint')}</p>
+              )}
+            </div>
+
+            {options.syncMode === 'replicate' && (
+              <div className="mb-5 flex gap-5">
+                <div>
+                  <label className="block mb-2 font-medium text-text-secondary">{t('migration.replicationMethod')}</label>
+                  <select
+                    value={options.replicationMethod}
+                    onChange={(e) => setOptions({ ...options, replicationMethod: e.target.value })}
+                    className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                  >
+                    <option value="ct">{t('migration.replicationMethodCT')}</option>
+                    <option value="cdc">{t('migration.replicationMethodCDC')}</option>
+                  </select>
+                </div>
+                <div>
+                  <label className="block mb-2 font-medium text-text-secondary">{t('migration.replicationPollSeconds')}</label>
+                  <input
+                    type="number"
+                    value={options.replicationPollSeconds}
+                    onChange={(e) =>
+                      setOptions({ ...options, replicationPollSeconds: parseInt(e.target.value) || 5 })
+                    }
+                    min={1}
+                    max={3600}
+                    className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                  />
+                </div>
+              </div>
+            )}
+
             <button
               className="px-5 py-2.5 bg-accent hover:bg-accent-hover text-white rounded-md text-sm font-medium transition-colors disabled:opacity-60 disabled:cursor-not-allowed"
               onClick={handleLoadTables}
@@ -486,7 +1041,7 @@ export default function Migration() {
               {status.Status === 'cancelled' && t('migration.statusCancelled')}
             </span>
             <span className="text-text-muted">
-              {status.CurrentTable && t('migration.processing', { table: status.CurrentTable })}
+              {status.CurrentTables?.length ? t('migration.processing', { table: status.CurrentTables.join(', ') }) : null}
             </span>
           </div>
 
@@ -511,8 +1066,61 @@ export default function Migration() {
                 {status.MigratedRows.toLocaleString()} / {status.TotalRows.toLocaleString()}
               </span>
             </div>
+            {status.Replication && (
+              <>
+                <div>
+                  <label className="block text-xs text-text-muted mb-1">{t('migration.replicationLag')}</label>
+                  <span className="text-lg font-semibold text-text-primary">
+                    {status.Replication.LagSeconds.toFixed(1)}s
+                  </span>
+                </div>
+                <div>
+                  <label className="block text-xs text-text-muted mb-1">{t('migration.replicationApplied')}</label>
+                  <span className="text-lg font-semibold text-text-primary">
+                    {status.Replication.AppliedChanges.toLocaleString()} ({t('migration.replicationCycles', { count: status.Replication.Cycles })})
+                  </span>
+                </div>
+              </>
+            )}
           </div>
 
+          {(isRunning || isPaused) && (
+            <div className="flex items-end gap-3 mb-5">
+              <div>
+                <label className="block text-xs text-text-muted mb-1">{t('migration.throttleRowsPerSec')}</label>
+                <input
+                  type="number"
+                  value={liveThrottle?.rows ?? status.ThrottleRowsPerSec}
+                  onChange={(e) =>
+                    setLiveThrottle({ rows: parseInt(e.target.value) || 0, mb: liveThrottle?.mb ?? status.ThrottleMBPerSec })
+                  }
+                  min={0}
+                  className="w-32 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                />
+              </div>
+              <div>
+                <label className="block text-xs text-text-muted mb-1">{t('migration.throttleMBPerSec')}</label>
+                <input
+                  type="number"
+                  value={liveThrottle?.mb ?? status.ThrottleMBPerSec}
+                  onChange={(e) =>
+                    setLiveThrottle({ rows: liveThrottle?.rows ?? status.ThrottleRowsPerSec, mb: parseFloat(e.target.value) || 0 })
+                  }
+                  min={0}
+                  step={0.5}
+                  className="w-32 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                />
+              </div>
+              <button
+                className="px-4 py-2 bg-accent hover:bg-accent-hover text-white rounded-md text-sm font-medium transition-colors disabled:opacity-60 disabled:cursor-not-allowed"
+                onClick={() => liveThrottle && setThrottle(liveThrottle.rows, liveThrottle.mb).then(() => setLiveThrottle(null))}
+                disabled={!liveThrottle}
+              >
+                {t('migration.applyThrottle')}
+              </button>
+            </div>
+          )}
+
           <div className="flex gap-3 mb-8">
             {isRunning && (
               <button className="px-5 py-2.5 bg-warning hover:bg-warning-hover text-white rounded-md text-sm font-medium transition-colors" onClick={pauseMigration}>
@@ -520,10 +1128,19 @@ export default function Migration() {
               </button>
             )}
             {isPaused && (
-              <button className="px-5 py-2.5 bg-accent hover:bg-accent-hover text-white rounded-md text-sm font-medium transition-colors" onClick={resumeMigration}>
+              <button className="px-5 py-2.5 bg-accent hover:bg-accent-hover text-white rounded-md text-sm font-medium transition-colors" onClick={() => resumeMigration()}>
                 {t('migration.resume')}
               </button>
             )}
+            {isRunning && status.Replication && (
+              <button
+                className="px-5 py-2.5 bg-accent hover:bg-accent-hover text-white rounded-md text-sm font-medium transition-colors disabled:opacity-60 disabled:cursor-not-allowed"
+                onClick={cutover}
+                disabled={status.Replication.CutoverRequested}
+              >
+                {status.Replication.CutoverRequested ? t('migration.cutoverPending') : t('migration.cutover')}
+              </button>
+            )}
             {(isRunning || isPaused) && (
               <button className="px-5 py-2.5 bg-error hover:bg-error-hover text-white rounded-md text-sm font-medium transition-colors" onClick={cancelMigration}>
                 {t('migration.cancel')}
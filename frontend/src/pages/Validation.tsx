@@ -1,6 +1,7 @@
-import { useState } from 'react';
+import { useState, useEffect } from 'react';
 import { useTranslation } from 'react-i18next';
 import { StartValidation } from '../../wailsjs/go/main/App';
+import { useMigrationStore } from '../stores/migrationStore';
 import type { ValidationConfig, ValidationResult } from '../types';
 
 export default function Validation() {
@@ -16,11 +17,20 @@ export default function Validation() {
     rowCountValidation: true,
     checksumValidation: true,
     sampleComparison: true,
-    sampleSize: 100
+    sampleSize: 100,
+    sourceTimeZone: '',
+    timestampTz: false,
+    typeMappingRuleSet: '',
+    namingPolicy: 'preserve'
   });
   const [results, setResults] = useState<ValidationResult[]>([]);
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
+  const { typeMappingRuleSets, loadTypeMappingRuleSets } = useMigrationStore();
+
+  useEffect(() => {
+    loadTypeMappingRuleSets();
+  }, [loadTypeMappingRuleSets]);
 
   const handleStartValidation = async () => {
     setLoading(true);
@@ -88,6 +98,20 @@ export default function Validation() {
             />
           </div>
 
+          <div className="mb-5">
+            <label className="block mb-2 font-medium text-text-secondary">{t('validation.namingPolicy')}</label>
+            <select
+              value={config.namingPolicy}
+              onChange={(e) => setConfig({ ...config, namingPolicy: e.target.value })}
+              className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+            >
+              <option value="preserve">{t('migration.namingPolicyPreserve')}</option>
+              <option value="lowercase">{t('migration.namingPolicyLowercase')}</option>
+              <option value="snake_case">{t('migration.namingPolicySnakeCase')}</option>
+            </select>
+            <p className="mt-2 text-sm text-text-muted">{t('validation.namingPolicyHint')}</p>
+          </div>
+
           <div className="grid grid-cols-2 md:grid-cols-3 gap-4 mb-5">
             <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary">
               <input
@@ -124,6 +148,48 @@ export default function Validation() {
             </label>
           </div>
 
+          {config.sampleComparison && (
+            <div className="mb-5">
+              <label className="block mb-2 font-medium text-text-secondary">{t('validation.sourceTimeZone')}</label>
+              <div className="flex items-center gap-4">
+                <input
+                  type="text"
+                  value={config.sourceTimeZone}
+                  onChange={(e) => setConfig({ ...config, sourceTimeZone: e.target.value })}
+                  placeholder="Asia/Taipei"
+                  className="w-48 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+                />
+                <label className="flex items-center gap-2 cursor-pointer text-sm text-text-secondary">
+                  <input
+                    type="checkbox"
+                    checked={config.timestampTz}
+                    onChange={(e) => setConfig({ ...config, timestampTz: e.target.checked })}
+                    className="w-4 h-4"
+                  />
+                  {t('validation.timestampTz')}
+                </label>
+              </div>
+              <p className="mt-2 text-sm text-text-muted">{t('validation.timeZoneHint')}</p>
+            </div>
+          )}
+
+          {config.sampleComparison && (
+            <div className="mb-5">
+              <label className="block mb-2 font-medium text-text-secondary">{t('validation.typeMapping')}</label>
+              <select
+                value={config.typeMappingRuleSet}
+                onChange={(e) => setConfig({ ...config, typeMappingRuleSet: e.target.value })}
+                className="w-64 px-3 py-2 border border-border rounded-md bg-card-bg text-text-primary text-sm focus:outline-none focus:ring-2 focus:ring-accent"
+              >
+                <option value="">{t('migration.typeMappingBuiltIn')}</option>
+                {typeMappingRuleSets.map((ruleSet) => (
+                  <option key={ruleSet.id} value={ruleSet.id}>{ruleSet.name}</option>
+                ))}
+              </select>
+              <p className="mt-2 text-sm text-text-muted">{t('validation.typeMappingHint')}</p>
+            </div>
+          )}
+
           {config.sampleComparison && (
             <div className="mb-5">
               <label className="block mb-2 font-medium text-text-secondary">{t('validation.sampleSize')}</label>
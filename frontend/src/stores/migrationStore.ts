@@ -4,7 +4,14 @@ import type {
   MigrationState,
   MigrationRecord,
   LogEntry,
+  ForeignKeyCheck,
+  IndexBuild,
+  QuarantinedRow,
+  TableMigrationState,
+  ScriptFile,
   TableInfo,
+  TextIssue,
+  TypeMappingRuleSet,
   ProgressEvent
 } from '../types';
 import {
@@ -12,10 +19,24 @@ import {
   PauseMigration,
   ResumeMigration,
   CancelMigration,
+  Cutover,
+  SetThrottle,
   GetMigrationStatus,
   GetMigrationHistory,
   GetMigrationLogs,
-  GetTables
+  GetMigrationTables,
+  GetDDLScript,
+  ExportDDLScript,
+  GetForeignKeyChecks,
+  GetIndexBuilds,
+  GetQuarantinedRows,
+  GetTables,
+  GetTextIssues,
+  GetTypeMappingRuleSets,
+  ImportTypeMappingRules,
+  ExportTypeMappingRuleSet,
+  DeleteTypeMappingRuleSet,
+  ExportNameMappings
 } from '../../wailsjs/go/main/App';
 import { EventsOn, EventsOff } from '../../wailsjs/runtime/runtime';
 
@@ -24,6 +45,13 @@ interface MigrationStoreState {
   status: MigrationState | null;
   history: MigrationRecord[];
   logs: LogEntry[];
+  script: ScriptFile[];
+  quarantined: QuarantinedRow[];
+  textIssues: TextIssue[];
+  indexBuilds: IndexBuild[];
+  migrationTables: TableMigrationState[];
+  foreignKeyChecks: ForeignKeyCheck[];
+  typeMappingRuleSets: TypeMappingRuleSet[];
   tables: TableInfo[];
   selectedTables: string[];
   progress: Record<string, ProgressEvent>;
@@ -34,11 +62,29 @@ interface MigrationStoreState {
   
   startMigration: (config: MigrationConfig, name: string) => Promise<string>;
   pauseMigration: () => Promise<void>;
-  resumeMigration: () => Promise<void>;
+  resumeMigration: (migrationId?: string) => Promise<void>;
   cancelMigration: () => Promise<void>;
+  cutover: () => Promise<void>;
+  /** 調整執行中 migration 的來源讀取限制（0 表示不限） */
+  setThrottle: (rowsPerSec: number, mbPerSec: number) => Promise<void>;
   refreshStatus: () => Promise<void>;
   loadHistory: (limit?: number) => Promise<void>;
   loadLogs: (migrationId: string, limit?: number) => Promise<void>;
+  loadScript: (migrationId: string) => Promise<void>;
+  /** 匯出試執行的 DDL 腳本，回傳匯出目錄（取消時為空字串） */
+  exportScript: (migrationId: string) => Promise<string>;
+  loadQuarantined: (migrationId: string) => Promise<void>;
+  loadTextIssues: (migrationId: string) => Promise<void>;
+  loadIndexBuilds: (migrationId: string) => Promise<void>;
+  loadMigrationTables: (migrationId: string) => Promise<void>;
+  loadForeignKeyChecks: (migrationId: string) => Promise<void>;
+  loadTypeMappingRuleSets: () => Promise<void>;
+  /** 匯入 JSON/YAML 型別對應規則集，回傳匯入的規則集（取消時為 null） */
+  importTypeMappingRules: () => Promise<TypeMappingRuleSet | null>;
+  /** 匯出型別對應規則集，回傳匯出的檔案路徑（取消時為空字串） */
+  exportTypeMappingRuleSet: (id: string) => Promise<string>;
+  deleteTypeMappingRuleSet: (id: string) => Promise<void>;
+  exportNameMappings: (config: MigrationConfig) => Promise<string>;
   toggleTableSelection: (tableName: string) => void;
   selectAllTables: () => void;
   deselectAllTables: () => void;
@@ -56,6 +102,13 @@ export const useMigrationStore = create<MigrationStoreState>((set, get) => ({
   status: null,
   history: [],
   logs: [],
+  script: [],
+  quarantined: [],
+  textIssues: [],
+  indexBuilds: [],
+  migrationTables: [],
+  foreignKeyChecks: [],
+  typeMappingRuleSets: [],
   tables: [],
   selectedTables: [],
   progress: {},
@@ -105,9 +158,16 @@ export const useMigrationStore = create<MigrationStoreState>((set, get) => ({
     }
   },
 
-  resumeMigration: async () => {
+  resumeMigration: async (migrationId?: string) => {
     try {
-      await ResumeMigration();
+      // 未指定時續傳目前的遷移；指定歷史紀錄時從檢查點重新啟動
+      const id = migrationId ?? get().activeMigrationId ?? '';
+      if (id !== get().activeMigrationId) {
+        cleanupEventListeners();
+        setupEventListeners(set, get);
+      }
+      await ResumeMigration(id);
+      set({ activeMigrationId: id });
     } catch (e: unknown) {
       const message = e instanceof Error ? e.message : 'Failed to resume';
       set({ error: message });
@@ -126,6 +186,28 @@ export const useMigrationStore = create<MigrationStoreState>((set, get) => ({
     }
   },
 
+  cutover: async () => {
+    try {
+      await Cutover();
+      get().refreshStatus();
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to cut over';
+      set({ error: message });
+      throw e;
+    }
+  },
+
+  setThrottle: async (rowsPerSec: number, mbPerSec: number) => {
+    try {
+      await SetThrottle(rowsPerSec, mbPerSec);
+      get().refreshStatus();
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to set throttle';
+      set({ error: message });
+      throw e;
+    }
+  },
+
   refreshStatus: async () => {
     try {
       const result = await GetMigrationStatus();
@@ -157,6 +239,129 @@ export const useMigrationStore = create<MigrationStoreState>((set, get) => ({
     }
   },
 
+  loadScript: async (migrationId: string) => {
+    try {
+      const result = await GetDDLScript(migrationId);
+      set({ script: (result || []) as ScriptFile[] });
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to load DDL script';
+      set({ error: message, script: [] });
+    }
+  },
+
+  exportScript: async (migrationId: string) => {
+    try {
+      return await ExportDDLScript(migrationId);
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to export DDL script';
+      set({ error: message });
+      return '';
+    }
+  },
+
+  loadTypeMappingRuleSets: async () => {
+    try {
+      const result = await GetTypeMappingRuleSets();
+      set({ typeMappingRuleSets: (result || []) as unknown as TypeMappingRuleSet[] });
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to load type mapping rule sets';
+      set({ error: message, typeMappingRuleSets: [] });
+    }
+  },
+
+  importTypeMappingRules: async () => {
+    try {
+      const result = await ImportTypeMappingRules();
+      if (!result) return null;
+      await get().loadTypeMappingRuleSets();
+      return result as unknown as TypeMappingRuleSet;
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to import type mapping rules';
+      set({ error: message });
+      return null;
+    }
+  },
+
+  exportTypeMappingRuleSet: async (id: string) => {
+    try {
+      return await ExportTypeMappingRuleSet(id);
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to export type mapping rules';
+      set({ error: message });
+      return '';
+    }
+  },
+
+  deleteTypeMappingRuleSet: async (id: string) => {
+    try {
+      await DeleteTypeMappingRuleSet(id);
+      await get().loadTypeMappingRuleSets();
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to delete type mapping rule set';
+      set({ error: message });
+    }
+  },
+
+  exportNameMappings: async (config: MigrationConfig) => {
+    try {
+      return await ExportNameMappings(config as never);
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to export name mappings';
+      set({ error: message });
+      return '';
+    }
+  },
+
+  loadQuarantined: async (migrationId: string) => {
+    try {
+      const result = await GetQuarantinedRows(migrationId);
+      set({ quarantined: (result || []) as unknown as QuarantinedRow[] });
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to load quarantined rows';
+      set({ error: message, quarantined: [] });
+    }
+  },
+
+  loadTextIssues: async (migrationId: string) => {
+    try {
+      const result = await GetTextIssues(migrationId);
+      set({ textIssues: (result || []) as unknown as TextIssue[] });
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to load text issues';
+      set({ error: message, textIssues: [] });
+    }
+  },
+
+  loadIndexBuilds: async (migrationId: string) => {
+    try {
+      const result = await GetIndexBuilds(migrationId);
+      set({ indexBuilds: (result || []) as unknown as IndexBuild[] });
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to load index builds';
+      set({ error: message, indexBuilds: [] });
+    }
+  },
+
+  loadMigrationTables: async (migrationId: string) => {
+    try {
+      const result = await GetMigrationTables(migrationId);
+      set({ migrationTables: (result || []) as unknown as TableMigrationState[] });
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to load migration tables';
+      set({ error: message, migrationTables: [] });
+    }
+  },
+
+  loadForeignKeyChecks: async (migrationId: string) => {
+    try {
+      const result = await GetForeignKeyChecks(migrationId);
+      set({ foreignKeyChecks: (result || []) as unknown as ForeignKeyCheck[] });
+    } catch (e: unknown) {
+      const message = e instanceof Error ? e.message : 'Failed to load foreign key checks';
+      set({ error: message, foreignKeyChecks: [] });
+    }
+  },
+
   toggleTableSelection: (tableName: string) => {
     const { selectedTables } = get();
     const index = selectedTables.indexOf(tableName);
@@ -264,6 +469,10 @@ function setupEventListeners(
   EventsOn('migration:table-complete', () => {
     get().refreshStatus();
   });
+
+  EventsOn('migration:replication', () => {
+    get().refreshStatus();
+  });
 }
 
 function cleanupEventListeners() {
@@ -272,4 +481,5 @@ function cleanupEventListeners() {
   EventsOff('migration:complete');
   EventsOff('migration:error');
   EventsOff('migration:table-complete');
+  EventsOff('migration:replication');
 }
@@ -27,6 +27,8 @@ export interface TableInfo {
   rowCount: number;
   columns?: ColumnInfo[];
   primaryKey?: string[];
+  primaryKeyClustered?: boolean;
+  uniqueKey?: IndexInfo; // 無主鍵時用來分頁的唯一索引
   foreignKeys?: ForeignKey[];
   indexes?: IndexInfo[];
 }
@@ -41,6 +43,8 @@ export interface ColumnInfo {
   isIdentity: boolean;
   defaultValue?: string;
   isPrimaryKey: boolean;
+  collation?: string;
+  codePage?: number;
 }
 
 export interface ForeignKey {
@@ -80,6 +84,40 @@ export interface MigrationConfig {
   batchSize: number;
   parallelTables: number;
   dropTargetIfExists: boolean;
+  splitTableRows?: number;
+  rangeParallelism?: number;
+  parentMigrationId?: string;
+  syncMode?: string;
+  replicationMethod?: string;
+  replicationPollSeconds?: number;
+  dryRun?: boolean;
+  loadMode?: string;
+  stagingSwap?: boolean;
+  quarantineBadRows?: boolean;
+  quarantineMaxRows?: number;
+  tableOrder?: string;
+  deferIndexes?: boolean;
+  indexParallelism?: number;
+  maintenanceWorkMemMB?: number;
+  foreignKeysNotValid?: boolean;
+  validateParallelism?: number;
+  bulkLoadProfile?: boolean;
+  unloggedTables?: boolean;
+  skipForeignKeyChecks?: boolean;
+  analyzeTables?: boolean;
+  vacuumTables?: boolean;
+  clusterTables?: boolean;
+  throttleRowsPerSec?: number;
+  throttleMBPerSec?: number;
+  timeWindows?: string;
+  snapshotMode?: string;
+  undecodableText?: string;
+  sourceTimeZone?: string;
+  timestampTz?: boolean;
+  typeMappingRuleSet?: string;
+  namingPolicy?: string;
+  typeMappingRules?: TypeMappingRule[];
+  identifierRenames?: Record<string, string>;
 }
 
 export interface MigrationRecord {
@@ -98,6 +136,7 @@ export interface MigrationRecord {
   completedTables: number;
   totalRows: number;
   migratedRows: number;
+  parentId?: string;
 }
 
 export interface MigrationState {
@@ -107,22 +146,49 @@ export interface MigrationState {
   CompletedTables: number;
   TotalRows: number;
   MigratedRows: number;
-  CurrentTable: string;
+  CurrentTables: string[];
   Tables: Record<string, TableState>;
+  Replication?: ReplicationState;
+  ThrottleRowsPerSec: number;
+  ThrottleMBPerSec: number;
   Errors: string[];
 }
 
+export interface ReplicationState {
+  Method: string;
+  Tables: number;
+  Cycles: number;
+  AppliedChanges: number;
+  LastBatch: number;
+  LagSeconds: number;
+  LastAppliedAt: string;
+  Position: string;
+  CutoverRequested: boolean;
+}
+
 export interface TableState {
   Name: string;
   Schema: string;
   Status: MigrationStatus;
   TotalRows: number;
   MigratedRows: number;
+  ReadStrategy: string;
+  ReadRowsPerSec: number;
+  WriteRowsPerSec: number;
+  Ranges?: RangeState[];
+  QuarantinedRows: number;
+  TextIssues: number;
   StartTime: string;
   EndTime: string;
   Error: string;
 }
 
+export interface RangeState {
+  Range: string;
+  Status: MigrationStatus;
+  MigratedRows: number;
+}
+
 // Log types
 export type LogLevel = 'debug' | 'info' | 'warn' | 'error';
 
@@ -136,6 +202,99 @@ export interface LogEntry {
   createdAt: string;
 }
 
+// Dry-run DDL script file
+export interface ScriptFile {
+  name: string;
+  content: string;
+}
+
+// Foreign key created or validated on the target, with the orphaned rows that kept it from validating
+export interface ForeignKeyCheck {
+  migrationId: string;
+  schemaName: string;
+  tableName: string;
+  constraintName: string;
+  referencedTable: string;
+  valid: boolean;
+  orphanCount: number;
+  sampleKeys: string;
+  error: string;
+  durationMs: number;
+  createdAt: string;
+}
+
+// Table selected for a migration, with the strategy its source rows were read with
+export interface TableMigrationState {
+  id: number;
+  migrationId: string;
+  tableName: string;
+  schemaName: string;
+  migrateOrder: number;
+  readStrategy: '' | 'keyset' | 'unique-index' | 'single-pass';
+}
+
+// Deferred index build with its duration, or the error when it failed
+export interface IndexBuild {
+  migrationId: string;
+  schemaName: string;
+  tableName: string;
+  indexName: string;
+  durationMs: number;
+  error: string;
+  createdAt: string;
+}
+
+// Source row the target rejected, set aside instead of failing the table
+export interface QuarantinedRow {
+  id: number;
+  migrationId: string;
+  schemaName: string;
+  tableName: string;
+  sourceKey: string;
+  rowData: string;
+  error: string;
+  createdAt: string;
+}
+
+export type TextIssueKind = 'undecodable' | 'private-use';
+
+export interface TextIssue {
+  id: number;
+  migrationId: string;
+  schemaName: string;
+  tableName: string;
+  columnName: string;
+  sourceKey: string;
+  kind: TextIssueKind;
+  codePage: number;
+  rawBytes: string;
+  text: string;
+  createdAt: string;
+}
+
+// Type mapping types
+export type TypeCast = 'none' | 'text' | 'integer' | 'numeric' | 'boolean' | 'money' | 'timestamptz';
+
+export interface TypeMappingRule {
+  sourceType?: string;
+  length?: number;
+  precision?: number;
+  scale?: number;
+  table?: string;
+  column?: string;
+  targetType: string;
+  cast?: TypeCast;
+}
+
+export interface TypeMappingRuleSet {
+  id: string;
+  name: string;
+  description: string;
+  rules: TypeMappingRule[];
+  createdAt: string;
+  updatedAt: string;
+}
+
 // Validation types
 export interface ValidationConfig {
   migrationId: string;
@@ -144,6 +303,10 @@ export interface ValidationConfig {
   sampleComparison: boolean;
   sampleSize: number;
   tables?: string[];
+  sourceTimeZone?: string;
+  timestampTz?: boolean;
+  typeMappingRuleSet?: string;
+  namingPolicy?: string;
 }
 
 export interface ValidationResult {
@@ -209,6 +372,8 @@ export interface ProgressEvent {
   totalRows: number;
   processedRows: number;
   percentage: number;
+  readRowsPerSec?: number;
+  writeRowsPerSec?: number;
 }
 
 export interface LogEvent {
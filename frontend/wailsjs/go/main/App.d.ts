@@ -5,14 +5,30 @@ import {migration} from '../models';
 
 export function CancelMigration():Promise<void>;
 
+export function Cutover():Promise<void>;
+
 export function DeleteConnection(arg1:string):Promise<void>;
 
+export function DeleteTypeMappingRuleSet(arg1:string):Promise<void>;
+
+export function ExportDDLScript(arg1:string):Promise<string>;
+
+export function ExportNameMappings(arg1:types.MigrationConfig):Promise<string>;
+
+export function ExportTypeMappingRuleSet(arg1:string):Promise<string>;
+
 export function GetAppVersion():Promise<string>;
 
 export function GetConnections():Promise<Array<types.ConnectionConfig>>;
 
+export function GetDDLScript(arg1:string):Promise<Array<types.ScriptFile>>;
+
+export function GetForeignKeyChecks(arg1:string):Promise<Array<types.ForeignKeyCheck>>;
+
 export function GetFunctions(arg1:string,arg2:string):Promise<Array<types.FunctionInfo>>;
 
+export function GetIndexBuilds(arg1:string):Promise<Array<types.IndexBuild>>;
+
 export function GetMSSQLConnections():Promise<Array<types.ConnectionConfig>>;
 
 export function GetMigration(arg1:string):Promise<types.MigrationRecord>;
@@ -27,20 +43,32 @@ export function GetMigrationTables(arg1:string):Promise<Array<types.TableMigrati
 
 export function GetPostgresConnections():Promise<Array<types.ConnectionConfig>>;
 
+export function GetQuarantinedRows(arg1:string):Promise<Array<types.QuarantinedRow>>;
+
+export function GetRetryTables(arg1:string):Promise<Array<string>>;
+
 export function GetStoredProcedures(arg1:string,arg2:string):Promise<Array<types.StoredProcedureInfo>>;
 
 export function GetTableDetails(arg1:string,arg2:string,arg3:string,arg4:string):Promise<types.TableInfo>;
 
 export function GetTables(arg1:string,arg2:string):Promise<Array<types.TableInfo>>;
 
+export function GetTextIssues(arg1:string):Promise<Array<types.TextIssue>>;
+
+export function GetTypeMappingRuleSets():Promise<Array<types.TypeMappingRuleSet>>;
+
 export function GetViews(arg1:string,arg2:string):Promise<Array<types.ViewInfo>>;
 
+export function ImportTypeMappingRules():Promise<types.TypeMappingRuleSet>;
+
 export function PauseMigration():Promise<void>;
 
-export function ResumeMigration():Promise<void>;
+export function ResumeMigration(arg1:string):Promise<void>;
 
 export function SaveConnection(arg1:types.ConnectionConfig):Promise<void>;
 
+export function SetThrottle(arg1:number,arg2:number):Promise<void>;
+
 export function StartMigration(arg1:types.MigrationConfig,arg2:string):Promise<string>;
 
 export function StartValidation(arg1:string,arg2:string,arg3:types.ValidationConfig):Promise<Array<types.ValidationResult>>;
@@ -6,10 +6,30 @@ export function CancelMigration() {
   return window['go']['main']['App']['CancelMigration']();
 }
 
+export function Cutover() {
+  return window['go']['main']['App']['Cutover']();
+}
+
 export function DeleteConnection(arg1) {
   return window['go']['main']['App']['DeleteConnection'](arg1);
 }
 
+export function DeleteTypeMappingRuleSet(arg1) {
+  return window['go']['main']['App']['DeleteTypeMappingRuleSet'](arg1);
+}
+
+export function ExportDDLScript(arg1) {
+  return window['go']['main']['App']['ExportDDLScript'](arg1);
+}
+
+export function ExportNameMappings(arg1) {
+  return window['go']['main']['App']['ExportNameMappings'](arg1);
+}
+
+export function ExportTypeMappingRuleSet(arg1) {
+  return window['go']['main']['App']['ExportTypeMappingRuleSet'](arg1);
+}
+
 export function GetAppVersion() {
   return window['go']['main']['App']['GetAppVersion']();
 }
@@ -18,10 +38,22 @@ export function GetConnections() {
   return window['go']['main']['App']['GetConnections']();
 }
 
+export function GetDDLScript(arg1) {
+  return window['go']['main']['App']['GetDDLScript'](arg1);
+}
+
+export function GetForeignKeyChecks(arg1) {
+  return window['go']['main']['App']['GetForeignKeyChecks'](arg1);
+}
+
 export function GetFunctions(arg1, arg2) {
   return window['go']['main']['App']['GetFunctions'](arg1, arg2);
 }
 
+export function GetIndexBuilds(arg1) {
+  return window['go']['main']['App']['GetIndexBuilds'](arg1);
+}
+
 export function GetMSSQLConnections() {
   return window['go']['main']['App']['GetMSSQLConnections']();
 }
@@ -50,6 +82,14 @@ export function GetPostgresConnections() {
   return window['go']['main']['App']['GetPostgresConnections']();
 }
 
+export function GetQuarantinedRows(arg1) {
+  return window['go']['main']['App']['GetQuarantinedRows'](arg1);
+}
+
+export function GetRetryTables(arg1) {
+  return window['go']['main']['App']['GetRetryTables'](arg1);
+}
+
 export function GetStoredProcedures(arg1, arg2) {
   return window['go']['main']['App']['GetStoredProcedures'](arg1, arg2);
 }
@@ -62,22 +102,38 @@ export function GetTables(arg1, arg2) {
   return window['go']['main']['App']['GetTables'](arg1, arg2);
 }
 
+export function GetTextIssues(arg1) {
+  return window['go']['main']['App']['GetTextIssues'](arg1);
+}
+
+export function GetTypeMappingRuleSets() {
+  return window['go']['main']['App']['GetTypeMappingRuleSets']();
+}
+
 export function GetViews(arg1, arg2) {
   return window['go']['main']['App']['GetViews'](arg1, arg2);
 }
 
+export function ImportTypeMappingRules() {
+  return window['go']['main']['App']['ImportTypeMappingRules']();
+}
+
 export function PauseMigration() {
   return window['go']['main']['App']['PauseMigration']();
 }
 
-export function ResumeMigration() {
-  return window['go']['main']['App']['ResumeMigration']();
+export function ResumeMigration(arg1) {
+  return window['go']['main']['App']['ResumeMigration'](arg1);
 }
 
 export function SaveConnection(arg1) {
   return window['go']['main']['App']['SaveConnection'](arg1);
 }
 
+export function SetThrottle(arg1, arg2) {
+  return window['go']['main']['App']['SetThrottle'](arg1, arg2);
+}
+
 export function StartMigration(arg1, arg2) {
   return window['go']['main']['App']['StartMigration'](arg1, arg2);
 }
@@ -1,11 +1,33 @@
 export namespace migration {
 	
+	export class RangeState {
+	    Range: string;
+	    Status: string;
+	    MigratedRows: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RangeState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Range = source["Range"];
+	        this.Status = source["Status"];
+	        this.MigratedRows = source["MigratedRows"];
+	    }
+	}
 	export class TableState {
 	    Name: string;
 	    Schema: string;
 	    Status: string;
 	    TotalRows: number;
 	    MigratedRows: number;
+	    ReadStrategy: string;
+	    ReadRowsPerSec: number;
+	    WriteRowsPerSec: number;
+	    Ranges: RangeState[];
+	    QuarantinedRows: number;
+	    TextIssues: number;
 	    // Go type: time
 	    StartTime: any;
 	    // Go type: time
@@ -23,6 +45,12 @@ export namespace migration {
 	        this.Status = source["Status"];
 	        this.TotalRows = source["TotalRows"];
 	        this.MigratedRows = source["MigratedRows"];
+	        this.ReadStrategy = source["ReadStrategy"];
+	        this.ReadRowsPerSec = source["ReadRowsPerSec"];
+	        this.WriteRowsPerSec = source["WriteRowsPerSec"];
+	        this.Ranges = this.convertValues(source["Ranges"], RangeState);
+	        this.QuarantinedRows = source["QuarantinedRows"];
+	        this.TextIssues = source["TextIssues"];
 	        this.StartTime = this.convertValues(source["StartTime"], null);
 	        this.EndTime = this.convertValues(source["EndTime"], null);
 	        this.Error = source["Error"];
@@ -46,6 +74,53 @@ export namespace migration {
 		    return a;
 		}
 	}
+	export class ReplicationState {
+	    Method: string;
+	    Tables: number;
+	    Cycles: number;
+	    AppliedChanges: number;
+	    LastBatch: number;
+	    LagSeconds: number;
+	    // Go type: time
+	    LastAppliedAt: any;
+	    Position: string;
+	    CutoverRequested: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReplicationState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Method = source["Method"];
+	        this.Tables = source["Tables"];
+	        this.Cycles = source["Cycles"];
+	        this.AppliedChanges = source["AppliedChanges"];
+	        this.LastBatch = source["LastBatch"];
+	        this.LagSeconds = source["LagSeconds"];
+	        this.LastAppliedAt = this.convertValues(source["LastAppliedAt"], null);
+	        this.Position = source["Position"];
+	        this.CutoverRequested = source["CutoverRequested"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class MigrationState {
 	    Status: string;
 	    // Go type: time
@@ -54,8 +129,11 @@ export namespace migration {
 	    CompletedTables: number;
 	    TotalRows: number;
 	    MigratedRows: number;
-	    CurrentTable: string;
+	    CurrentTables: string[];
 	    Tables: Record<string, TableState>;
+	    Replication?: ReplicationState;
+	    ThrottleRowsPerSec: number;
+	    ThrottleMBPerSec: number;
 	    Errors: string[];
 	
 	    static createFrom(source: any = {}) {
@@ -70,8 +148,11 @@ export namespace migration {
 	        this.CompletedTables = source["CompletedTables"];
 	        this.TotalRows = source["TotalRows"];
 	        this.MigratedRows = source["MigratedRows"];
-	        this.CurrentTable = source["CurrentTable"];
+	        this.CurrentTables = source["CurrentTables"];
 	        this.Tables = this.convertValues(source["Tables"], TableState, true);
+	        this.Replication = this.convertValues(source["Replication"], ReplicationState);
+	        this.ThrottleRowsPerSec = source["ThrottleRowsPerSec"];
+	        this.ThrottleMBPerSec = source["ThrottleMBPerSec"];
 	        this.Errors = source["Errors"];
 	    }
 	
@@ -124,6 +205,8 @@ export namespace types {
 	    isIdentity: boolean;
 	    defaultValue?: string;
 	    isPrimaryKey: boolean;
+	    collation?: string;
+	    codePage?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new ColumnInfo(source);
@@ -140,6 +223,8 @@ export namespace types {
 	        this.isIdentity = source["isIdentity"];
 	        this.defaultValue = source["defaultValue"];
 	        this.isPrimaryKey = source["isPrimaryKey"];
+	        this.collation = source["collation"];
+	        this.codePage = source["codePage"];
 	    }
 	}
 	export class ConnectionConfig {
@@ -230,6 +315,57 @@ export namespace types {
 	        this.onUpdate = source["onUpdate"];
 	    }
 	}
+	export class ForeignKeyCheck {
+	    migrationId: string;
+	    schemaName: string;
+	    tableName: string;
+	    constraintName: string;
+	    referencedTable: string;
+	    valid: boolean;
+	    orphanCount: number;
+	    sampleKeys: string;
+	    error: string;
+	    durationMs: number;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ForeignKeyCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.migrationId = source["migrationId"];
+	        this.schemaName = source["schemaName"];
+	        this.tableName = source["tableName"];
+	        this.constraintName = source["constraintName"];
+	        this.referencedTable = source["referencedTable"];
+	        this.valid = source["valid"];
+	        this.orphanCount = source["orphanCount"];
+	        this.sampleKeys = source["sampleKeys"];
+	        this.error = source["error"];
+	        this.durationMs = source["durationMs"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class ParameterInfo {
 	    name: string;
 	    dataType: string;
@@ -286,6 +422,49 @@ export namespace types {
 		    return a;
 		}
 	}
+	export class IndexBuild {
+	    migrationId: string;
+	    schemaName: string;
+	    tableName: string;
+	    indexName: string;
+	    durationMs: number;
+	    error: string;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new IndexBuild(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.migrationId = source["migrationId"];
+	        this.schemaName = source["schemaName"];
+	        this.tableName = source["tableName"];
+	        this.indexName = source["indexName"];
+	        this.durationMs = source["durationMs"];
+	        this.error = source["error"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class IndexInfo {
 	    name: string;
 	    columns: string[];
@@ -370,6 +549,40 @@ export namespace types {
 	    batchSize: number;
 	    parallelTables: number;
 	    dropTargetIfExists: boolean;
+	    splitTableRows: number;
+	    rangeParallelism: number;
+	    parentMigrationId: string;
+	    syncMode: string;
+	    replicationMethod: string;
+	    replicationPollSeconds: number;
+	    dryRun: boolean;
+	    loadMode: string;
+	    stagingSwap: boolean;
+	    quarantineBadRows: boolean;
+	    quarantineMaxRows: number;
+	    tableOrder: string;
+	    deferIndexes: boolean;
+	    indexParallelism: number;
+	    maintenanceWorkMemMB: number;
+	    foreignKeysNotValid: boolean;
+	    validateParallelism: number;
+	    bulkLoadProfile: boolean;
+	    unloggedTables: boolean;
+	    skipForeignKeyChecks: boolean;
+	    analyzeTables: boolean;
+	    vacuumTables: boolean;
+	    clusterTables: boolean;
+	    throttleRowsPerSec: number;
+	    throttleMBPerSec: number;
+	    timeWindows: string;
+	    snapshotMode: string;
+	    undecodableText: string;
+	    sourceTimeZone: string;
+	    timestampTz: boolean;
+	    typeMappingRuleSet: string;
+	    namingPolicy: string;
+	    typeMappingRules?: TypeMappingRule[];
+	    identifierRenames?: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
 	        return new MigrationConfig(source);
@@ -393,7 +606,59 @@ export namespace types {
 	        this.batchSize = source["batchSize"];
 	        this.parallelTables = source["parallelTables"];
 	        this.dropTargetIfExists = source["dropTargetIfExists"];
+	        this.splitTableRows = source["splitTableRows"];
+	        this.rangeParallelism = source["rangeParallelism"];
+	        this.parentMigrationId = source["parentMigrationId"];
+	        this.syncMode = source["syncMode"];
+	        this.replicationMethod = source["replicationMethod"];
+	        this.replicationPollSeconds = source["replicationPollSeconds"];
+	        this.dryRun = source["dryRun"];
+	        this.loadMode = source["loadMode"];
+	        this.stagingSwap = source["stagingSwap"];
+	        this.quarantineBadRows = source["quarantineBadRows"];
+	        this.quarantineMaxRows = source["quarantineMaxRows"];
+	        this.tableOrder = source["tableOrder"];
+	        this.deferIndexes = source["deferIndexes"];
+	        this.indexParallelism = source["indexParallelism"];
+	        this.maintenanceWorkMemMB = source["maintenanceWorkMemMB"];
+	        this.foreignKeysNotValid = source["foreignKeysNotValid"];
+	        this.validateParallelism = source["validateParallelism"];
+	        this.bulkLoadProfile = source["bulkLoadProfile"];
+	        this.unloggedTables = source["unloggedTables"];
+	        this.skipForeignKeyChecks = source["skipForeignKeyChecks"];
+	        this.analyzeTables = source["analyzeTables"];
+	        this.vacuumTables = source["vacuumTables"];
+	        this.clusterTables = source["clusterTables"];
+	        this.throttleRowsPerSec = source["throttleRowsPerSec"];
+	        this.throttleMBPerSec = source["throttleMBPerSec"];
+	        this.timeWindows = source["timeWindows"];
+	        this.snapshotMode = source["snapshotMode"];
+	        this.undecodableText = source["undecodableText"];
+	        this.sourceTimeZone = source["sourceTimeZone"];
+	        this.timestampTz = source["timestampTz"];
+	        this.typeMappingRuleSet = source["typeMappingRuleSet"];
+	        this.namingPolicy = source["namingPolicy"];
+	        this.typeMappingRules = this.convertValues(source["typeMappingRules"], TypeMappingRule);
+	        this.identifierRenames = source["identifierRenames"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class MigrationRecord {
 	    id: string;
@@ -412,6 +677,7 @@ export namespace types {
 	    completedTables: number;
 	    totalRows: number;
 	    migratedRows: number;
+	    parentId: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new MigrationRecord(source);
@@ -432,6 +698,7 @@ export namespace types {
 	        this.completedTables = source["completedTables"];
 	        this.totalRows = source["totalRows"];
 	        this.migratedRows = source["migratedRows"];
+	        this.parentId = source["parentId"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -487,6 +754,65 @@ export namespace types {
 		}
 	}
 	
+	export class QuarantinedRow {
+	    id: number;
+	    migrationId: string;
+	    schemaName: string;
+	    tableName: string;
+	    sourceKey: string;
+	    rowData: string;
+	    error: string;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new QuarantinedRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.migrationId = source["migrationId"];
+	        this.schemaName = source["schemaName"];
+	        this.tableName = source["tableName"];
+	        this.sourceKey = source["sourceKey"];
+	        this.rowData = source["rowData"];
+	        this.error = source["error"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScriptFile {
+	    name: string;
+	    content: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScriptFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.content = source["content"];
+	    }
+	}
 	export class StoredProcedureInfo {
 	    schema: string;
 	    name: string;
@@ -529,6 +855,8 @@ export namespace types {
 	    rowCount: number;
 	    columns: ColumnInfo[];
 	    primaryKey: string[];
+	    primaryKeyClustered: boolean;
+	    uniqueKey?: IndexInfo;
 	    foreignKeys: ForeignKey[];
 	    indexes: IndexInfo[];
 	
@@ -543,6 +871,8 @@ export namespace types {
 	        this.rowCount = source["rowCount"];
 	        this.columns = this.convertValues(source["columns"], ColumnInfo);
 	        this.primaryKey = source["primaryKey"];
+	        this.primaryKeyClustered = source["primaryKeyClustered"];
+	        this.uniqueKey = this.convertValues(source["uniqueKey"], IndexInfo);
 	        this.foreignKeys = this.convertValues(source["foreignKeys"], ForeignKey);
 	        this.indexes = this.convertValues(source["indexes"], IndexInfo);
 	    }
@@ -571,6 +901,7 @@ export namespace types {
 	    tableName: string;
 	    schemaName: string;
 	    migrateOrder: number;
+	    readStrategy: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new TableMigrationState(source);
@@ -583,7 +914,127 @@ export namespace types {
 	        this.tableName = source["tableName"];
 	        this.schemaName = source["schemaName"];
 	        this.migrateOrder = source["migrateOrder"];
+	        this.readStrategy = source["readStrategy"];
+	    }
+	}
+	export class TextIssue {
+	    id: number;
+	    migrationId: string;
+	    schemaName: string;
+	    tableName: string;
+	    columnName: string;
+	    sourceKey: string;
+	    kind: string;
+	    codePage: number;
+	    rawBytes: string;
+	    text: string;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new TextIssue(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.migrationId = source["migrationId"];
+	        this.schemaName = source["schemaName"];
+	        this.tableName = source["tableName"];
+	        this.columnName = source["columnName"];
+	        this.sourceKey = source["sourceKey"];
+	        this.kind = source["kind"];
+	        this.codePage = source["codePage"];
+	        this.rawBytes = source["rawBytes"];
+	        this.text = source["text"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TypeMappingRule {
+	    sourceType?: string;
+	    length?: number;
+	    precision?: number;
+	    scale?: number;
+	    table?: string;
+	    column?: string;
+	    targetType: string;
+	    cast?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TypeMappingRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sourceType = source["sourceType"];
+	        this.length = source["length"];
+	        this.precision = source["precision"];
+	        this.scale = source["scale"];
+	        this.table = source["table"];
+	        this.column = source["column"];
+	        this.targetType = source["targetType"];
+	        this.cast = source["cast"];
+	    }
+	}
+	export class TypeMappingRuleSet {
+	    id: string;
+	    name: string;
+	    description: string;
+	    rules: TypeMappingRule[];
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    updatedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new TypeMappingRuleSet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.rules = this.convertValues(source["rules"], TypeMappingRule);
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class ValidationConfig {
 	    migrationId: string;
@@ -592,6 +1043,11 @@ export namespace types {
 	    sampleComparison: boolean;
 	    sampleSize: number;
 	    tables?: string[];
+	    sourceTimeZone: string;
+	    timestampTz: boolean;
+	    typeMappingRuleSet: string;
+	    namingPolicy: string;
+	    identifierRenames?: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
 	        return new ValidationConfig(source);
@@ -605,6 +1061,11 @@ export namespace types {
 	        this.sampleComparison = source["sampleComparison"];
 	        this.sampleSize = source["sampleSize"];
 	        this.tables = source["tables"];
+	        this.sourceTimeZone = source["sourceTimeZone"];
+	        this.timestampTz = source["timestampTz"];
+	        this.typeMappingRuleSet = source["typeMappingRuleSet"];
+	        this.namingPolicy = source["namingPolicy"];
+	        this.identifierRenames = source["identifierRenames"];
 	    }
 	}
 	export class ValidationResult {
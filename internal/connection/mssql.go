@@ -5,10 +5,11 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"adaru-db-tool/internal/types"
 
-	_ "github.com/microsoft/go-mssqldb"
+	mssql "github.com/microsoft/go-mssqldb"
 )
 
 // MSSQLConnection represents a connection to Microsoft SQL Server
@@ -16,6 +17,8 @@ type MSSQLConnection struct {
 	db           *sql.DB
 	connString   string
 	databaseName string
+	snapshotConn *sql.Conn // BeginSnapshot 之後，批次讀取都經由此 session 的交易
+	snapshotTx   *sql.Tx
 }
 
 // NewMSSQLConnection creates a new MSSQL connection
@@ -50,6 +53,10 @@ func (c *MSSQLConnection) Connect(ctx context.Context) error {
 
 // Close closes the database connection
 func (c *MSSQLConnection) Close() error {
+	if c.snapshotTx != nil {
+		c.snapshotTx.Rollback()
+		c.snapshotConn.Close()
+	}
 	if c.db != nil {
 		return c.db.Close()
 	}
@@ -170,11 +177,21 @@ func (c *MSSQLConnection) GetTableDetails(ctx context.Context, schema, tableName
 	table.Columns = columns
 
 	// Get primary key
-	pk, err := c.getTablePrimaryKey(ctx, schema, tableName)
+	pk, clustered, err := c.getTablePrimaryKey(ctx, schema, tableName)
 	if err != nil {
 		return nil, err
 	}
 	table.PrimaryKey = pk
+	table.PrimaryKeyClustered = clustered
+
+	// 無主鍵時找出可取代主鍵的唯一索引
+	if len(pk) == 0 {
+		uniqueKey, err := c.getTableUniqueKey(ctx, schema, tableName)
+		if err != nil {
+			return nil, err
+		}
+		table.UniqueKey = uniqueKey
+	}
 
 	// Mark primary key columns
 	for i := range table.Columns {
@@ -221,7 +238,11 @@ func (c *MSSQLConnection) getTableColumns(ctx context.Context, schema, tableName
 			c.scale,
 			c.is_nullable,
 			c.is_identity,
-			dc.definition AS default_value
+			dc.definition AS default_value,
+			c.collation_name,
+			-- 只有非 Unicode 字串欄位以定序的字碼頁儲存位元組（如 Chinese_Taiwan_Stroke_CI_AS 為 950）
+			CASE WHEN TYPE_NAME(c.system_type_id) IN ('char', 'varchar', 'text')
+				THEN CAST(COLLATIONPROPERTY(c.collation_name, 'CodePage') AS int) END AS code_page
 		FROM sys.columns c
 		INNER JOIN sys.types t ON c.user_type_id = t.user_type_id
 		INNER JOIN sys.tables tb ON c.object_id = tb.object_id
@@ -242,7 +263,8 @@ func (c *MSSQLConnection) getTableColumns(ctx context.Context, schema, tableName
 	var columns []types.ColumnInfo
 	for rows.Next() {
 		var col types.ColumnInfo
-		var defaultVal sql.NullString
+		var defaultVal, collation sql.NullString
+		var codePage sql.NullInt64
 		if err := rows.Scan(
 			&col.Name,
 			&col.DataType,
@@ -252,21 +274,25 @@ func (c *MSSQLConnection) getTableColumns(ctx context.Context, schema, tableName
 			&col.IsNullable,
 			&col.IsIdentity,
 			&defaultVal,
+			&collation,
+			&codePage,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan column: %w", err)
 		}
 		if defaultVal.Valid {
 			col.DefaultValue = &defaultVal.String
 		}
+		col.Collation = collation.String
+		col.CodePage = int(codePage.Int64)
 		columns = append(columns, col)
 	}
 
 	return columns, nil
 }
 
-func (c *MSSQLConnection) getTablePrimaryKey(ctx context.Context, schema, tableName string) ([]string, error) {
+func (c *MSSQLConnection) getTablePrimaryKey(ctx context.Context, schema, tableName string) ([]string, bool, error) {
 	query := `
-		SELECT col.name
+		SELECT col.name, idx.type_desc
 		FROM sys.indexes idx
 		INNER JOIN sys.index_columns ic ON idx.object_id = ic.object_id AND idx.index_id = ic.index_id
 		INNER JOIN sys.columns col ON ic.object_id = col.object_id AND ic.column_id = col.column_id
@@ -280,20 +306,67 @@ func (c *MSSQLConnection) getTablePrimaryKey(ctx context.Context, schema, tableN
 		sql.Named("schema", schema),
 		sql.Named("table", tableName))
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	defer rows.Close()
 
 	var columns []string
+	var clustered bool
 	for rows.Next() {
-		var col string
-		if err := rows.Scan(&col); err != nil {
-			return nil, err
+		var col, typeDesc string
+		if err := rows.Scan(&col, &typeDesc); err != nil {
+			return nil, false, err
 		}
 		columns = append(columns, col)
+		clustered = typeDesc == "CLUSTERED"
 	}
 
-	return columns, nil
+	return columns, clustered, nil
+}
+
+// getTableUniqueKey returns the unique index that identifies every row of a table without a primary key:
+// not filtered, not disabled and with no nullable key column. The index with the fewest key columns is preferred.
+// It returns nil when there is no such index.
+func (c *MSSQLConnection) getTableUniqueKey(ctx context.Context, schema, tableName string) (*types.IndexInfo, error) {
+	query := `
+		SELECT idx.name, col.name, idx.type_desc
+		FROM sys.indexes idx
+		INNER JOIN sys.index_columns ic ON idx.object_id = ic.object_id AND idx.index_id = ic.index_id AND ic.is_included_column = 0
+		INNER JOIN sys.columns col ON ic.object_id = col.object_id AND ic.column_id = col.column_id
+		WHERE idx.object_id = OBJECT_ID(@name)
+			AND idx.is_unique = 1 AND idx.is_primary_key = 0 AND idx.has_filter = 0 AND idx.is_disabled = 0
+			AND NOT EXISTS (
+				SELECT 1 FROM sys.index_columns nic
+				INNER JOIN sys.columns ncol ON nic.object_id = ncol.object_id AND nic.column_id = ncol.column_id
+				WHERE nic.object_id = idx.object_id AND nic.index_id = idx.index_id
+					AND nic.is_included_column = 0 AND ncol.is_nullable = 1
+			)
+		ORDER BY
+			(SELECT COUNT(*) FROM sys.index_columns kc
+			 WHERE kc.object_id = idx.object_id AND kc.index_id = idx.index_id AND kc.is_included_column = 0),
+			idx.name, ic.key_ordinal
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, sql.Named("name", fmt.Sprintf("[%s].[%s]", schema, tableName)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var key *types.IndexInfo
+	for rows.Next() {
+		var idxName, colName, typeDesc string
+		if err := rows.Scan(&idxName, &colName, &typeDesc); err != nil {
+			return nil, err
+		}
+		if key == nil {
+			key = &types.IndexInfo{Name: idxName, IsUnique: true, IsClustered: typeDesc == "CLUSTERED"}
+		} else if idxName != key.Name {
+			break // 只取第一個索引的欄位
+		}
+		key.Columns = append(key.Columns, colName)
+	}
+	return key, rows.Err()
 }
 
 func (c *MSSQLConnection) getTableForeignKeys(ctx context.Context, schema, tableName string) ([]types.ForeignKey, error) {
@@ -352,6 +425,65 @@ func (c *MSSQLConnection) getTableForeignKeys(ctx context.Context, schema, table
 	return fks, nil
 }
 
+// GetForeignKeys retrieves the foreign keys of all tables in the current database,
+// keyed by the "schema.table" name of the referencing table
+func (c *MSSQLConnection) GetForeignKeys(ctx context.Context) (map[string][]types.ForeignKey, error) {
+	query := `
+		SELECT
+			s.name AS schema_name,
+			t.name AS table_name,
+			fk.name AS fk_name,
+			COL_NAME(fkc.parent_object_id, fkc.parent_column_id) AS column_name,
+			OBJECT_SCHEMA_NAME(fk.referenced_object_id) AS ref_schema,
+			OBJECT_NAME(fk.referenced_object_id) AS ref_table,
+			COL_NAME(fkc.referenced_object_id, fkc.referenced_column_id) AS ref_column,
+			fk.delete_referential_action_desc,
+			fk.update_referential_action_desc
+		FROM sys.foreign_keys fk
+		INNER JOIN sys.foreign_key_columns fkc ON fk.object_id = fkc.constraint_object_id
+		INNER JOIN sys.tables t ON fk.parent_object_id = t.object_id
+		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
+		ORDER BY s.name, t.name, fk.name, fkc.constraint_column_id
+	`
+
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]types.ForeignKey)
+	var last *types.ForeignKey
+	var lastTable string
+	for rows.Next() {
+		var schema, tableName, fkName, colName, refSchema, refTable, refCol, onDelete, onUpdate string
+		if err := rows.Scan(&schema, &tableName, &fkName, &colName, &refSchema, &refTable, &refCol, &onDelete, &onUpdate); err != nil {
+			return nil, err
+		}
+
+		// 依表格與外鍵名稱排序，同一外鍵的欄位連續出現
+		fullName := schema + "." + tableName
+		if last != nil && lastTable == fullName && last.Name == fkName {
+			last.Columns = append(last.Columns, colName)
+			last.ReferencedColumns = append(last.ReferencedColumns, refCol)
+			continue
+		}
+		result[fullName] = append(result[fullName], types.ForeignKey{
+			Name:              fkName,
+			Columns:           []string{colName},
+			ReferencedSchema:  refSchema,
+			ReferencedTable:   refTable,
+			ReferencedColumns: []string{refCol},
+			OnDelete:          onDelete,
+			OnUpdate:          onUpdate,
+		})
+		fks := result[fullName]
+		last = &fks[len(fks)-1]
+		lastTable = fullName
+	}
+	return result, rows.Err()
+}
+
 func (c *MSSQLConnection) getTableIndexes(ctx context.Context, schema, tableName string) ([]types.IndexInfo, error) {
 	query := `
 		SELECT
@@ -558,26 +690,147 @@ func (c *MSSQLConnection) GetTriggers(ctx context.Context) ([]types.TriggerInfo,
 	return triggers, nil
 }
 
-// ReadBatch reads a batch of rows from a table
-func (c *MSSQLConnection) ReadBatch(ctx context.Context, schema, tableName string, columns []string, orderBy string, offset, limit int) ([][]interface{}, error) {
-	colList := strings.Join(columns, ", ")
-	query := fmt.Sprintf(`
+// BatchQuery describes one page of rows to read from a table
+type BatchQuery struct {
+	Schema     string
+	Table      string
+	Columns    []string           // 欄位清單（已加中括號）
+	KeyColumns []types.ColumnInfo // keyset 欄位；為空時使用 OFFSET 分頁
+	LastKey    []interface{}      // 上一頁最後一筆的 key；nil 表示第一頁
+	OrderBy    string             // OFFSET 分頁使用的 ORDER BY；與 KeyColumns 皆為空時不分頁，一次讀出整張表
+	Offset     int
+	Limit      int
+
+	// 範圍切分：只讀取 RangeFrom <= RangeColumn < RangeTo 的資料，nil 表示該端不設限
+	RangeColumn string
+	RangeFrom   interface{}
+	RangeTo     interface{}
+
+	// rowversion 篩選：只讀取 VersionFrom <= VersionColumn < VersionTo 的資料，nil 表示該端不設限
+	VersionColumn string
+	VersionFrom   []byte
+	VersionTo     []byte
+}
+
+// build returns the T-SQL and parameters for the page
+func (q BatchQuery) build() (string, []interface{}) {
+	colList := strings.Join(q.Columns, ", ")
+
+	// 不分頁：單一查詢依伺服器回傳順序讀出整張表
+	if len(q.KeyColumns) == 0 && q.OrderBy == "" {
+		return fmt.Sprintf(`
+		SELECT %s
+		FROM [%s].[%s]
+	`, colList, q.Schema, q.Table), nil
+	}
+
+	if len(q.KeyColumns) == 0 {
+		return fmt.Sprintf(`
 		SELECT %s
 		FROM [%s].[%s]
 		ORDER BY %s
 		OFFSET %d ROWS FETCH NEXT %d ROWS ONLY
-	`, colList, schema, tableName, orderBy, offset, limit)
+	`, colList, q.Schema, q.Table, q.OrderBy, q.Offset, q.Limit), nil
+	}
 
-	rows, err := c.db.QueryContext(ctx, query)
+	keyNames := make([]string, len(q.KeyColumns))
+	orderBy := make([]string, len(q.KeyColumns))
+	for i, col := range q.KeyColumns {
+		keyNames[i] = col.Name
+		orderBy[i] = fmt.Sprintf("[%s]", col.Name)
+	}
+
+	var conditions []string
+	var args []interface{}
+	if q.LastKey != nil {
+		conditions = append(conditions, KeysetPredicate(keyNames))
+		for i, val := range q.LastKey {
+			args = append(args, sql.Named(fmt.Sprintf("k%d", i), keyParam(q.KeyColumns[i], val)))
+		}
+	}
+	if q.RangeColumn != "" && q.RangeFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("[%s] >= @rfrom", q.RangeColumn))
+		args = append(args, sql.Named("rfrom", q.RangeFrom))
+	}
+	if q.RangeColumn != "" && q.RangeTo != nil {
+		conditions = append(conditions, fmt.Sprintf("[%s] < @rto", q.RangeColumn))
+		args = append(args, sql.Named("rto", q.RangeTo))
+	}
+	if q.VersionColumn != "" && q.VersionFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("[%s] >= @vfrom", q.VersionColumn))
+		args = append(args, sql.Named("vfrom", q.VersionFrom))
+	}
+	if q.VersionColumn != "" && q.VersionTo != nil {
+		conditions = append(conditions, fmt.Sprintf("[%s] < @vto", q.VersionColumn))
+		args = append(args, sql.Named("vto", q.VersionTo))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	return fmt.Sprintf(`
+		SELECT TOP (%d) %s
+		FROM [%s].[%s]
+		%s
+		ORDER BY %s
+	`, q.Limit, colList, q.Schema, q.Table, where, strings.Join(orderBy, ", ")), args
+}
+
+// ReadBatch reads a batch of rows from a table
+func (c *MSSQLConnection) ReadBatch(ctx context.Context, schema, tableName string, columns []string, orderBy string, offset, limit int) ([][]interface{}, error) {
+	return c.collectBatch(ctx, BatchQuery{
+		Schema:  schema,
+		Table:   tableName,
+		Columns: columns,
+		OrderBy: orderBy,
+		Offset:  offset,
+		Limit:   limit,
+	})
+}
+
+// ReadBatchAfter reads the next batch of rows using keyset pagination.
+// Rows are ordered by keyColumns and only rows whose key is greater than lastKey are returned,
+// so each batch is an index seek instead of rescanning every row before the offset.
+// A nil lastKey reads the first batch.
+func (c *MSSQLConnection) ReadBatchAfter(ctx context.Context, schema, tableName string, columns []string, keyColumns []types.ColumnInfo, lastKey []interface{}, limit int) ([][]interface{}, error) {
+	return c.collectBatch(ctx, BatchQuery{
+		Schema:     schema,
+		Table:      tableName,
+		Columns:    columns,
+		KeyColumns: keyColumns,
+		LastKey:    lastKey,
+		Limit:      limit,
+	})
+}
+
+// collectBatch reads a whole page into memory
+func (c *MSSQLConnection) collectBatch(ctx context.Context, q BatchQuery) ([][]interface{}, error) {
+	var results [][]interface{}
+	_, err := c.StreamBatch(ctx, q, func(row []interface{}) error {
+		results = append(results, row)
+		return nil
+	})
+	return results, err
+}
+
+// StreamBatch reads one page and hands each row to emit as soon as it is scanned,
+// without materializing the page. Returning an error from emit stops the read.
+// 回傳已讀取的行數
+func (c *MSSQLConnection) StreamBatch(ctx context.Context, q BatchQuery, emit func(row []interface{}) error) (int, error) {
+	query, args := q.build()
+
+	rows, err := c.reader().QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 	defer rows.Close()
 
-	var results [][]interface{}
 	colTypes, _ := rows.ColumnTypes()
 	numCols := len(colTypes)
 
+	count := 0
 	for rows.Next() {
 		values := make([]interface{}, numCols)
 		valuePtrs := make([]interface{}, numCols)
@@ -586,10 +839,192 @@ func (c *MSSQLConnection) ReadBatch(ctx context.Context, schema, tableName strin
 		}
 
 		if err := rows.Scan(valuePtrs...); err != nil {
+			return count, err
+		}
+		if err := emit(values); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, rows.Err()
+}
+
+// KeysetPredicate builds the T-SQL equivalent of (k0, k1, ...) > (@k0, @k1, ...).
+// SQL Server has no row-value comparison, so the tuple comparison is expanded to
+// (k0 > @k0) OR (k0 = @k0 AND k1 > @k1) OR ...
+func KeysetPredicate(keyColumns []string) string {
+	var terms []string
+	for i := range keyColumns {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("[%s] = @k%d", keyColumns[j], j))
+		}
+		parts = append(parts, fmt.Sprintf("[%s] > @k%d", keyColumns[i], i))
+		terms = append(terms, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(terms, " OR ") + ")"
+}
+
+// keyParam wraps a key value so it is sent with a type matching the key column.
+// go-mssqldb sends Go strings as NVARCHAR, which forces an implicit conversion
+// (and an index scan) when the key column is VARCHAR/CHAR.
+// Keys read as raw bytes in the column's code page are sent as VARBINARY, which SQL Server
+// converts to the column's type and collation, so the seek compares the original bytes.
+// Decimal and money values are also read as bytes, but hold the number as text; sent as
+// VARBINARY they would be read as the decimal's storage format, so they are sent as strings.
+// Go times are sent as DATETIMEOFFSET(7); a datetime key is read rounded to whole milliseconds,
+// which no longer equals the stored 1/300 s value, so it is sent as DATETIME to match exactly.
+func keyParam(col types.ColumnInfo, val interface{}) interface{} {
+	switch v := val.(type) {
+	case time.Time:
+		if strings.EqualFold(col.DataType, "datetime") {
+			return mssql.DateTime1(v)
+		}
+	case string:
+		switch strings.ToLower(col.DataType) {
+		case "varchar", "char":
+			return mssql.VarChar(v)
+		}
+	case []byte:
+		switch strings.ToLower(col.DataType) {
+		case "decimal", "numeric", "money", "smallmoney":
+			return string(v)
+		}
+	}
+	return val
+}
+
+// HistogramStep is one step of a statistics histogram on an integer key column
+type HistogramStep struct {
+	HighKey int64
+	Rows    int64 // 該 step 涵蓋的行數（range_rows + equal_rows）
+}
+
+// GetKeyBoundaries returns up to parts-1 split points of an integer key column so that
+// the ranges between them hold roughly equal numbers of rows.
+// It uses the histogram of the column's statistics when available and falls back to an
+// even MIN/MAX split. The second return value names the method that was used.
+func (c *MSSQLConnection) GetKeyBoundaries(ctx context.Context, schema, tableName, column string, parts int) ([]int64, string, error) {
+	steps, err := c.getKeyHistogram(ctx, schema, tableName, column)
+	if err == nil && len(steps) > 1 {
+		if bounds := SplitHistogram(steps, parts); len(bounds) > 0 {
+			return bounds, "histogram", nil
+		}
+	}
+
+	// sys.dm_db_stats_histogram 需 SQL Server 2016 SP1 CU2 以上，或欄位尚無統計資訊時退回 MIN/MAX
+	query := fmt.Sprintf("SELECT MIN(CONVERT(bigint, [%s])), MAX(CONVERT(bigint, [%s])) FROM [%s].[%s]",
+		column, column, schema, tableName)
+	var minKey, maxKey sql.NullInt64
+	if err := c.db.QueryRowContext(ctx, query).Scan(&minKey, &maxKey); err != nil {
+		return nil, "", fmt.Errorf("failed to read key range: %w", err)
+	}
+	if !minKey.Valid || !maxKey.Valid {
+		return nil, "min/max", nil
+	}
+	return SplitEven(minKey.Int64, maxKey.Int64, parts), "min/max", nil
+}
+
+// getKeyHistogram reads the histogram of the first statistics object led by column
+func (c *MSSQLConnection) getKeyHistogram(ctx context.Context, schema, tableName, column string) ([]HistogramStep, error) {
+	query := `
+		WITH st AS (
+			SELECT TOP 1 s.object_id, s.stats_id
+			FROM sys.stats s
+			INNER JOIN sys.stats_columns sc ON s.object_id = sc.object_id AND s.stats_id = sc.stats_id AND sc.stats_column_id = 1
+			INNER JOIN sys.columns col ON sc.object_id = col.object_id AND sc.column_id = col.column_id
+			WHERE s.object_id = OBJECT_ID(@object) AND col.name = @column
+			ORDER BY s.stats_id
+		)
+		SELECT CONVERT(bigint, h.range_high_key), CONVERT(bigint, h.range_rows + h.equal_rows)
+		FROM st
+		CROSS APPLY sys.dm_db_stats_histogram(st.object_id, st.stats_id) h
+		ORDER BY h.step_number
+	`
+
+	rows, err := c.db.QueryContext(ctx, query,
+		sql.Named("object", fmt.Sprintf("[%s].[%s]", schema, tableName)),
+		sql.Named("column", column))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var steps []HistogramStep
+	for rows.Next() {
+		var step HistogramStep
+		if err := rows.Scan(&step.HighKey, &step.Rows); err != nil {
 			return nil, err
 		}
-		results = append(results, values)
+		steps = append(steps, step)
 	}
+	return steps, rows.Err()
+}
+
+// SplitHistogram picks up to parts-1 histogram high keys at evenly spaced cumulative row counts
+func SplitHistogram(steps []HistogramStep, parts int) []int64 {
+	var total int64
+	for _, step := range steps {
+		total += step.Rows
+	}
+	if parts < 2 || total == 0 {
+		return nil
+	}
+
+	var bounds []int64
+	var cumulative int64
+	next := 1
+	for _, step := range steps {
+		cumulative += step.Rows
+		for next < parts && cumulative >= total*int64(next)/int64(parts) {
+			// 切點必須嚴格遞增，避免產生空範圍
+			if len(bounds) == 0 || step.HighKey > bounds[len(bounds)-1] {
+				bounds = append(bounds, step.HighKey)
+			}
+			next++
+		}
+	}
+
+	// 最後一個切點等於最大鍵時，最後一個範圍只剩下等於該鍵以外的空集合，直接捨去
+	if len(bounds) > 0 && bounds[len(bounds)-1] >= steps[len(steps)-1].HighKey {
+		bounds = bounds[:len(bounds)-1]
+	}
+	return bounds
+}
+
+// SplitEven divides [minKey, maxKey] into parts ranges of equal key width
+func SplitEven(minKey, maxKey int64, parts int) []int64 {
+	if parts < 2 || maxKey <= minKey {
+		return nil
+	}
+
+	// 以無號整數計算跨度，避免 bigint 全範圍時溢位
+	span := uint64(maxKey) - uint64(minKey)
+	width := span / uint64(parts)
+	if span%uint64(parts) == uint64(parts)-1 {
+		width++ // 等同 (span+1)/parts，但不會在 span+1 時溢位
+	}
+	if width == 0 {
+		width = 1
+	}
+
+	var bounds []int64
+	for i := 1; i < parts; i++ {
+		bound := int64(uint64(minKey) + width*uint64(i))
+		if bound > maxKey || bound <= minKey {
+			break
+		}
+		bounds = append(bounds, bound)
+	}
+	return bounds
+}
 
-	return results, rows.Err()
+// GetMinActiveRowVersion returns MIN_ACTIVE_ROWVERSION() of the current database.
+// Every row with a lower rowversion belongs to a committed transaction, so it is a safe
+// exclusive upper bound for reading changed rows.
+func (c *MSSQLConnection) GetMinActiveRowVersion(ctx context.Context) ([]byte, error) {
+	var version []byte
+	err := c.db.QueryRowContext(ctx, "SELECT CONVERT(binary(8), MIN_ACTIVE_ROWVERSION())").Scan(&version)
+	return version, err
 }
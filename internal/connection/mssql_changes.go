@@ -0,0 +1,269 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"adaru-db-tool/internal/schema/converter"
+	"adaru-db-tool/internal/types"
+)
+
+// SourceChange is one row change read from Change Tracking or CDC
+type SourceChange struct {
+	Order  []byte        // 提交順序鍵（CT：版本號；CDC：start_lsn + seqval），可跨表格以位元組比較排序
+	Delete bool          // true 表示刪除，只有 Key 有值
+	Key    []interface{} // 主鍵值，依主鍵順序
+	Values []interface{} // 完整資料列，依欄位順序（刪除時為 nil）
+}
+
+// ChangeTrackingEnabled reports whether Change Tracking is enabled for a table
+func (c *MSSQLConnection) ChangeTrackingEnabled(ctx context.Context, schema, tableName string) (bool, error) {
+	var count int
+	err := c.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM sys.change_tracking_tables
+		WHERE object_id = OBJECT_ID(@name)
+	`, sql.Named("name", fmt.Sprintf("[%s].[%s]", schema, tableName))).Scan(&count)
+	return count > 0, err
+}
+
+// CurrentChangeTrackingVersion returns CHANGE_TRACKING_CURRENT_VERSION() of the current database
+func (c *MSSQLConnection) CurrentChangeTrackingVersion(ctx context.Context) (int64, error) {
+	var version sql.NullInt64
+	err := c.db.QueryRowContext(ctx, "SELECT CHANGE_TRACKING_CURRENT_VERSION()").Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	if !version.Valid {
+		return 0, fmt.Errorf("change tracking is not enabled on the database")
+	}
+	return version.Int64, nil
+}
+
+// MinValidChangeTrackingVersion returns the oldest version whose changes are still retained for a table
+func (c *MSSQLConnection) MinValidChangeTrackingVersion(ctx context.Context, schema, tableName string) (int64, error) {
+	var version sql.NullInt64
+	err := c.db.QueryRowContext(ctx, "SELECT CHANGE_TRACKING_MIN_VALID_VERSION(OBJECT_ID(@name))",
+		sql.Named("name", fmt.Sprintf("[%s].[%s]", schema, tableName))).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	if !version.Valid {
+		return 0, fmt.Errorf("change tracking is not enabled on %s.%s", schema, tableName)
+	}
+	return version.Int64, nil
+}
+
+// ChangeTrackingLag returns how long ago the oldest change after version from was committed, by the source's clock.
+// It is zero when no change has been committed since. Commit times cover every tracked table of the database.
+func (c *MSSQLConnection) ChangeTrackingLag(ctx context.Context, from int64) (time.Duration, error) {
+	// sys.dm_tran_commit_table 的 commit_time 為 UTC
+	var oldest sql.NullTime
+	var now time.Time
+	err := c.db.QueryRowContext(ctx, `
+		SELECT MIN(commit_time), SYSUTCDATETIME() FROM sys.dm_tran_commit_table
+		WHERE commit_ts > @from
+	`, sql.Named("from", from)).Scan(&oldest, &now)
+	if err != nil || !oldest.Valid {
+		return 0, err
+	}
+	return lagSince(oldest.Time, now), nil
+}
+
+// GetTrackedChanges reads the net changes of a table with a version in (from, to], in version order.
+// Change Tracking only records keys, so inserted and updated rows are joined back to the table;
+// a row that no longer exists is reported as a delete.
+func (c *MSSQLConnection) GetTrackedChanges(ctx context.Context, schema, tableName string, columns []types.ColumnInfo, keyColumns []string, from, to int64) ([]SourceChange, error) {
+	selects := []string{"ct.SYS_CHANGE_VERSION", "CASE WHEN t.[" + keyColumns[0] + "] IS NULL THEN 0 ELSE 1 END"}
+	byName := make(map[string]types.ColumnInfo, len(columns))
+	for _, col := range columns {
+		byName[col.Name] = col
+	}
+	joins := make([]string, len(keyColumns))
+	for i, key := range keyColumns {
+		// 主鍵以與資料欄位相同的形式讀取（如舊字碼頁字串的原始位元組），由同一套轉換解碼
+		keyCol, ok := byName[key]
+		if !ok {
+			keyCol = types.ColumnInfo{Name: key}
+		}
+		selects = append(selects, converter.SourceExpression(keyCol, "ct."))
+		joins[i] = fmt.Sprintf("t.[%s] = ct.[%s]", key, key)
+	}
+	for _, col := range columns {
+		selects = append(selects, converter.SourceExpression(col, "t."))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM CHANGETABLE(CHANGES [%s].[%s], @from) AS ct
+		LEFT JOIN [%s].[%s] AS t ON %s
+		WHERE ct.SYS_CHANGE_VERSION <= @to
+		ORDER BY ct.SYS_CHANGE_VERSION
+	`, strings.Join(selects, ", "), schema, tableName, schema, tableName, strings.Join(joins, " AND "))
+
+	rows, err := c.db.QueryContext(ctx, query, sql.Named("from", from), sql.Named("to", to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []SourceChange
+	for rows.Next() {
+		var version int64
+		var exists int
+		key := make([]interface{}, len(keyColumns))
+		values := make([]interface{}, len(columns))
+		dest := []interface{}{&version, &exists}
+		for i := range key {
+			dest = append(dest, &key[i])
+		}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		order := make([]byte, 8)
+		binary.BigEndian.PutUint64(order, uint64(version))
+		change := SourceChange{Order: order, Key: key}
+		// 資料列已不存在（之後被刪除）時視為刪除，後續版本會再記錄刪除
+		if exists == 0 {
+			change.Delete = true
+		} else {
+			change.Values = values
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// CDCCaptureInstance returns the CDC capture instance of a table, or "" when the table is not captured
+func (c *MSSQLConnection) CDCCaptureInstance(ctx context.Context, schema, tableName string) (string, error) {
+	var enabled bool
+	if err := c.db.QueryRowContext(ctx, "SELECT is_cdc_enabled FROM sys.databases WHERE name = DB_NAME()").Scan(&enabled); err != nil {
+		return "", err
+	}
+	if !enabled {
+		return "", nil
+	}
+
+	// 同一表格可能有兩個 capture instance（變更結構期間），取最新建立的
+	var instance string
+	err := c.db.QueryRowContext(ctx, `
+		SELECT TOP 1 capture_instance FROM cdc.change_tables
+		WHERE source_object_id = OBJECT_ID(@name)
+		ORDER BY create_date DESC
+	`, sql.Named("name", fmt.Sprintf("[%s].[%s]", schema, tableName))).Scan(&instance)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return instance, err
+}
+
+// CDCMaxLSN returns sys.fn_cdc_get_max_lsn(), the highest LSN available to CDC queries
+func (c *MSSQLConnection) CDCMaxLSN(ctx context.Context) ([]byte, error) {
+	var lsn []byte
+	err := c.db.QueryRowContext(ctx, "SELECT sys.fn_cdc_get_max_lsn()").Scan(&lsn)
+	if err == nil && lsn == nil {
+		err = fmt.Errorf("CDC has not captured any changes yet")
+	}
+	return lsn, err
+}
+
+// CDCChangesRetained reports whether every change of a capture instance after the from LSN is still retained
+func (c *MSSQLConnection) CDCChangesRetained(ctx context.Context, instance string, from []byte) (bool, error) {
+	var retained int
+	err := c.db.QueryRowContext(ctx, `
+		SELECT CASE WHEN sys.fn_cdc_increment_lsn(@from) >= sys.fn_cdc_get_min_lsn(@instance) THEN 1 ELSE 0 END
+	`, sql.Named("from", from), sql.Named("instance", instance)).Scan(&retained)
+	return retained == 1, err
+}
+
+// CDCLag returns how long ago the oldest change of a capture instance after the from LSN was committed,
+// by the source's clock. It is zero when no change has been captured since.
+func (c *MSSQLConnection) CDCLag(ctx context.Context, instance string, from []byte) (time.Duration, error) {
+	// cdc.lsn_time_mapping 的 tran_end_time 為伺服器當地時間
+	var oldest sql.NullTime
+	var now time.Time
+	query := fmt.Sprintf(`
+		SELECT sys.fn_cdc_map_lsn_to_time(MIN(__$start_lsn)), SYSDATETIME() FROM cdc.[%s_CT]
+		WHERE __$start_lsn > @from
+	`, instance)
+	if err := c.db.QueryRowContext(ctx, query, sql.Named("from", from)).Scan(&oldest, &now); err != nil || !oldest.Valid {
+		return 0, err
+	}
+	return lagSince(oldest.Time, now), nil
+}
+
+// lagSince returns the time from a commit to now, both read from the source; clock skew never makes it negative
+func lagSince(commit, now time.Time) time.Duration {
+	if lag := now.Sub(commit); lag > 0 {
+		return lag
+	}
+	return 0
+}
+
+// GetCDCChanges reads the changes of a capture instance with an LSN in (from, to], in commit order.
+// Updates are read as their after-image only. from must be lower than to.
+func (c *MSSQLConnection) GetCDCChanges(ctx context.Context, instance string, columns []types.ColumnInfo, keyColumns []string, from, to []byte) ([]SourceChange, error) {
+	selects := []string{"__$start_lsn", "__$seqval", "__$operation"}
+	for _, col := range columns {
+		selects = append(selects, converter.SourceExpression(col, ""))
+	}
+	keyIndexes := make([]int, len(keyColumns))
+	for i, key := range keyColumns {
+		keyIndexes[i] = -1
+		for j, col := range columns {
+			if col.Name == key {
+				keyIndexes[i] = j
+			}
+		}
+		if keyIndexes[i] < 0 {
+			return nil, fmt.Errorf("key column %s is not captured by %s", key, instance)
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM cdc.[fn_cdc_get_all_changes_%s](sys.fn_cdc_increment_lsn(@from), @to, N'all')
+		ORDER BY __$start_lsn, __$seqval
+	`, strings.Join(selects, ", "), instance)
+
+	rows, err := c.db.QueryContext(ctx, query, sql.Named("from", from), sql.Named("to", to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []SourceChange
+	for rows.Next() {
+		var startLSN, seqval []byte
+		var operation int
+		values := make([]interface{}, len(columns))
+		dest := []interface{}{&startLSN, &seqval, &operation}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		key := make([]interface{}, len(keyIndexes))
+		for i, idx := range keyIndexes {
+			key[i] = values[idx]
+		}
+		change := SourceChange{Order: append(startLSN, seqval...), Key: key}
+		// __$operation：1 刪除、2 新增、4 更新後
+		if operation == 1 {
+			change.Delete = true
+		} else {
+			change.Values = values
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
@@ -0,0 +1,118 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// DataFile is a data file of a database, as listed in sys.master_files
+type DataFile struct {
+	LogicalName  string
+	PhysicalName string
+}
+
+// SnapshotIsolationAllowed reports whether ALLOW_SNAPSHOT_ISOLATION is ON for the current database
+func (c *MSSQLConnection) SnapshotIsolationAllowed(ctx context.Context) (bool, error) {
+	var state int
+	err := c.db.QueryRowContext(ctx, "SELECT snapshot_isolation_state FROM sys.databases WHERE name = @db",
+		sql.Named("db", c.databaseName)).Scan(&state)
+	return state == 1, err
+}
+
+// BeginSnapshot starts a SNAPSHOT isolation transaction on a dedicated session.
+// All later batch reads of this connection go through it and see the database as of its first read;
+// the transaction is rolled back when the connection is closed.
+func (c *MSSQLConnection) BeginSnapshot(ctx context.Context) error {
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	// USE 只作用於執行它的 session，交易所在的 session 需要自己切換資料庫
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", c.databaseName)); err != nil {
+		conn.Close()
+		return err
+	}
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSnapshot})
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	c.snapshotConn = conn
+	c.snapshotTx = tx
+	return nil
+}
+
+// reader returns the SNAPSHOT transaction when one was started, or the connection pool
+func (c *MSSQLConnection) reader() interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+} {
+	if c.snapshotTx != nil {
+		return c.snapshotTx
+	}
+	return c.db
+}
+
+// dataFiles returns the data files of the current database
+func (c *MSSQLConnection) dataFiles(ctx context.Context) ([]DataFile, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT name, physical_name FROM sys.master_files
+		WHERE database_id = DB_ID(@db) AND type = 0
+		ORDER BY file_id
+	`, sql.Named("db", c.databaseName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []DataFile
+	for rows.Next() {
+		var f DataFile
+		if err := rows.Scan(&f.LogicalName, &f.PhysicalName); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+// CreateDatabaseSnapshot creates a read-only database snapshot of the current database
+func (c *MSSQLConnection) CreateDatabaseSnapshot(ctx context.Context, name string) error {
+	files, err := c.dataFiles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list data files: %w", err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no data files found for database %s", c.databaseName)
+	}
+	_, err = c.db.ExecContext(ctx, DatabaseSnapshotSQL(name, c.databaseName, files))
+	return err
+}
+
+// DatabaseSnapshotSQL returns the statement creating a database snapshot,
+// with one sparse file per data file placed next to the original
+func DatabaseSnapshotSQL(name, database string, files []DataFile) string {
+	specs := make([]string, len(files))
+	for i, f := range files {
+		// physical_name 可能是 Windows 或 Linux 路徑
+		dir := f.PhysicalName[:strings.LastIndexAny(f.PhysicalName, `\/`)+1]
+		path := dir + name + "_" + f.LogicalName + ".ss"
+		specs[i] = fmt.Sprintf("(NAME = [%s], FILENAME = '%s')", f.LogicalName, strings.ReplaceAll(path, "'", "''"))
+	}
+	return fmt.Sprintf("CREATE DATABASE [%s] ON %s AS SNAPSHOT OF [%s]", name, strings.Join(specs, ", "), database)
+}
+
+// DropDatabaseSnapshot drops a database snapshot. It refuses to drop a database that is not a snapshot.
+func (c *MSSQLConnection) DropDatabaseSnapshot(ctx context.Context, name string) error {
+	var source sql.NullInt64
+	if err := c.db.QueryRowContext(ctx, "SELECT source_database_id FROM sys.databases WHERE name = @name",
+		sql.Named("name", name)).Scan(&source); err != nil {
+		return err
+	}
+	if !source.Valid {
+		return fmt.Errorf("database %s is not a database snapshot", name)
+	}
+	_, err := c.db.ExecContext(ctx, fmt.Sprintf("DROP DATABASE [%s]", name))
+	return err
+}
@@ -0,0 +1,156 @@
+package connection
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"adaru-db-tool/internal/types"
+
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+func TestKeysetPredicate(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		want string
+	}{
+		{"single key", []string{"Id"}, "(([Id] > @k0))"},
+		{"composite key", []string{"OrderId", "LineNo"}, "(([OrderId] > @k0) OR ([OrderId] = @k0 AND [LineNo] > @k1))"},
+		{"three keys", []string{"A", "B", "C"}, "(([A] > @k0) OR ([A] = @k0 AND [B] > @k1) OR ([A] = @k0 AND [B] = @k1 AND [C] > @k2))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := KeysetPredicate(tt.keys)
+			if got != tt.want {
+				t.Errorf("KeysetPredicate(%v) = %q, want %q", tt.keys, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchQuery_BuildKeyParams(t *testing.T) {
+	// datetime 以 1/300 秒儲存，.997 讀出時已四捨五入為毫秒
+	created := time.Date(2024, 3, 1, 23, 59, 59, 997000000, time.UTC)
+	tests := []struct {
+		name string
+		col  types.ColumnInfo
+		key  interface{}
+		want interface{}
+	}{
+		{"decimal read as text", types.ColumnInfo{Name: "Amount", DataType: "decimal", Precision: 18, Scale: 2}, []byte("1234.50"), "1234.50"},
+		{"money read as text", types.ColumnInfo{Name: "Price", DataType: "money"}, []byte("9.9900"), "9.9900"},
+		{"varchar", types.ColumnInfo{Name: "Code", DataType: "varchar"}, "A01", mssql.VarChar("A01")},
+		{"varchar in code page", types.ColumnInfo{Name: "Code", DataType: "varchar"}, []byte{0xa4, 0xa4}, []byte{0xa4, 0xa4}},
+		{"int", types.ColumnInfo{Name: "Id", DataType: "int"}, int64(42), int64(42)},
+		{"datetime", types.ColumnInfo{Name: "CreatedAt", DataType: "datetime"}, created, mssql.DateTime1(created)},
+		{"datetime2", types.ColumnInfo{Name: "CreatedAt", DataType: "datetime2"}, created, created},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := BatchQuery{
+				Schema:     "dbo",
+				Table:      "Orders",
+				Columns:    []string{"[" + tt.col.Name + "]"},
+				KeyColumns: []types.ColumnInfo{tt.col},
+				LastKey:    []interface{}{tt.key},
+				Limit:      1000,
+			}
+			query, args := q.build()
+			if !strings.Contains(query, "((["+tt.col.Name+"] > @k0))") {
+				t.Errorf("build() query = %s", query)
+			}
+			if len(args) != 1 {
+				t.Fatalf("build() args = %v", args)
+			}
+			arg := args[0].(sql.NamedArg)
+			if arg.Name != "k0" || !reflect.DeepEqual(arg.Value, tt.want) {
+				t.Errorf("build() arg = %s=%#v, want k0=%#v", arg.Name, arg.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitHistogram(t *testing.T) {
+	steps := []HistogramStep{
+		{HighKey: 100, Rows: 100},
+		{HighKey: 200, Rows: 100},
+		{HighKey: 300, Rows: 100},
+		{HighKey: 400, Rows: 100},
+	}
+
+	tests := []struct {
+		name  string
+		parts int
+		want  []int64
+	}{
+		{"two parts", 2, []int64{200}},
+		{"four parts", 4, []int64{100, 200, 300}},
+		{"more parts than steps", 8, []int64{100, 200, 300}},
+		{"one part", 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitHistogram(steps, tt.parts)
+			if !equalInt64s(got, tt.want) {
+				t.Errorf("SplitHistogram(parts=%d) = %v, want %v", tt.parts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitEven(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int64
+		parts    int
+		want     []int64
+	}{
+		{"even", 1, 100, 4, []int64{26, 51, 76}},
+		{"narrow range", 1, 2, 4, []int64{2}},
+		{"single key", 5, 5, 4, nil},
+		// bigint 全範圍不可溢位
+		{"full bigint range", -9223372036854775808, 9223372036854775807, 2, []int64{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitEven(tt.min, tt.max, tt.parts)
+			if !equalInt64s(got, tt.want) {
+				t.Errorf("SplitEven(%d, %d, %d) = %v, want %v", tt.min, tt.max, tt.parts, got, tt.want)
+			}
+		})
+	}
+}
+
+func equalInt64s(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDatabaseSnapshotSQL(t *testing.T) {
+	files := []DataFile{
+		{LogicalName: "Sales", PhysicalName: `D:\Data\Sales.mdf`},
+		{LogicalName: "Sales_Archive", PhysicalName: "/var/opt/mssql/data/Sales_Archive.ndf"},
+	}
+	want := `CREATE DATABASE [Sales_snapshot_1] ON ` +
+		`(NAME = [Sales], FILENAME = 'D:\Data\Sales_snapshot_1_Sales.ss'), ` +
+		`(NAME = [Sales_Archive], FILENAME = '/var/opt/mssql/data/Sales_snapshot_1_Sales_Archive.ss') ` +
+		`AS SNAPSHOT OF [Sales]`
+	if got := DatabaseSnapshotSQL("Sales_snapshot_1", "Sales", files); got != want {
+		t.Errorf("DatabaseSnapshotSQL() =\n%s\nwant\n%s", got, want)
+	}
+}
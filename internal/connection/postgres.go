@@ -3,7 +3,10 @@ package connection
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"adaru-db-tool/internal/types"
 
@@ -15,6 +18,9 @@ import (
 type PostgresConnection struct {
 	pool       *pgxpool.Pool
 	connString string
+
+	mu       sync.Mutex
+	settings map[string]string // 每個新建立的 session 都會套用的設定參數
 }
 
 // NewPostgresConnection creates a new PostgreSQL connection
@@ -26,7 +32,13 @@ func NewPostgresConnection(connString string) *PostgresConnection {
 
 // Connect establishes a connection pool to the database
 func (c *PostgresConnection) Connect(ctx context.Context) error {
-	pool, err := pgxpool.New(ctx, c.connString)
+	config, err := pgxpool.ParseConfig(c.connString)
+	if err != nil {
+		return fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	config.AfterConnect = c.applySettings
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return fmt.Errorf("failed to create connection pool: %w", err)
 	}
@@ -47,6 +59,93 @@ func (c *PostgresConnection) Close() {
 	}
 }
 
+// applySettings sets the session parameters of the connection on a new session
+func (c *PostgresConnection) applySettings(ctx context.Context, conn *pgx.Conn) error {
+	c.mu.Lock()
+	settings := make(map[string]string, len(c.settings))
+	for name, value := range c.settings {
+		settings[name] = value
+	}
+	c.mu.Unlock()
+
+	for name, value := range settings {
+		if _, err := conn.Exec(ctx, "SELECT set_config($1, $2, false)", name, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// SetSessionSettings changes the parameters set on every session of the pool; an empty value removes a parameter.
+// Open sessions are replaced so the change applies to all of them. When a parameter is rejected
+// (e.g. for lack of privileges) the previous settings are restored and the error is returned.
+func (c *PostgresConnection) SetSessionSettings(ctx context.Context, settings map[string]string) error {
+	c.mu.Lock()
+	previous := c.settings
+	next := make(map[string]string, len(previous)+len(settings))
+	for name, value := range previous {
+		next[name] = value
+	}
+	for name, value := range settings {
+		if value == "" {
+			delete(next, name)
+		} else {
+			next[name] = value
+		}
+	}
+	c.settings = next
+	c.mu.Unlock()
+
+	if c.pool == nil {
+		return nil
+	}
+	c.pool.Reset()
+	// 建立新 session 時套用設定，設定被拒絕即在此回報
+	if _, err := c.pool.Exec(ctx, "SELECT 1"); err != nil {
+		c.mu.Lock()
+		c.settings = previous
+		c.mu.Unlock()
+		c.pool.Reset()
+		return err
+	}
+	return nil
+}
+
+// BulkLoadProfile tunes the sessions of a connection for an initial bulk load.
+// The parameters only live in the sessions of the pool: nothing is changed in the server configuration,
+// and they are gone once the pool is closed or the profile is reset.
+type BulkLoadProfile struct {
+	SynchronousCommitOff bool // 提交時不等待 WAL 寫入磁碟；伺服器當機可能遺失最後的提交，但不會損毀資料
+	MaintenanceWorkMemMB int  // 建立索引可使用的記憶體（0 表示不變更）
+}
+
+// Settings returns the session parameters of the profile
+func (p BulkLoadProfile) Settings() map[string]string {
+	settings := make(map[string]string)
+	if p.SynchronousCommitOff {
+		settings["synchronous_commit"] = "off"
+	}
+	if p.MaintenanceWorkMemMB > 0 {
+		settings["maintenance_work_mem"] = fmt.Sprintf("%dMB", p.MaintenanceWorkMemMB)
+	}
+	return settings
+}
+
+// String describes the parameters of the profile as "name=value" pairs sorted by name
+func (p BulkLoadProfile) String() string {
+	var pairs []string
+	for name, value := range p.Settings() {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+// ApplyBulkLoadProfile sets the parameters of a bulk load profile on every session of the pool
+func (c *PostgresConnection) ApplyBulkLoadProfile(ctx context.Context, profile BulkLoadProfile) error {
+	return c.SetSessionSettings(ctx, profile.Settings())
+}
+
 // Pool returns the underlying connection pool
 func (c *PostgresConnection) Pool() *pgxpool.Pool {
 	return c.pool
@@ -104,10 +203,15 @@ func (c *PostgresConnection) Test(ctx context.Context) (*types.ConnectionTestRes
 
 // CreateSchema creates a schema if it doesn't exist
 func (c *PostgresConnection) CreateSchema(ctx context.Context, schemaName string) error {
-	_, err := c.pool.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", pgx.Identifier{schemaName}.Sanitize()))
+	_, err := c.pool.Exec(ctx, CreateSchemaSQL(schemaName))
 	return err
 }
 
+// CreateSchemaSQL returns the statement executed by CreateSchema
+func CreateSchemaSQL(schemaName string) string {
+	return fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", pgx.Identifier{schemaName}.Sanitize())
+}
+
 // ExecuteDDL executes a DDL statement
 func (c *PostgresConnection) ExecuteDDL(ctx context.Context, ddl string) error {
 	_, err := c.pool.Exec(ctx, ddl)
@@ -158,6 +262,226 @@ func (c *PostgresConnection) CopyFrom(ctx context.Context, schema, tableName str
 	return count, err
 }
 
+// CopyFromSource 使用 COPY 協議寫入由 src 逐筆提供的資料
+// 與 CopyFrom 不同，資料不需先整批載入記憶體，src 可以一邊讀取來源一邊提供給 COPY
+func (c *PostgresConnection) CopyFromSource(ctx context.Context, schema, tableName string, columns []string, src pgx.CopyFromSource) (int64, error) {
+	return c.pool.CopyFrom(ctx, pgx.Identifier{schema, tableName}, columns, src)
+}
+
+// CopyUpsert streams rows into a temporary table with COPY and merges them into the target
+// with INSERT ... ON CONFLICT (keyColumns) DO UPDATE, all in one transaction.
+// It returns the number of rows inserted or updated.
+func (c *PostgresConnection) CopyUpsert(ctx context.Context, schema, tableName string, columns, keyColumns []string, src pgx.CopyFromSource) (int64, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	target := fmt.Sprintf("%s.%s", pgx.Identifier{schema}.Sanitize(), pgx.Identifier{tableName}.Sanitize())
+	cols := make([]string, len(columns))
+	for i, col := range columns {
+		cols[i] = pgx.Identifier{col}.Sanitize()
+	}
+	colList := strings.Join(cols, ", ")
+
+	// 暫存表只保留要寫入的欄位，交易結束時自動刪除
+	staging := "_upsert_staging"
+	if _, err := tx.Exec(ctx, fmt.Sprintf("CREATE TEMP TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA",
+		pgx.Identifier{staging}.Sanitize(), colList, target)); err != nil {
+		return 0, err
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{staging}, columns, src); err != nil {
+		return 0, err
+	}
+
+	tag, err := tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s %s",
+		target, colList, colList, pgx.Identifier{staging}.Sanitize(), onConflictUpdate(columns, keyColumns)))
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// RowChange is one row change to apply to a target table
+type RowChange struct {
+	Schema     string
+	Table      string
+	Columns    []string
+	KeyColumns []string
+	Key        []interface{} // 主鍵值，依 KeyColumns 順序
+	Values     []interface{} // 完整資料列，依 Columns 順序；nil 表示刪除
+}
+
+// applyBatchSize bounds the statements sent in one round trip by ApplyChanges
+const applyBatchSize = 1000
+
+// ApplyChanges applies row changes in the given order within one transaction.
+// Inserts and updates are applied as upserts and deletes by key, so applying a change twice is harmless.
+func (c *PostgresConnection) ApplyChanges(ctx context.Context, changes []RowChange) error {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// 同一表格的 SQL 只組一次
+	upserts := make(map[string]string)
+	deletes := make(map[string]string)
+
+	batch := &pgx.Batch{}
+	flush := func() error {
+		if batch.Len() == 0 {
+			return nil
+		}
+		err := tx.SendBatch(ctx, batch).Close()
+		batch = &pgx.Batch{}
+		return err
+	}
+
+	for _, ch := range changes {
+		name := ch.Schema + "." + ch.Table
+		if ch.Values == nil {
+			query, ok := deletes[name]
+			if !ok {
+				query = buildDeleteByKey(ch.Schema, ch.Table, ch.KeyColumns)
+				deletes[name] = query
+			}
+			batch.Queue(query, ch.Key...)
+		} else {
+			query, ok := upserts[name]
+			if !ok {
+				query = buildUpsertRow(ch.Schema, ch.Table, ch.Columns, ch.KeyColumns)
+				upserts[name] = query
+			}
+			batch.Queue(query, ch.Values...)
+		}
+
+		if batch.Len() >= applyBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// buildUpsertRow returns INSERT ... ON CONFLICT DO UPDATE for one row with $1..$n in column order
+func buildUpsertRow(schema, tableName string, columns, keyColumns []string) string {
+	cols := make([]string, len(columns))
+	params := make([]string, len(columns))
+	for i, col := range columns {
+		cols[i] = pgx.Identifier{col}.Sanitize()
+		params[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	return fmt.Sprintf("INSERT INTO %s.%s (%s) VALUES (%s) %s",
+		pgx.Identifier{schema}.Sanitize(), pgx.Identifier{tableName}.Sanitize(),
+		strings.Join(cols, ", "), strings.Join(params, ", "), onConflictUpdate(columns, keyColumns))
+}
+
+// onConflictUpdate returns the ON CONFLICT clause that overwrites every non-key column
+func onConflictUpdate(columns, keyColumns []string) string {
+	isKey := make(map[string]bool, len(keyColumns))
+	keys := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		isKey[col] = true
+		keys[i] = pgx.Identifier{col}.Sanitize()
+	}
+
+	var sets []string
+	for _, col := range columns {
+		if !isKey[col] {
+			ident := pgx.Identifier{col}.Sanitize()
+			sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", ident, ident))
+		}
+	}
+	if len(sets) == 0 {
+		return fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", strings.Join(keys, ", "))
+	}
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(keys, ", "), strings.Join(sets, ", "))
+}
+
+// buildDeleteByKey returns DELETE ... WHERE key = $1 AND ... in key column order
+func buildDeleteByKey(schema, tableName string, keyColumns []string) string {
+	conditions := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		conditions[i] = fmt.Sprintf("%s = $%d", pgx.Identifier{col}.Sanitize(), i+1)
+	}
+	return fmt.Sprintf("DELETE FROM %s.%s WHERE %s",
+		pgx.Identifier{schema}.Sanitize(), pgx.Identifier{tableName}.Sanitize(),
+		strings.Join(conditions, " AND "))
+}
+
+// ValidateConstraint validates a constraint added as NOT VALID, scanning the existing rows
+func (c *PostgresConnection) ValidateConstraint(ctx context.Context, schema, tableName, constraintName string) error {
+	_, err := c.pool.Exec(ctx, ValidateConstraintSQL(schema, tableName, constraintName))
+	return err
+}
+
+// ValidateConstraintSQL returns the statement executed by ValidateConstraint
+func ValidateConstraintSQL(schema, tableName, constraintName string) string {
+	return fmt.Sprintf("ALTER TABLE %s.%s VALIDATE CONSTRAINT %s",
+		pgx.Identifier{schema}.Sanitize(), pgx.Identifier{tableName}.Sanitize(), pgx.Identifier{constraintName}.Sanitize())
+}
+
+// FindOrphans counts the rows of a table whose foreign key has no match in the referenced table,
+// and returns up to limit distinct offending key values
+func (c *PostgresConnection) FindOrphans(ctx context.Context, schema, tableName string, columns []string, refSchema, refTable string, refColumns []string, limit int) (int64, [][]interface{}, error) {
+	countSQL, sampleSQL := buildOrphanQueries(schema, tableName, columns, refSchema, refTable, refColumns)
+
+	var count int64
+	if err := c.pool.QueryRow(ctx, countSQL).Scan(&count); err != nil {
+		return 0, nil, err
+	}
+	if count == 0 {
+		return 0, nil, nil
+	}
+
+	rows, err := c.pool.Query(ctx, sampleSQL, limit)
+	if err != nil {
+		return count, nil, err
+	}
+	defer rows.Close()
+
+	var samples [][]interface{}
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return count, samples, err
+		}
+		samples = append(samples, values)
+	}
+	return count, samples, rows.Err()
+}
+
+// buildOrphanQueries returns the queries counting orphaned rows and sampling their distinct keys ($1 = limit).
+// Like the default MATCH SIMPLE, a key with any NULL column is never an orphan.
+func buildOrphanQueries(schema, tableName string, columns []string, refSchema, refTable string, refColumns []string) (string, string) {
+	keys := make([]string, len(columns))
+	notNull := make([]string, len(columns))
+	matches := make([]string, len(columns))
+	for i, col := range columns {
+		keys[i] = "c." + pgx.Identifier{col}.Sanitize()
+		notNull[i] = keys[i] + " IS NOT NULL"
+		matches[i] = fmt.Sprintf("p.%s = %s", pgx.Identifier{refColumns[i]}.Sanitize(), keys[i])
+	}
+
+	from := fmt.Sprintf("FROM %s.%s c WHERE %s AND NOT EXISTS (SELECT 1 FROM %s.%s p WHERE %s)",
+		pgx.Identifier{schema}.Sanitize(), pgx.Identifier{tableName}.Sanitize(),
+		strings.Join(notNull, " AND "),
+		pgx.Identifier{refSchema}.Sanitize(), pgx.Identifier{refTable}.Sanitize(),
+		strings.Join(matches, " AND "))
+	return "SELECT COUNT(*) " + from,
+		fmt.Sprintf("SELECT DISTINCT %s %s ORDER BY %s LIMIT $1", strings.Join(keys, ", "), from, strings.Join(keys, ", "))
+}
+
 // DisableTriggers disables triggers on a table
 func (c *PostgresConnection) DisableTriggers(ctx context.Context, schema, tableName string) error {
 	query := fmt.Sprintf("ALTER TABLE %s.%s DISABLE TRIGGER ALL",
@@ -176,35 +500,78 @@ func (c *PostgresConnection) EnableTriggers(ctx context.Context, schema, tableNa
 	return err
 }
 
-// DisableForeignKeyChecks disables foreign key checks for a session
+// DisableForeignKeyChecks disables foreign key checks (and user triggers) on every session of the pool
 func (c *PostgresConnection) DisableForeignKeyChecks(ctx context.Context) error {
-	_, err := c.pool.Exec(ctx, "SET session_replication_role = 'replica'")
-	return err
+	return c.SetSessionSettings(ctx, map[string]string{"session_replication_role": "replica"})
 }
 
-// EnableForeignKeyChecks enables foreign key checks for a session
+// EnableForeignKeyChecks restores foreign key checks on every session of the pool
 func (c *PostgresConnection) EnableForeignKeyChecks(ctx context.Context) error {
-	_, err := c.pool.Exec(ctx, "SET session_replication_role = 'origin'")
+	return c.SetSessionSettings(ctx, map[string]string{"session_replication_role": ""})
+}
+
+// SetLogged switches an UNLOGGED table to LOGGED, which writes the whole table to the WAL
+func (c *PostgresConnection) SetLogged(ctx context.Context, schema, tableName string) error {
+	_, err := c.pool.Exec(ctx, SetLoggedSQL(schema, tableName))
 	return err
 }
 
+// SetLoggedSQL returns the statement executed by SetLogged
+func SetLoggedSQL(schema, tableName string) string {
+	return fmt.Sprintf("ALTER TABLE %s.%s SET LOGGED",
+		pgx.Identifier{schema}.Sanitize(), pgx.Identifier{tableName}.Sanitize())
+}
+
+// IsUnlogged reports whether a table is UNLOGGED
+func (c *PostgresConnection) IsUnlogged(ctx context.Context, schema, tableName string) (bool, error) {
+	var persistence string
+	err := c.pool.QueryRow(ctx, `
+		SELECT c.relpersistence::text FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2
+	`, schema, tableName).Scan(&persistence)
+	return persistence == "u", err
+}
+
+// AnalyzeTableSQL returns the statement collecting planner statistics for a table
+func AnalyzeTableSQL(schema, tableName string) string {
+	return fmt.Sprintf("ANALYZE %s.%s", pgx.Identifier{schema}.Sanitize(), pgx.Identifier{tableName}.Sanitize())
+}
+
+// VacuumTableSQL returns the statement vacuuming a table, collecting planner statistics too when analyze is set
+func VacuumTableSQL(schema, tableName string, analyze bool) string {
+	options := ""
+	if analyze {
+		options = "(ANALYZE) "
+	}
+	return fmt.Sprintf("VACUUM %s%s.%s", options, pgx.Identifier{schema}.Sanitize(), pgx.Identifier{tableName}.Sanitize())
+}
+
+// ClusterTableSQL returns the statement rewriting a table in the order of an index.
+// The index is remembered, so a later plain CLUSTER of the table reuses it.
+func ClusterTableSQL(schema, tableName, indexName string) string {
+	return fmt.Sprintf("CLUSTER %s.%s USING %s",
+		pgx.Identifier{schema}.Sanitize(), pgx.Identifier{tableName}.Sanitize(), pgx.Identifier{indexName}.Sanitize())
+}
+
 // SyncSequence synchronizes a sequence with the max value in the table
 func (c *PostgresConnection) SyncSequence(ctx context.Context, schema, tableName, columnName string) error {
+	_, err := c.pool.Exec(ctx, SyncSequenceSQL(schema, tableName, columnName))
+	return err
+}
+
+// SyncSequenceSQL returns the statement executed by SyncSequence
+func SyncSequenceSQL(schema, tableName, columnName string) string {
 	// pg_get_serial_sequence needs quoted identifiers to preserve case
 	qualifiedTable := fmt.Sprintf("%s.%s",
 		pgx.Identifier{schema}.Sanitize(),
 		pgx.Identifier{tableName}.Sanitize())
 
-	query := fmt.Sprintf(`
-		SELECT setval(pg_get_serial_sequence('%s', '%s'),
-			COALESCE((SELECT MAX(%s) FROM %s.%s), 1))
-	`, qualifiedTable, columnName,
+	return fmt.Sprintf(`SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE((SELECT MAX(%s) FROM %s.%s), 1))`,
+		qualifiedTable, columnName,
 		pgx.Identifier{columnName}.Sanitize(),
 		pgx.Identifier{schema}.Sanitize(),
 		pgx.Identifier{tableName}.Sanitize())
-
-	_, err := c.pool.Exec(ctx, query)
-	return err
 }
 
 // GetRowCount gets the row count of a table
@@ -280,13 +647,221 @@ func (c *PostgresConnection) GetSampleRows(ctx context.Context, schema, tableNam
 
 // DropTableIfExists drops a table if it exists
 func (c *PostgresConnection) DropTableIfExists(ctx context.Context, schema, tableName string) error {
-	query := fmt.Sprintf("DROP TABLE IF EXISTS %s.%s CASCADE",
+	_, err := c.pool.Exec(ctx, DropTableSQL(schema, tableName))
+	return err
+}
+
+// DropTableSQL returns the statement executed by DropTableIfExists
+func DropTableSQL(schema, tableName string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s.%s CASCADE",
+		pgx.Identifier{schema}.Sanitize(),
+		pgx.Identifier{tableName}.Sanitize())
+}
+
+// SwapTable replaces a table with a fully loaded staging table in one transaction.
+// The previous table is dropped without CASCADE, so objects depending on it make the swap fail
+// and leave it untouched. Indexes are renamed from their staging names (indexRenames maps staging
+// to final name); the primary key and the sequences of serialColumns get the names PostgreSQL
+// would have given them on the final table.
+func (c *PostgresConnection) SwapTable(ctx context.Context, schema, stagingName, tableName string, indexRenames map[string]string, serialColumns []string) error {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qualifiedTable := fmt.Sprintf("%s.%s", pgx.Identifier{schema}.Sanitize(), pgx.Identifier{tableName}.Sanitize())
+	if _, err := tx.Exec(ctx, "DROP TABLE IF EXISTS "+qualifiedTable); err != nil {
+		return fmt.Errorf("failed to drop %s.%s (drop the views or foreign keys that depend on it first): %w", schema, tableName, err)
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf("ALTER TABLE %s.%s RENAME TO %s",
+		pgx.Identifier{schema}.Sanitize(), pgx.Identifier{stagingName}.Sanitize(), pgx.Identifier{tableName}.Sanitize())); err != nil {
+		return fmt.Errorf("failed to rename staging table: %w", err)
+	}
+
+	var pkName string
+	err = tx.QueryRow(ctx, "SELECT conname FROM pg_constraint WHERE conrelid = $1::regclass AND contype = 'p'", qualifiedTable).Scan(&pkName)
+	if err != nil && err != pgx.ErrNoRows {
+		return fmt.Errorf("failed to read primary key: %w", err)
+	}
+	if pkName != "" {
+		if _, err := tx.Exec(ctx, fmt.Sprintf("ALTER TABLE %s RENAME CONSTRAINT %s TO %s",
+			qualifiedTable, pgx.Identifier{pkName}.Sanitize(), pgx.Identifier{PrimaryKeyName(tableName)}.Sanitize())); err != nil {
+			return fmt.Errorf("failed to rename primary key: %w", err)
+		}
+	}
+
+	for from, to := range indexRenames {
+		if _, err := tx.Exec(ctx, fmt.Sprintf("ALTER INDEX %s.%s RENAME TO %s",
+			pgx.Identifier{schema}.Sanitize(), pgx.Identifier{from}.Sanitize(), pgx.Identifier{to}.Sanitize())); err != nil {
+			return fmt.Errorf("failed to rename index %s: %w", from, err)
+		}
+	}
+
+	// SERIAL 欄位的序列名稱沿用暫存表名稱，改為正式表格的名稱（舊表格的序列已隨 DROP 刪除）
+	for _, col := range serialColumns {
+		var seq *string
+		if err := tx.QueryRow(ctx, "SELECT pg_get_serial_sequence($1, $2)", qualifiedTable, col).Scan(&seq); err != nil {
+			return fmt.Errorf("failed to find sequence of %s: %w", col, err)
+		}
+		if seq == nil {
+			continue
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf("ALTER SEQUENCE %s RENAME TO %s",
+			*seq, pgx.Identifier{SequenceName(tableName, col)}.Sanitize())); err != nil {
+			return fmt.Errorf("failed to rename sequence of %s: %w", col, err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// maxIdentifierLength is the number of bytes PostgreSQL keeps of an identifier (NAMEDATALEN - 1)
+const maxIdentifierLength = 63
+
+// TruncateIdentifier shortens a name the way PostgreSQL does, without splitting a UTF-8 character
+func TruncateIdentifier(name string) string {
+	return clipIdentifier(name, maxIdentifierLength)
+}
+
+// PrimaryKeyName returns the name PostgreSQL gives to the unnamed primary key of a table:
+// the table name is shortened first so that the _pkey suffix is kept
+func PrimaryKeyName(tableName string) string {
+	return objectName(tableName, "", "pkey")
+}
+
+// SequenceName returns the name PostgreSQL gives to the sequence of a serial column:
+// the table and column names are shortened first so that the _seq suffix is kept
+func SequenceName(tableName, column string) string {
+	return objectName(tableName, column, "seq")
+}
+
+// objectName builds an implicit object name as PostgreSQL's makeObjectName does:
+// name1_name2_label, shortening the longer of the two names byte by byte until the whole fits
+func objectName(name1, name2, label string) string {
+	avail := maxIdentifierLength - len(label) - 1
+	if name2 != "" {
+		avail--
+	}
+	n1, n2 := len(name1), len(name2)
+	for n1+n2 > avail {
+		if n1 > n2 {
+			n1--
+		} else {
+			n2--
+		}
+	}
+
+	name := clipIdentifier(name1, n1)
+	if name2 != "" {
+		name += "_" + clipIdentifier(name2, n2)
+	}
+	return name + "_" + label
+}
+
+// clipIdentifier cuts a name to at most n bytes without splitting a UTF-8 character
+func clipIdentifier(name string, n int) string {
+	if len(name) <= n {
+		return name
+	}
+	for n > 0 && !utf8.RuneStart(name[n]) {
+		n--
+	}
+	return name[:n]
+}
+
+// TruncateTable removes all rows from a table
+func (c *PostgresConnection) TruncateTable(ctx context.Context, schema, tableName string) error {
+	query := fmt.Sprintf("TRUNCATE TABLE %s.%s",
 		pgx.Identifier{schema}.Sanitize(),
 		pgx.Identifier{tableName}.Sanitize())
 	_, err := c.pool.Exec(ctx, query)
 	return err
 }
 
+// DeleteRowsAfterKey deletes rows whose key is greater than lastKey.
+// When rangeColumn is set, only rows with rangeFrom <= rangeColumn < rangeTo are affected
+// (nil bounds are open). A nil lastKey deletes every row in the range.
+// 用於續傳前清除最後一個檢查點之後已提交的資料，避免重複寫入
+func (c *PostgresConnection) DeleteRowsAfterKey(ctx context.Context, schema, tableName string, keyColumns []string, lastKey []interface{}, rangeColumn string, rangeFrom, rangeTo interface{}) (int64, error) {
+	var conditions []string
+	var args []interface{}
+
+	if lastKey != nil {
+		keys := make([]string, len(keyColumns))
+		params := make([]string, len(keyColumns))
+		for i, col := range keyColumns {
+			keys[i] = pgx.Identifier{col}.Sanitize()
+			args = append(args, lastKey[i])
+			params[i] = fmt.Sprintf("$%d", len(args))
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s) > (%s)", strings.Join(keys, ", "), strings.Join(params, ", ")))
+	}
+	if rangeColumn != "" && rangeFrom != nil {
+		args = append(args, rangeFrom)
+		conditions = append(conditions, fmt.Sprintf("%s >= $%d", pgx.Identifier{rangeColumn}.Sanitize(), len(args)))
+	}
+	if rangeColumn != "" && rangeTo != nil {
+		args = append(args, rangeTo)
+		conditions = append(conditions, fmt.Sprintf("%s < $%d", pgx.Identifier{rangeColumn}.Sanitize(), len(args)))
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s.%s",
+		pgx.Identifier{schema}.Sanitize(),
+		pgx.Identifier{tableName}.Sanitize())
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	tag, err := c.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// WithSession runs fn on one pooled connection with the given configuration parameters set for its session.
+// The parameters are reset before the connection returns to the pool.
+func (c *PostgresConnection) WithSession(ctx context.Context, settings map[string]string, fn func(conn *pgx.Conn) error) error {
+	conn, err := c.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	defer func() {
+		// 無法重設的連線直接關閉，避免設定殘留到之後借用的工作
+		if _, err := conn.Exec(context.Background(), "RESET ALL"); err != nil {
+			conn.Conn().Close(context.Background())
+		}
+	}()
+	for name, value := range settings {
+		if _, err := conn.Exec(ctx, "SELECT set_config($1, $2, false)", name, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", name, err)
+		}
+	}
+	return fn(conn.Conn())
+}
+
+// IndexNames returns the names of the indexes of a table, including its primary key
+func (c *PostgresConnection) IndexNames(ctx context.Context, schema, tableName string) (map[string]bool, error) {
+	rows, err := c.pool.Query(ctx, "SELECT indexname FROM pg_indexes WHERE schemaname = $1 AND tablename = $2", schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names[name] = true
+	}
+	return names, rows.Err()
+}
+
 // TableExists checks if a table exists
 func (c *PostgresConnection) TableExists(ctx context.Context, schema, tableName string) (bool, error) {
 	query := `
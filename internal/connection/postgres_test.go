@@ -0,0 +1,142 @@
+package connection
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildUpsertRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		keys    []string
+		want    string
+	}{
+		{
+			"update non-key columns",
+			[]string{"Id", "Name", "Price"}, []string{"Id"},
+			`INSERT INTO "dbo"."Items" ("Id", "Name", "Price") VALUES ($1, $2, $3) ON CONFLICT ("Id") DO UPDATE SET "Name" = EXCLUDED."Name", "Price" = EXCLUDED."Price"`,
+		},
+		{
+			"key only",
+			[]string{"OrderId", "LineNo"}, []string{"OrderId", "LineNo"},
+			`INSERT INTO "dbo"."Items" ("OrderId", "LineNo") VALUES ($1, $2) ON CONFLICT ("OrderId", "LineNo") DO NOTHING`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildUpsertRow("dbo", "Items", tt.columns, tt.keys); got != tt.want {
+				t.Errorf("buildUpsertRow() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildDeleteByKey(t *testing.T) {
+	want := `DELETE FROM "dbo"."Lines" WHERE "OrderId" = $1 AND "LineNo" = $2`
+	if got := buildDeleteByKey("dbo", "Lines", []string{"OrderId", "LineNo"}); got != want {
+		t.Errorf("buildDeleteByKey() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildOrphanQueries(t *testing.T) {
+	countSQL, sampleSQL := buildOrphanQueries("sales", "Lines", []string{"OrderId", "Region"}, "sales", "Orders", []string{"Id", "Region"})
+
+	from := `FROM "sales"."Lines" c WHERE c."OrderId" IS NOT NULL AND c."Region" IS NOT NULL AND NOT EXISTS ` +
+		`(SELECT 1 FROM "sales"."Orders" p WHERE p."Id" = c."OrderId" AND p."Region" = c."Region")`
+	if want := "SELECT COUNT(*) " + from; countSQL != want {
+		t.Errorf("count query = %q, want %q", countSQL, want)
+	}
+	if want := `SELECT DISTINCT c."OrderId", c."Region" ` + from + ` ORDER BY c."OrderId", c."Region" LIMIT $1`; sampleSQL != want {
+		t.Errorf("sample query = %q, want %q", sampleSQL, want)
+	}
+}
+
+func TestBulkLoadProfile_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile BulkLoadProfile
+		want    string
+	}{
+		{"empty", BulkLoadProfile{}, ""},
+		{"synchronous commit only", BulkLoadProfile{SynchronousCommitOff: true}, "synchronous_commit=off"},
+		{"full", BulkLoadProfile{SynchronousCommitOff: true, MaintenanceWorkMemMB: 2048}, "maintenance_work_mem=2048MB, synchronous_commit=off"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateIdentifier(t *testing.T) {
+	long := strings.Repeat("a", 70)
+	// 62 個 ASCII 後接 3 位元組的中文字，第 63 個位元組落在字元中間
+	cjk := strings.Repeat("b", 62) + "表格"
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"short", "Orders", "Orders"},
+		{"exactly 63", long[:63], long[:63]},
+		{"ascii", long, long[:63]},
+		{"utf8 boundary", cjk, strings.Repeat("b", 62)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TruncateIdentifier(tt.in); got != tt.want {
+				t.Errorf("TruncateIdentifier(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrimaryKeyName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"short", "Orders", "Orders_pkey"},
+		{"ascii", strings.Repeat("a", 70), strings.Repeat("a", 58) + "_pkey"},
+		// 57 個 ASCII 後接 3 位元組的中文字，第 58 個位元組落在字元中間
+		{"utf8 boundary", strings.Repeat("b", 57) + "表格", strings.Repeat("b", 57) + "_pkey"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrimaryKeyName(tt.in); got != tt.want {
+				t.Errorf("PrimaryKeyName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSequenceName(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  string
+		column string
+		want   string
+	}{
+		{"short", "Orders", "Id", "Orders_Id_seq"},
+		// 超出長度時先縮短較長的名稱
+		{"long table", strings.Repeat("a", 70), "Id", strings.Repeat("a", 56) + "_Id_seq"},
+		{"both long", strings.Repeat("a", 40), strings.Repeat("c", 30), strings.Repeat("a", 29) + "_" + strings.Repeat("c", 29) + "_seq"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SequenceName(tt.table, tt.column)
+			if got != tt.want || len(got) > maxIdentifierLength {
+				t.Errorf("SequenceName(%q, %q) = %q, want %q", tt.table, tt.column, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package migration
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"adaru-db-tool/internal/types"
+)
+
+// encodedValue is the JSON form of one key value, tagged with its Go type so it
+// decodes back to the same type the MSSQL driver produced
+type encodedValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v,omitempty"`
+}
+
+// encodeKey serializes key values for storage in a checkpoint; a nil key encodes to ""
+func encodeKey(key []interface{}) (string, error) {
+	if key == nil {
+		return "", nil
+	}
+
+	encoded := make([]encodedValue, len(key))
+	for i, val := range key {
+		switch v := val.(type) {
+		case nil:
+			encoded[i] = encodedValue{Type: "null"}
+		case int64:
+			encoded[i] = encodedValue{Type: "int64", Value: strconv.FormatInt(v, 10)}
+		case float64:
+			encoded[i] = encodedValue{Type: "float64", Value: strconv.FormatFloat(v, 'g', -1, 64)}
+		case bool:
+			encoded[i] = encodedValue{Type: "bool", Value: strconv.FormatBool(v)}
+		case string:
+			encoded[i] = encodedValue{Type: "string", Value: v}
+		case []byte:
+			encoded[i] = encodedValue{Type: "bytes", Value: base64.StdEncoding.EncodeToString(v)}
+		case time.Time:
+			encoded[i] = encodedValue{Type: "time", Value: v.Format(time.RFC3339Nano)}
+		default:
+			return "", fmt.Errorf("unsupported key value type %T", val)
+		}
+	}
+
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeKey restores key values written by encodeKey; "" decodes to a nil key
+func decodeKey(s string) ([]interface{}, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var encoded []encodedValue
+	if err := json.Unmarshal([]byte(s), &encoded); err != nil {
+		return nil, err
+	}
+
+	key := make([]interface{}, len(encoded))
+	for i, ev := range encoded {
+		var err error
+		switch ev.Type {
+		case "null":
+			key[i] = nil
+		case "int64":
+			key[i], err = strconv.ParseInt(ev.Value, 10, 64)
+		case "float64":
+			key[i], err = strconv.ParseFloat(ev.Value, 64)
+		case "bool":
+			key[i], err = strconv.ParseBool(ev.Value)
+		case "string":
+			key[i] = ev.Value
+		case "bytes":
+			key[i], err = base64.StdEncoding.DecodeString(ev.Value)
+		case "time":
+			key[i], err = time.Parse(time.RFC3339Nano, ev.Value)
+		default:
+			err = fmt.Errorf("unknown key value type %q", ev.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// encodeBound serializes one range bound; an open (nil) bound encodes to ""
+func encodeBound(bound interface{}) (string, error) {
+	if bound == nil {
+		return "", nil
+	}
+	return encodeKey([]interface{}{bound})
+}
+
+// decodeBound restores a range bound written by encodeBound
+func decodeBound(s string) (interface{}, error) {
+	key, err := decodeKey(s)
+	if err != nil || key == nil {
+		return nil, err
+	}
+	return key[0], nil
+}
+
+// tableResume is the checkpointed progress of one table loaded for a resumed run
+type tableResume struct {
+	phase      string
+	lastKey    []interface{}
+	rowsCopied int64
+	rowVersion []byte           // 上次開始複製前記錄的 rowversion 水位
+	replStart  replicationStart // 上次開始複製前記錄的 CT 版本與 CDC LSN
+	parts      []rangePart      // 上次執行時的主鍵範圍（未切分則為空）
+}
+
+// loadResume groups the checkpoints of a migration by table
+func loadResume(cps []types.TableCheckpoint) (map[string]*tableResume, error) {
+	resume := make(map[string]*tableResume)
+	for _, cp := range cps {
+		tableName := fmt.Sprintf("%s.%s", cp.SchemaName, cp.TableName)
+		tr, ok := resume[tableName]
+		if !ok {
+			tr = &tableResume{}
+			resume[tableName] = tr
+		}
+
+		lastKey, err := decodeKey(cp.LastKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint key for %s: %w", tableName, err)
+		}
+
+		if cp.Part == 0 {
+			tr.phase = cp.Phase
+			tr.lastKey = lastKey
+			tr.rowsCopied = cp.RowsCopied
+			if cp.RowVersion != "" {
+				if tr.rowVersion, err = hex.DecodeString(cp.RowVersion); err != nil {
+					return nil, fmt.Errorf("invalid checkpoint rowversion for %s: %w", tableName, err)
+				}
+			}
+			if cp.ReplStart != "" {
+				if err := json.Unmarshal([]byte(cp.ReplStart), &tr.replStart); err != nil {
+					return nil, fmt.Errorf("invalid checkpoint replication start for %s: %w", tableName, err)
+				}
+			}
+			continue
+		}
+
+		from, err := decodeBound(cp.RangeFrom)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint range for %s: %w", tableName, err)
+		}
+		to, err := decodeBound(cp.RangeTo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint range for %s: %w", tableName, err)
+		}
+		tr.parts = append(tr.parts, rangePart{
+			part:       cp.Part,
+			keyRange:   keyRange{From: from, To: to},
+			startKey:   lastKey,
+			rowsCopied: cp.RowsCopied,
+			done:       cp.Phase == types.CheckpointPhaseCompleted,
+		})
+	}
+	return resume, nil
+}
+
+// saveCheckpoint persists the progress of a table part.
+// Failures are logged but do not stop the copy; the worst case is re-copying from an older checkpoint.
+func (e *Engine) saveCheckpoint(table types.TableInfo, part int, phase string, r *keyRange, lastKey []interface{}, rows int64) {
+	tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+	cp := &types.TableCheckpoint{
+		MigrationID: e.migrationID,
+		SchemaName:  table.Schema,
+		TableName:   table.Name,
+		Part:        part,
+		Phase:       phase,
+		RowsCopied:  rows,
+	}
+
+	var err error
+	if cp.LastKey, err = encodeKey(lastKey); err == nil && r != nil {
+		if cp.RangeFrom, err = encodeBound(r.From); err == nil {
+			cp.RangeTo, err = encodeBound(r.To)
+		}
+	}
+	if err == nil {
+		err = e.storage.SaveTableCheckpoint(cp)
+	}
+	if err != nil {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Failed to save checkpoint for %s: %v", tableName, err))
+	}
+}
+
+// prepareResume removes target rows committed after the last checkpoint of a partially
+// copied table, so copying can continue from the checkpoint without duplicating rows.
+// Upsert loads keep every target row, since re-applying rows is harmless.
+// Tables whose key sorts differently in PostgreSQL are truncated and copied again.
+// It returns the key to continue from (single-range tables) and the checkpointed ranges.
+func (e *Engine) prepareResume(ctx context.Context, w *tableWorker, table types.TableInfo, plan *readPlan, resume *tableResume) ([]interface{}, []rangePart, error) {
+	tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+	schema, name := plan.target(table)
+
+	// 無主鍵的表格無法精確定位中斷位置，清空後從頭複製
+	if !plan.keyset() {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Resuming %s from scratch: no primary key or unique index to continue from", tableName))
+		resume.rowsCopied = 0
+		return nil, nil, w.targetConn.TruncateTable(ctx, schema, name)
+	}
+
+	keyNames := make([]string, len(plan.keyColumns))
+	for i, col := range plan.keyColumns {
+		keyNames[i] = e.names.Column(table.Schema, table.Name, col.Name)
+	}
+
+	// 範圍檢查點在開始複製前寫入，中斷於寫入途中時範圍不完整，此時尚未複製任何範圍，從頭開始即可
+	if len(resume.parts) > 0 && !coversKeySpace(resume.parts) {
+		resume.parts = nil
+		resume.lastKey = nil
+	}
+
+	// upsert 可重複套用，目標表格可能原本就有資料，不刪除任何資料，直接從檢查點接續
+	if len(plan.upsertKeys) > 0 {
+		return resume.lastKey, resume.parts, nil
+	}
+
+	// 檢查點之後的資料以 PostgreSQL 的排序刪除，來源卻依 SQL Server 的排序接續讀取；
+	// 兩者排序不一致的主鍵會遺漏或重複資料，清空後從頭複製
+	if (resume.lastKey != nil || len(resume.parts) > 0) && !sameKeyOrder(plan.keyColumns) {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Resuming %s from scratch: its key does not sort the same way in SQL Server and PostgreSQL", tableName))
+		resume.rowsCopied = 0
+		resume.parts = nil
+		return nil, nil, w.targetConn.TruncateTable(ctx, schema, name)
+	}
+
+	if len(resume.parts) > 0 {
+		for _, p := range resume.parts {
+			if p.done {
+				continue
+			}
+			startKey, from, to, err := targetResumeKey(plan, p.startKey, p.keyRange)
+			if err != nil {
+				return nil, nil, err
+			}
+			deleted, err := w.targetConn.DeleteRowsAfterKey(ctx, schema, name, keyNames, startKey, keyNames[0], from, to)
+			if err != nil {
+				return nil, nil, err
+			}
+			if deleted > 0 {
+				e.log(types.LogLevelInfo, fmt.Sprintf("Removed %d rows of %s range %s committed after its checkpoint", deleted, tableName, p.keyRange))
+			}
+		}
+		return nil, resume.parts, nil
+	}
+
+	if resume.lastKey == nil {
+		resume.rowsCopied = 0
+		return nil, nil, w.targetConn.TruncateTable(ctx, schema, name)
+	}
+
+	lastKey, err := plan.targetKey(resume.lastKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert checkpoint key: %w", err)
+	}
+	deleted, err := w.targetConn.DeleteRowsAfterKey(ctx, schema, name, keyNames, lastKey, "", nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if deleted > 0 {
+		e.log(types.LogLevelInfo, fmt.Sprintf("Removed %d rows of %s committed after its checkpoint", deleted, tableName))
+	}
+	return resume.lastKey, nil, nil
+}
+
+// targetResumeKey converts the checkpointed key and range bounds of a part to their target values;
+// the checkpoint keeps the source values, which the resumed read sends back to SQL Server
+func targetResumeKey(plan *readPlan, startKey []interface{}, r keyRange) ([]interface{}, interface{}, interface{}, error) {
+	key, err := plan.targetKey(startKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to convert checkpoint key: %w", err)
+	}
+	// 範圍以主鍵第一欄切分，邊界依第一欄轉換
+	from, err := plan.targetKey([]interface{}{r.From})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to convert range bound: %w", err)
+	}
+	to, err := plan.targetKey([]interface{}{r.To})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to convert range bound: %w", err)
+	}
+	return key, from[0], to[0], nil
+}
+
+// sameKeyOrder reports whether PostgreSQL sorts key columns the same way SQL Server does, so the rows
+// after a checkpoint can be removed by a key range. Text keys sort by collation (e.g. case-insensitively
+// on SQL Server), uniqueidentifier compares its last byte group first, and datetime and fractions
+// finer than microseconds are rounded on the target, so only integer, decimal and date keys qualify.
+// Decimal keys are checkpointed as the driver's text bytes, which the resumed seek sends back as text.
+func sameKeyOrder(cols []types.ColumnInfo) bool {
+	for _, col := range cols {
+		switch strings.ToLower(col.DataType) {
+		case "bigint", "int", "smallint", "tinyint", "decimal", "numeric", "date", "smalldatetime":
+		case "datetime2", "datetimeoffset":
+			if col.Scale > 6 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// coversKeySpace reports whether ordered parts form contiguous ranges from -inf to +inf
+func coversKeySpace(parts []rangePart) bool {
+	if parts[0].keyRange.From != nil || parts[len(parts)-1].keyRange.To != nil {
+		return false
+	}
+	for i := 1; i < len(parts); i++ {
+		if parts[i].keyRange.From == nil || parts[i].keyRange.From != parts[i-1].keyRange.To {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package migration
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"adaru-db-tool/internal/types"
+)
+
+func TestEncodeKey_RoundTrip(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.UTC)
+
+	tests := []struct {
+		name string
+		key  []interface{}
+	}{
+		{"nil key", nil},
+		{"int", []interface{}{int64(42)}},
+		{"negative int", []interface{}{int64(-9223372036854775808)}},
+		{"composite", []interface{}{"A-001", int64(7), true}},
+		{"float", []interface{}{3.25}},
+		{"null part", []interface{}{nil, int64(1)}},
+		{"bytes", []interface{}{[]byte{0x00, 0xff, 0x10}}},
+		{"decimal", []interface{}{[]byte("1234.50"), int64(3)}},
+		{"time", []interface{}{ts}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := encodeKey(tt.key)
+			if err != nil {
+				t.Fatalf("encodeKey() error = %v", err)
+			}
+			got, err := decodeKey(encoded)
+			if err != nil {
+				t.Fatalf("decodeKey(%q) error = %v", encoded, err)
+			}
+			if len(got) != len(tt.key) || (got == nil) != (tt.key == nil) {
+				t.Fatalf("decodeKey(%q) = %v, want %v", encoded, got, tt.key)
+			}
+			for i := range tt.key {
+				switch want := tt.key[i].(type) {
+				case []byte:
+					if b, ok := got[i].([]byte); !ok || !bytes.Equal(b, want) {
+						t.Errorf("value %d = %#v, want %#v", i, got[i], want)
+					}
+				case time.Time:
+					if g, ok := got[i].(time.Time); !ok || !g.Equal(want) {
+						t.Errorf("value %d = %#v, want %#v", i, got[i], want)
+					}
+				default:
+					if got[i] != tt.key[i] {
+						t.Errorf("value %d = %#v, want %#v", i, got[i], tt.key[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeKey_UnsupportedType(t *testing.T) {
+	if _, err := encodeKey([]interface{}{struct{}{}}); err == nil {
+		t.Fatal("encodeKey() expected error for unsupported type")
+	}
+}
+
+func TestCoversKeySpace(t *testing.T) {
+	part := func(from, to interface{}) rangePart {
+		return rangePart{keyRange: keyRange{From: from, To: to}}
+	}
+
+	tests := []struct {
+		name  string
+		parts []rangePart
+		want  bool
+	}{
+		{"complete", []rangePart{part(nil, int64(10)), part(int64(10), int64(20)), part(int64(20), nil)}, true},
+		{"missing last", []rangePart{part(nil, int64(10)), part(int64(10), int64(20))}, false},
+		{"missing first", []rangePart{part(int64(10), int64(20)), part(int64(20), nil)}, false},
+		{"gap", []rangePart{part(nil, int64(10)), part(int64(20), nil)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := coversKeySpace(tt.parts); got != tt.want {
+				t.Errorf("coversKeySpace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameKeyOrder(t *testing.T) {
+	col := func(dataType string, scale int) types.ColumnInfo {
+		return types.ColumnInfo{Name: "k", DataType: dataType, Scale: scale}
+	}
+
+	tests := []struct {
+		name string
+		cols []types.ColumnInfo
+		want bool
+	}{
+		{"int", []types.ColumnInfo{col("int", 0)}, true},
+		{"int and date", []types.ColumnInfo{col("BIGINT", 0), col("date", 0)}, true},
+		{"decimal", []types.ColumnInfo{col("decimal", 2), col("NUMERIC", 0)}, true},
+		{"datetime2(6)", []types.ColumnInfo{col("datetime2", 6)}, true},
+		{"datetime2(7)", []types.ColumnInfo{col("datetime2", 7)}, false},
+		{"datetime", []types.ColumnInfo{col("datetime", 0)}, false},
+		{"nvarchar", []types.ColumnInfo{col("nvarchar", 0)}, false},
+		{"int and varchar", []types.ColumnInfo{col("int", 0), col("varchar", 0)}, false},
+		{"uniqueidentifier", []types.ColumnInfo{col("uniqueidentifier", 0)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameKeyOrder(tt.cols); got != tt.want {
+				t.Errorf("sameKeyOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
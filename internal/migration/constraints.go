@@ -0,0 +1,150 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"adaru-db-tool/internal/types"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// orphanSampleSize is the number of distinct offending keys reported per foreign key
+const orphanSampleSize = 10
+
+// foreignKeyTable is a table with the foreign keys added as NOT VALID that still need validation, under their target names
+type foreignKeyTable struct {
+	table       *types.TableInfo
+	foreignKeys []types.ForeignKey
+}
+
+// foreignKeyStats counts the outcome of the foreign key validations of all tables
+type foreignKeyStats struct {
+	mu        sync.Mutex
+	validated int
+	orphaned  int
+	failed    int
+}
+
+// isForeignKeyViolation reports whether an error is a PostgreSQL foreign key violation
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// validateForeignKeys validates the foreign keys added as NOT VALID, ValidateParallelism tables at a time.
+// VALIDATE CONSTRAINT only blocks schema changes on the table, so reads and writes continue meanwhile;
+// the constraints of one table are validated one after another since they would wait for each other anyway.
+func (e *Engine) validateForeignKeys(ctx context.Context, tables []foreignKeyTable) error {
+	workerCount := e.config.ValidateParallelism
+	if workerCount > len(tables) {
+		workerCount = len(tables)
+	}
+
+	e.log(types.LogLevelInfo, fmt.Sprintf("Validating the foreign keys of %d tables...", len(tables)))
+	start := time.Now()
+	stats := &foreignKeyStats{}
+	jobs := make(chan foreignKeyTable)
+	var wg sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				for _, fk := range job.foreignKeys {
+					if ctx.Err() != nil {
+						break
+					}
+					fkStart := time.Now()
+					err := e.targetConn.ValidateConstraint(ctx, job.table.Schema, job.table.Name, fk.Name)
+					if err != nil && ctx.Err() != nil {
+						break
+					}
+
+					stats.mu.Lock()
+					switch {
+					case err == nil:
+						stats.validated++
+					case isForeignKeyViolation(err):
+						stats.orphaned++
+					default:
+						stats.failed++
+					}
+					stats.mu.Unlock()
+					e.recordForeignKey(ctx, job.table, fk, err, time.Since(fkStart))
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, job := range tables {
+		e.checkPaused(ctx)
+
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- job:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	e.log(types.LogLevelInfo, fmt.Sprintf("Validated %d foreign keys in %s (%d with orphaned rows, %d failed)",
+		stats.validated, time.Since(start).Round(time.Millisecond), stats.orphaned, stats.failed))
+	return nil
+}
+
+// recordForeignKey stores the outcome of adding or validating a foreign key of a target table.
+// A violation is reported with the number of orphaned rows and a sample of their keys.
+func (e *Engine) recordForeignKey(ctx context.Context, table *types.TableInfo, fk types.ForeignKey, fkErr error, elapsed time.Duration) {
+	tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+	check := &types.ForeignKeyCheck{
+		MigrationID:     e.migrationID,
+		SchemaName:      table.Schema,
+		TableName:       table.Name,
+		ConstraintName:  fk.Name,
+		ReferencedTable: fk.ReferencedSchema + "." + fk.ReferencedTable,
+		Valid:           fkErr == nil,
+		DurationMs:      elapsed.Milliseconds(),
+	}
+	if fkErr != nil {
+		check.Error = fkErr.Error()
+	}
+
+	if isForeignKeyViolation(fkErr) {
+		count, samples, err := e.targetConn.FindOrphans(ctx, table.Schema, table.Name, fk.Columns,
+			fk.ReferencedSchema, fk.ReferencedTable, fk.ReferencedColumns, orphanSampleSize)
+		if err != nil {
+			e.log(types.LogLevelWarn, fmt.Sprintf("Failed to find the orphaned rows of foreign key %s: %v", fk.Name, err))
+		} else {
+			check.OrphanCount = count
+			check.SampleKeys = orphanSamplesJSON(fk.Columns, samples)
+			// NOT VALID 的外鍵仍會檢查之後寫入的資料，只是既有資料未通過驗證
+			e.log(types.LogLevelWarn, fmt.Sprintf("Foreign key %s of %s: %d rows reference missing rows of %s, e.g. %s",
+				fk.Name, tableName, count, check.ReferencedTable, check.SampleKeys))
+		}
+	} else if fkErr == nil && e.config.ForeignKeysNotValid {
+		e.log(types.LogLevelInfo, fmt.Sprintf("Validated foreign key %s of %s in %s", fk.Name, tableName, elapsed.Round(time.Millisecond)))
+	}
+
+	if err := e.storage.SaveForeignKeyCheck(check); err != nil {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Failed to record foreign key %s: %v", fk.Name, err))
+	}
+}
+
+// orphanSamplesJSON encodes sampled foreign key values as a JSON array of objects keyed by column name
+func orphanSamplesJSON(columns []string, samples [][]interface{}) string {
+	values := make([]string, len(samples))
+	for i, sample := range samples {
+		values[i] = quarantineJSON(columns, sample)
+	}
+	return "[" + strings.Join(values, ",") + "]"
+}
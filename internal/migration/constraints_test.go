@@ -0,0 +1,39 @@
+package migration
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsForeignKeyViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"foreign key violation", &pgconn.PgError{Code: "23503"}, true},
+		{"wrapped", fmt.Errorf("validate: %w", &pgconn.PgError{Code: "23503"}), true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isForeignKeyViolation(tt.err); got != tt.want {
+				t.Errorf("isForeignKeyViolation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrphanSamplesJSON(t *testing.T) {
+	got := orphanSamplesJSON([]string{"OrderId", "Region"}, [][]interface{}{{int64(7), "EU"}, {int64(9), "US"}})
+	want := `[{"OrderId":7,"Region":"EU"},{"OrderId":9,"Region":"US"}]`
+	if got != want {
+		t.Errorf("orphanSamplesJSON() = %s, want %s", got, want)
+	}
+	if got := orphanSamplesJSON([]string{"OrderId"}, nil); got != "[]" {
+		t.Errorf("orphanSamplesJSON(nil) = %s, want []", got)
+	}
+}
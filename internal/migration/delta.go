@@ -0,0 +1,217 @@
+package migration
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"adaru-db-tool/internal/types"
+)
+
+// rowVersionColumn returns the rowversion (timestamp) column of a table, if it has one
+func rowVersionColumn(table *types.TableInfo) (types.ColumnInfo, bool) {
+	for _, col := range table.Columns {
+		switch strings.ToLower(col.DataType) {
+		case "timestamp", "rowversion":
+			return col, true
+		}
+	}
+	return types.ColumnInfo{}, false
+}
+
+// deltaBlocker explains why a table cannot be delta-synced, or returns "" when it can
+func deltaBlocker(table *types.TableInfo, wm *types.SyncWatermark) string {
+	col, ok := rowVersionColumn(table)
+	switch {
+	case !ok:
+		return "no rowversion column to detect changed rows"
+	case len(table.PrimaryKey) == 0:
+		return "no primary key to upsert changed rows on"
+	case wm == nil:
+		return "no high-water mark recorded; run a full load first"
+	case !strings.EqualFold(wm.VersionColumn, col.Name):
+		return fmt.Sprintf("high-water mark was recorded for column %s, table now uses %s; run a full load first", wm.VersionColumn, col.Name)
+	}
+	return ""
+}
+
+// captureRowVersion reads the rowversion bound for a table about to be fully copied.
+// Rows changed after this point have a higher rowversion and are picked up by the next delta sync.
+// It returns nil when the table has no rowversion column or the bound cannot be trusted.
+func (e *Engine) captureRowVersion(ctx context.Context, w *tableWorker, table *types.TableInfo, resume *tableResume) []byte {
+	tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+	if _, ok := rowVersionColumn(table); !ok || len(table.PrimaryKey) == 0 {
+		return nil
+	}
+
+	// 續傳時沿用中斷前記錄的水位，中斷期間變更的資料才不會漏掉
+	if resume != nil && resume.phase != types.CheckpointPhaseSchema {
+		if resume.rowVersion == nil {
+			e.log(types.LogLevelWarn, fmt.Sprintf("%s: the interrupted copy recorded no rowversion high-water mark; delta sync needs another full load", tableName))
+		}
+		return resume.rowVersion
+	}
+
+	rv, err := e.minActiveRowVersion(ctx, w)
+	if err != nil {
+		e.log(types.LogLevelWarn, fmt.Sprintf("%s: failed to read MIN_ACTIVE_ROWVERSION, delta sync needs another full load: %v", tableName, err))
+		return nil
+	}
+	return rv
+}
+
+// saveWatermark records the rowversion high-water mark of a table after its rows have been copied
+func (e *Engine) saveWatermark(table *types.TableInfo, rv []byte) {
+	tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+	col, _ := rowVersionColumn(table)
+	wm := &types.SyncWatermark{
+		SourceDatabase: e.config.SourceDatabase,
+		TargetDatabase: e.config.TargetDatabase,
+		SchemaName:     table.Schema,
+		TableName:      table.Name,
+		VersionColumn:  col.Name,
+		HighWater:      hex.EncodeToString(rv),
+		MigrationID:    e.migrationID,
+	}
+	if err := e.storage.SaveSyncWatermark(wm); err != nil {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Failed to save high-water mark for %s: %v", tableName, err))
+		return
+	}
+	e.log(types.LogLevelInfo, fmt.Sprintf("High-water mark of %s on %s: 0x%s", tableName, col.Name, wm.HighWater))
+}
+
+// syncTableDelta copies the rows of a table changed since its high-water mark and upserts them into the target.
+// Deleted source rows are not detected by rowversion and stay in the target.
+func (e *Engine) syncTableDelta(ctx context.Context, w *tableWorker, table types.TableInfo) error {
+	tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+
+	status := "running"
+	var migratedRows int64
+	var errorMsg string
+
+	defer func() {
+		var level types.LogLevel
+		var message string
+		switch status {
+		case "completed":
+			level = types.LogLevelInfo
+			message = fmt.Sprintf("Delta synced %s: %d rows upserted", tableName, migratedRows)
+		case "not-possible":
+			level = types.LogLevelWarn
+			message = fmt.Sprintf("Delta sync not possible for %s: %s", tableName, errorMsg)
+		case "failed":
+			level = types.LogLevelError
+			message = fmt.Sprintf("Failed delta sync %s: %s", tableName, errorMsg)
+		default:
+			level = types.LogLevelWarn
+			message = fmt.Sprintf("Interrupted delta sync %s: %d rows upserted (status: %s)", tableName, migratedRows, status)
+		}
+		e.logTableProgress(level, message, tableName, status, nil, &migratedRows, errorMsg)
+
+		e.mu.Lock()
+		if ts, ok := e.state.Tables[tableName]; ok {
+			ts.MigratedRows = migratedRows
+			ts.EndTime = time.Now()
+			switch status {
+			case "not-possible":
+				// 無法同步的表格未更新，不視為完成，也不進行後續維護
+				ts.Status = types.MigrationStatusFailed
+			default:
+				ts.Status = types.MigrationStatus(status)
+			}
+			ts.Error = errorMsg
+		}
+		e.state.removeCurrentTable(tableName)
+		e.mu.Unlock()
+	}()
+
+	e.mu.Lock()
+	e.state.addCurrentTable(tableName)
+	e.state.Tables[tableName] = &TableState{
+		Name:      table.Name,
+		Schema:    table.Schema,
+		Status:    types.MigrationStatusRunning,
+		StartTime: time.Now(),
+	}
+	e.mu.Unlock()
+
+	tableDetails, err := w.sourceConn.GetTableDetails(ctx, table.Schema, table.Name)
+	if err != nil {
+		status = "failed"
+		errorMsg = err.Error()
+		return err
+	}
+
+	wm, err := e.storage.GetSyncWatermark(e.config.SourceDatabase, e.config.TargetDatabase, table.Schema, table.Name)
+	if err != nil {
+		status = "failed"
+		errorMsg = fmt.Sprintf("failed to load high-water mark: %v", err)
+		return err
+	}
+	if reason := deltaBlocker(tableDetails, wm); reason != "" {
+		status = "not-possible"
+		errorMsg = reason
+		return nil
+	}
+	from, err := hex.DecodeString(wm.HighWater)
+	if err != nil {
+		status = "failed"
+		errorMsg = fmt.Sprintf("invalid high-water mark %q: %v", wm.HighWater, err)
+		return err
+	}
+
+	// 上界取 MIN_ACTIVE_ROWVERSION：低於它的資料都已提交，尚未提交的交易留到下次同步
+	to, err := w.sourceConn.GetMinActiveRowVersion(ctx)
+	if err != nil {
+		status = "failed"
+		errorMsg = fmt.Sprintf("failed to read MIN_ACTIVE_ROWVERSION: %v", err)
+		return err
+	}
+	if bytes.Compare(from, to) >= 0 {
+		status = "completed"
+		return nil
+	}
+
+	plan := planTableRead(tableDetails)
+	e.applyValuePolicies(table, plan)
+	col, _ := rowVersionColumn(tableDetails)
+	plan.versionColumn = col.Name
+	plan.versionFrom = from
+	plan.versionTo = to
+	pgColumns := e.applyNaming(tableDetails, plan)
+	e.upsertByKey(tableDetails, plan)
+
+	stats := newPipelineStats()
+	_, err = e.copyTableRows(ctx, w, table, plan, pgColumns, stats, func(n int64, _ []interface{}) {
+		migratedRows += n
+
+		e.mu.Lock()
+		e.state.MigratedRows += n
+		if ts, ok := e.state.Tables[tableName]; ok {
+			ts.MigratedRows = migratedRows
+			ts.ReadRowsPerSec = stats.ReadRowsPerSec()
+			ts.WriteRowsPerSec = stats.WriteRowsPerSec()
+		}
+		e.mu.Unlock()
+
+		e.emitProgress(tableName, 0, migratedRows, stats)
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			status = "cancelled"
+			errorMsg = ctx.Err().Error()
+			return ctx.Err()
+		}
+		status = "failed"
+		errorMsg = err.Error()
+		return err
+	}
+
+	// 整張表同步成功後才推進水位，失敗時下次從舊水位重來（upsert 可重複執行）
+	e.saveWatermark(tableDetails, to)
+	status = "completed"
+	return nil
+}
@@ -0,0 +1,57 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"adaru-db-tool/internal/types"
+)
+
+func TestDeltaBlocker(t *testing.T) {
+	withVersion := &types.TableInfo{
+		Columns: []types.ColumnInfo{
+			{Name: "id", DataType: "int"},
+			{Name: "ver", DataType: "timestamp"},
+		},
+		PrimaryKey: []string{"id"},
+	}
+	noVersion := &types.TableInfo{
+		Columns:    []types.ColumnInfo{{Name: "id", DataType: "int"}},
+		PrimaryKey: []string{"id"},
+	}
+	noKey := &types.TableInfo{
+		Columns: []types.ColumnInfo{
+			{Name: "id", DataType: "int"},
+			{Name: "ver", DataType: "rowversion"},
+		},
+	}
+	wm := &types.SyncWatermark{VersionColumn: "ver", HighWater: "00000000000007d1"}
+
+	tests := []struct {
+		name  string
+		table *types.TableInfo
+		wm    *types.SyncWatermark
+		want  string // 預期原因包含的字串，空字串表示可以增量同步
+	}{
+		{"syncable", withVersion, wm, ""},
+		{"no rowversion", noVersion, wm, "no rowversion column"},
+		{"no primary key", noKey, wm, "no primary key"},
+		{"never fully loaded", withVersion, nil, "no high-water mark"},
+		{"version column changed", withVersion, &types.SyncWatermark{VersionColumn: "old_ver"}, "recorded for column old_ver"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deltaBlocker(tt.table, tt.wm)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("deltaBlocker() = %q, want no reason", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("deltaBlocker() = %q, want it to mention %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,12 @@ package migration
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -25,6 +27,9 @@ type Engine struct {
 	targetConn  *connection.PostgresConnection
 	storage     *storage.Storage
 	typeMapper  *converter.TypeMapper
+	timePolicy  converter.TimePolicy // datetime 類型的時區處理，由 SourceTimeZone 與 TimestampTZ 決定
+	typeRules   *converter.TypeRules // 型別對應規則，決定欄位型別與值的轉換；nil 表示使用內建對應
+	names       *converter.Namer     // 目標識別字的命名與更名；nil 表示沿用來源名稱
 	config      *types.MigrationConfig
 	migrationID string
 	state       *MigrationState
@@ -33,31 +38,57 @@ type Engine struct {
 	paused      bool
 	pauseCh     chan struct{}
 	resumeCh    chan struct{}
+	resume      map[string]*tableResume // 續傳時各表格上次的檢查點
+	cutoverCh   chan struct{}           // 持續複寫時要求切換
+	keptTables  map[string]bool         // 保留既有目標表格、未由本次遷移建立的表格
+	throttle    *throttle               // 來源讀取速率限制，執行中可調整
+	snapshot    *sourceSnapshot         // 一致性讀取的來源快照（未啟用時為 nil）
+	windows     []timeWindow            // 允許執行的時段（空表示不限）
 }
 
 // MigrationState tracks the current state of a migration
 type MigrationState struct {
-	Status          types.MigrationStatus
-	StartTime       time.Time
-	TotalTables     int
-	CompletedTables int
-	TotalRows       int64
-	MigratedRows    int64
-	CurrentTable    string
-	Tables          map[string]*TableState
-	Errors          []string
+	Status             types.MigrationStatus
+	StartTime          time.Time
+	TotalTables        int
+	CompletedTables    int
+	TotalRows          int64
+	MigratedRows       int64
+	CurrentTables      []string // 正在處理的表格，並行遷移時可能有多張
+	Tables             map[string]*TableState
+	Replication        *ReplicationState // 持續複寫的進度（其他模式為 nil）
+	ThrottleRowsPerSec int               // 目前的來源讀取限制（0 表示不限）
+	ThrottleMBPerSec   float64
+	Errors             []string
+}
+
+// addCurrentTable marks a table as being processed; the caller must hold e.mu.
+// The slice is copied since GetStatus hands the state out beyond the lock.
+func (s *MigrationState) addCurrentTable(name string) {
+	s.CurrentTables = append(slices.Clone(s.CurrentTables), name)
+}
+
+// removeCurrentTable unmarks a table once a worker is done with it; the caller must hold e.mu.
+func (s *MigrationState) removeCurrentTable(name string) {
+	s.CurrentTables = slices.DeleteFunc(slices.Clone(s.CurrentTables), func(n string) bool { return n == name })
 }
 
 // TableState tracks the state of a single table migration
 type TableState struct {
-	Name         string
-	Schema       string
-	Status       types.MigrationStatus
-	TotalRows    int64
-	MigratedRows int64
-	StartTime    time.Time
-	EndTime      time.Time
-	Error        string
+	Name            string
+	Schema          string
+	Status          types.MigrationStatus
+	TotalRows       int64
+	MigratedRows    int64
+	ReadStrategy    string        // 來源分頁策略（keyset / unique-index / single-pass）
+	ReadRowsPerSec  float64       // 來源讀取速率（不含等待寫入端的時間）
+	WriteRowsPerSec float64       // 目標寫入速率（不含等待讀取端的時間）
+	Ranges          []*RangeState // 主鍵範圍切分時各範圍的進度
+	QuarantinedRows int64         // 寫入失敗而隔離的資料列數
+	TextIssues      int64         // 舊字碼頁字串含無法解碼位元組或造字的值數
+	StartTime       time.Time
+	EndTime         time.Time
+	Error           string
 }
 
 // NewEngine creates a new migration engine
@@ -68,6 +99,8 @@ func NewEngine(ctx context.Context, storage *storage.Storage) *Engine {
 		typeMapper: converter.NewTypeMapper(),
 		pauseCh:    make(chan struct{}),
 		resumeCh:   make(chan struct{}),
+		cutoverCh:  make(chan struct{}),
+		throttle:   &throttle{},
 	}
 }
 
@@ -79,6 +112,117 @@ func (e *Engine) Configure(config *types.MigrationConfig) error {
 	if config.ParallelTables <= 0 {
 		config.ParallelTables = 1
 	}
+	if config.SplitTableRows <= 0 {
+		config.SplitTableRows = 10000000
+	}
+	switch config.SyncMode {
+	case "":
+		config.SyncMode = types.SyncModeFull
+	case types.SyncModeFull, types.SyncModeDelta, types.SyncModeReplicate:
+	default:
+		return fmt.Errorf("unknown sync mode %q", config.SyncMode)
+	}
+	switch config.LoadMode {
+	case "":
+		config.LoadMode = types.LoadModeAppend
+	case types.LoadModeAppend, types.LoadModeTruncate, types.LoadModeUpsert:
+	default:
+		return fmt.Errorf("unknown load mode %q", config.LoadMode)
+	}
+	switch config.TableOrder {
+	case "":
+		config.TableOrder = types.TableOrderManual
+	case types.TableOrderManual, types.TableOrderForeignKey:
+	default:
+		return fmt.Errorf("unknown table order %q", config.TableOrder)
+	}
+	if config.IndexParallelism <= 0 {
+		config.IndexParallelism = 4
+	}
+	if config.ValidateParallelism <= 0 {
+		config.ValidateParallelism = 4
+	}
+	if config.QuarantineMaxRows <= 0 {
+		config.QuarantineMaxRows = 1000
+	}
+	if config.StagingSwap && config.LoadMode == types.LoadModeUpsert {
+		return fmt.Errorf("upsert load mode cannot be combined with staging table swap, which replaces the whole table")
+	}
+	switch config.ReplicationMethod {
+	case "":
+		config.ReplicationMethod = types.ReplicationMethodCT
+	case types.ReplicationMethodCT, types.ReplicationMethodCDC:
+	default:
+		return fmt.Errorf("unknown replication method %q", config.ReplicationMethod)
+	}
+	if config.ReplicationPollSeconds <= 0 {
+		config.ReplicationPollSeconds = 5
+	}
+	if config.ThrottleRowsPerSec < 0 || config.ThrottleMBPerSec < 0 {
+		return fmt.Errorf("throttle limits cannot be negative")
+	}
+	switch config.SnapshotMode {
+	case "":
+		config.SnapshotMode = types.SnapshotModeOff
+	case types.SnapshotModeOff:
+	case types.SnapshotModeIsolation, types.SnapshotModeDatabase:
+		if config.SyncMode != types.SyncModeFull {
+			return fmt.Errorf("consistent snapshot reads are only supported for full sync")
+		}
+	default:
+		return fmt.Errorf("unknown snapshot mode %q", config.SnapshotMode)
+	}
+	// SNAPSHOT 隔離的時間點屬於單一 session，所有表格只能由同一個連線依序讀取
+	if config.SnapshotMode == types.SnapshotModeIsolation {
+		config.ParallelTables = 1
+		config.RangeParallelism = 1
+	}
+	switch config.UndecodableText {
+	case "":
+		config.UndecodableText = types.UndecodableTextReplace
+	case types.UndecodableTextReplace, types.UndecodableTextReject:
+	default:
+		return fmt.Errorf("unknown undecodable text policy %q", config.UndecodableText)
+	}
+	timePolicy, err := converter.NewTimePolicy(config.SourceTimeZone, config.TimestampTZ)
+	if err != nil {
+		return err
+	}
+	config.SourceTimeZone = timePolicy.Zone
+	e.timePolicy = timePolicy
+	e.typeMapper.SetTimePolicy(timePolicy)
+	// 規則集內容隨遷移設定保存，續傳時沿用開始時的規則
+	if config.TypeMappingRuleSet != "" && len(config.TypeMappingRules) == 0 {
+		set, err := e.storage.GetTypeMappingRuleSet(config.TypeMappingRuleSet)
+		if err != nil {
+			return fmt.Errorf("failed to load type mapping rule set: %w", err)
+		}
+		if set == nil {
+			return fmt.Errorf("type mapping rule set %s not found", config.TypeMappingRuleSet)
+		}
+		config.TypeMappingRules = set.Rules
+	}
+	typeRules, err := converter.NewTypeRules(config.TypeMappingRules)
+	if err != nil {
+		return fmt.Errorf("invalid type mapping rules: %w", err)
+	}
+	e.typeRules = typeRules
+	e.typeMapper.SetRules(typeRules)
+	if config.NamingPolicy == "" {
+		config.NamingPolicy = types.NamingPolicyPreserve
+	}
+	names, err := converter.NewNamer(config.NamingPolicy, config.IdentifierRenames)
+	if err != nil {
+		return fmt.Errorf("invalid identifier naming: %w", err)
+	}
+	e.names = names
+	e.typeMapper.SetNames(names)
+	windows, err := parseTimeWindows(config.TimeWindows)
+	if err != nil {
+		return err
+	}
+	e.windows = windows
+	e.throttle.setLimits(config.ThrottleRowsPerSec, config.ThrottleMBPerSec)
 	e.config = config
 	return nil
 }
@@ -91,9 +235,11 @@ func (e *Engine) Start(migrationID string) error {
 
 	// Initialize state
 	e.state = &MigrationState{
-		Status:    types.MigrationStatusRunning,
-		StartTime: time.Now(),
-		Tables:    make(map[string]*TableState),
+		Status:             types.MigrationStatusRunning,
+		StartTime:          time.Now(),
+		Tables:             make(map[string]*TableState),
+		ThrottleRowsPerSec: e.config.ThrottleRowsPerSec,
+		ThrottleMBPerSec:   e.config.ThrottleMBPerSec,
 	}
 
 	// Update migration status
@@ -114,11 +260,13 @@ func (e *Engine) Start(migrationID string) error {
 		return err
 	}
 
-	// Connect to target
-	e.targetConn = connection.NewPostgresConnection(e.config.TargetConnectionString)
-	if err := e.targetConn.Connect(ctx); err != nil {
-		e.fail("Failed to connect to target: " + err.Error())
-		return err
+	// Connect to target（試執行不連線目標）
+	if !e.config.DryRun {
+		e.targetConn = connection.NewPostgresConnection(e.config.TargetConnectionString)
+		if err := e.targetConn.Connect(ctx); err != nil {
+			e.fail("Failed to connect to target: " + err.Error())
+			return err
+		}
 	}
 
 	// Run migration in goroutine
@@ -127,6 +275,38 @@ func (e *Engine) Start(migrationID string) error {
 	return nil
 }
 
+// StartResume restarts an interrupted migration from its checkpoints.
+// Completed tables are skipped and partially copied tables continue after their last committed key.
+func (e *Engine) StartResume(migrationID string) error {
+	cps, err := e.storage.GetTableCheckpoints(migrationID)
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoints: %w", err)
+	}
+	resume, err := loadResume(cps)
+	if err != nil {
+		return err
+	}
+	e.resume = resume
+
+	if err := e.Start(migrationID); err != nil {
+		return err
+	}
+
+	completed := 0
+	for _, tr := range resume {
+		if tr.phase == types.CheckpointPhaseCompleted {
+			completed++
+		}
+	}
+	e.log(types.LogLevelInfo, fmt.Sprintf("Resuming migration: %d tables checkpointed, %d already completed", len(resume), completed))
+	return nil
+}
+
+// MigrationID returns the ID of the migration run by the engine
+func (e *Engine) MigrationID() string {
+	return e.migrationID
+}
+
 // runMigration executes the migration workflow
 func (e *Engine) runMigration(ctx context.Context) {
 	defer e.cleanup()
@@ -144,6 +324,54 @@ func (e *Engine) runMigration(ctx context.Context) {
 
 	e.log(types.LogLevelInfo, fmt.Sprintf("Starting migration of %d tables", len(tables)))
 
+	// 命名方式無法在目標端命名的表格不遷移，否則可能建表失敗或寫入另一個表格
+	tables, skipped := e.excludeNameConflicts(ctx, tables)
+
+	// 試執行只產生 DDL 腳本供審閱，不對目標執行任何陳述式
+	if e.config.DryRun {
+		e.log(types.LogLevelInfo, "Dry run: generating the DDL script without executing it")
+		if err := e.generateScript(ctx, tables, skipped); err != nil {
+			e.fail("Dry run failed: " + err.Error())
+			return
+		}
+		e.complete()
+		return
+	}
+
+	if e.config.ThrottleRowsPerSec > 0 || e.config.ThrottleMBPerSec > 0 {
+		e.log(types.LogLevelInfo, "Source read throttle: "+describeThrottle(e.config.ThrottleRowsPerSec, e.config.ThrottleMBPerSec))
+	}
+	// 時段外自動暫停；migration 結束時停止檢查
+	if len(e.windows) > 0 {
+		windowCtx, stopWindows := context.WithCancel(ctx)
+		defer stopWindows()
+		go e.enforceTimeWindows(windowCtx)
+	}
+
+	// 增量同步只處理資料：目標表格、外鍵與程式物件已由先前的完整遷移建立
+	if e.config.SyncMode == types.SyncModeDelta {
+		e.log(types.LogLevelInfo, "Delta sync: copying rows changed since each table's high-water mark (deleted rows are not propagated)")
+		if err := e.migrateData(ctx, tables); err != nil {
+			e.fail("Delta sync failed: " + err.Error())
+			return
+		}
+		e.complete()
+		return
+	}
+
+	// 持續複寫只套用變更：起始位置已由先前的完整遷移記錄，直到切換為止
+	if e.config.SyncMode == types.SyncModeReplicate {
+		if err := e.replicate(ctx, tables); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			e.fail("Replication failed: " + err.Error())
+			return
+		}
+		e.complete()
+		return
+	}
+
 	// Phase 1: Schema migration
 	if e.config.IncludeSchema {
 		e.log(types.LogLevelInfo, "Phase 1: Migrating schema...")
@@ -156,12 +384,34 @@ func (e *Engine) runMigration(ctx context.Context) {
 	// Phase 2: Data migration
 	if e.config.IncludeData {
 		e.log(types.LogLevelInfo, "Phase 2: Migrating data...")
-		if err := e.migrateData(ctx, tables); err != nil {
+		if e.config.SnapshotMode != types.SnapshotModeOff {
+			if err := e.openSourceSnapshot(ctx); err != nil {
+				e.fail("Failed to open source snapshot: " + err.Error())
+				return
+			}
+		}
+		err := e.migrateData(ctx, tables)
+		// 資料載入完成後即刪除快照，後續階段不再讀取來源資料
+		e.closeSourceSnapshot()
+		if err != nil {
+			// 使用者取消時狀態已由 Cancel 設定，不記為失敗
+			if ctx.Err() != nil {
+				return
+			}
 			e.fail("Data migration failed: " + err.Error())
 			return
 		}
 	}
 
+	// 延後的次要索引在資料全部載入後並行建立（暫存表載入時已於替換前建立）
+	if e.config.IncludeSchema && e.config.DeferIndexes && !e.config.StagingSwap {
+		e.log(types.LogLevelInfo, "Building deferred indexes...")
+		if err := e.buildDeferredIndexes(ctx, tables); err != nil {
+			e.fail("Index build failed: " + err.Error())
+			return
+		}
+	}
+
 	// Phase 3: Foreign keys and constraints
 	if e.config.IncludeSchema {
 		e.log(types.LogLevelInfo, "Phase 3: Creating foreign keys...")
@@ -170,13 +420,26 @@ func (e *Engine) runMigration(ctx context.Context) {
 		}
 	}
 
+	// 載入後維護：重建統計資訊，讓查詢規劃器不必在沒有統計的情況下估算
+	if e.config.IncludeData && (e.config.AnalyzeTables || e.config.VacuumTables || e.config.ClusterTables) {
+		e.log(types.LogLevelInfo, "Running post-load maintenance...")
+		if err := e.maintainTables(ctx, tables); err != nil {
+			e.fail("Post-load maintenance failed: " + err.Error())
+			return
+		}
+	}
+
 	// Phase 4: Views, procedures, functions (if requested)
 	if e.config.IncludeViews || e.config.IncludeProcedures || e.config.IncludeFunctions {
 		e.log(types.LogLevelInfo, "Phase 4: Migrating programmable objects...")
 		e.migrateProgrammableObjects(ctx)
 	}
 
-	// Mark as completed
+	e.complete()
+}
+
+// complete marks the migration as completed
+func (e *Engine) complete() {
 	e.mu.Lock()
 	e.state.Status = types.MigrationStatusCompleted
 	e.mu.Unlock()
@@ -205,22 +468,67 @@ func (e *Engine) getTablestoMigrate(ctx context.Context) ([]types.TableInfo, err
 	}
 
 	// If IncludeTables is specified, use its order
+	tables := allTables
 	if len(e.config.IncludeTables) > 0 {
-		var tables []types.TableInfo
+		tables = nil
 		for _, incl := range e.config.IncludeTables {
 			if table, ok := tableMap[incl]; ok {
 				tables = append(tables, table)
 			}
 		}
+	}
+
+	if e.config.TableOrder != types.TableOrderForeignKey {
 		return tables, nil
 	}
+	foreignKeys, err := e.sourceConn.GetForeignKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+	order := orderByForeignKeys(tables, foreignKeys)
+	e.logLoadOrder(order)
+	return order.tables, nil
+}
 
-	// No include list specified, use all tables
-	return allTables, nil
+// excludeNameConflicts leaves out the tables the naming policy cannot name on the target (see
+// Namer.Conflicts) and reports them as failed. It returns the other tables and why each was left out.
+func (e *Engine) excludeNameConflicts(ctx context.Context, tables []types.TableInfo) ([]types.TableInfo, []string) {
+	if e.names == nil {
+		return tables, nil
+	}
+
+	details := make([]types.TableInfo, len(tables))
+	for i, table := range tables {
+		details[i] = table
+		// 讀取失敗的表格只檢查表格名稱，錯誤由後續階段回報
+		if d, err := e.sourceConn.GetTableDetails(ctx, table.Schema, table.Name); err == nil {
+			details[i] = *d
+		}
+	}
+	conflicts := e.names.Conflicts(details)
+	if len(conflicts) == 0 {
+		return tables, nil
+	}
+
+	var kept []types.TableInfo
+	var skipped []string
+	for _, table := range tables {
+		tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+		problems, ok := conflicts[tableName]
+		if !ok {
+			kept = append(kept, table)
+			continue
+		}
+		msg := fmt.Sprintf("Cannot name %s on the target: %s", tableName, strings.Join(problems, "; "))
+		e.logTableProgress(types.LogLevelError, msg, tableName, "failed", nil, nil, msg)
+		skipped = append(skipped, msg)
+	}
+	return kept, skipped
 }
 
 // migrateSchema creates tables in the target database
 func (e *Engine) migrateSchema(ctx context.Context, tables []types.TableInfo) error {
+	e.keptTables = make(map[string]bool)
 	for _, table := range tables {
 		select {
 		case <-ctx.Done():
@@ -228,10 +536,16 @@ func (e *Engine) migrateSchema(ctx context.Context, tables []types.TableInfo) er
 		default:
 		}
 
-		e.checkPaused()
+		e.checkPaused(ctx)
 
-		// Get detailed table info
+		// 續傳時已建立過的表格不可重建，否則會刪除已複製的資料
 		tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+		if _, ok := e.resume[tableName]; ok {
+			e.log(types.LogLevelInfo, fmt.Sprintf("Skipping schema for %s: already created by the interrupted run", tableName))
+			continue
+		}
+
+		// Get detailed table info
 		tableDetails, err := e.sourceConn.GetTableDetails(ctx, table.Schema, table.Name)
 		if err != nil {
 			e.logTableProgress(types.LogLevelError, fmt.Sprintf("Failed to get details for %s: %v", tableName, err), tableName, "failed", nil, nil, err.Error())
@@ -239,19 +553,38 @@ func (e *Engine) migrateSchema(ctx context.Context, tables []types.TableInfo) er
 		}
 
 		// Create schema if needed
-		if err := e.targetConn.CreateSchema(ctx, table.Schema); err != nil {
-			e.log(types.LogLevelWarn, fmt.Sprintf("Failed to create schema %s: %v", table.Schema, err))
+		target := e.names.TargetTable(*tableDetails)
+		if err := e.targetConn.CreateSchema(ctx, target.Schema); err != nil {
+			e.log(types.LogLevelWarn, fmt.Sprintf("Failed to create schema %s: %v", target.Schema, err))
+		}
+
+		// 暫存表載入時表格於載入後才換上，此處不建立
+		if e.config.StagingSwap {
+			e.log(types.LogLevelInfo, fmt.Sprintf("%s will be created through a staging table", tableName))
+			e.saveCheckpoint(table, 0, types.CheckpointPhaseSchema, nil, nil, 0)
+			continue
 		}
 
 		// Drop table if requested
 		if e.config.DropTargetIfExists {
-			if err := e.targetConn.DropTableIfExists(ctx, table.Schema, table.Name); err != nil {
-				e.log(types.LogLevelWarn, fmt.Sprintf("Failed to drop table %s.%s: %v", table.Schema, table.Name, err))
+			if err := e.targetConn.DropTableIfExists(ctx, target.Schema, target.Name); err != nil {
+				e.log(types.LogLevelWarn, fmt.Sprintf("Failed to drop table %s.%s: %v", target.Schema, target.Name, err))
+			}
+		} else {
+			// 保留既有的目標表格（含相依的 view、外鍵與權限），資料依載入方式寫入
+			exists, err := e.targetConn.TableExists(ctx, target.Schema, target.Name)
+			if err != nil {
+				e.log(types.LogLevelWarn, fmt.Sprintf("Failed to check whether %s exists: %v", tableName, err))
+			} else if exists {
+				e.log(types.LogLevelInfo, fmt.Sprintf("Target table %s already exists, keeping it (load mode: %s)", tableName, e.config.LoadMode))
+				e.keptTables[tableName] = true
+				e.saveCheckpoint(table, 0, types.CheckpointPhaseSchema, nil, nil, 0)
+				continue
 			}
 		}
 
 		// Generate and execute CREATE TABLE
-		createDDL := e.typeMapper.GenerateCreateTableDDL(*tableDetails)
+		createDDL := e.createTableDDL(e.typeMapper, *tableDetails, target.Name)
 		e.log(types.LogLevelInfo, fmt.Sprintf("DDL for %s:\n%s", tableName, createDDL))
 		if err := e.targetConn.ExecuteDDL(ctx, createDDL); err != nil {
 			e.logTableProgress(types.LogLevelError, fmt.Sprintf("Failed to create table %s: %v\nDDL:\n%s", tableName, err, createDDL), tableName, "failed", nil, nil, err.Error())
@@ -259,7 +592,11 @@ func (e *Engine) migrateSchema(ctx context.Context, tables []types.TableInfo) er
 			continue
 		}
 
-		// Create indexes
+		// Create indexes（延後建立時只保留 CREATE TABLE 內的主鍵，次要索引於資料載入後建立）
+		if e.config.DeferIndexes && len(tableDetails.Indexes) > 0 {
+			e.log(types.LogLevelInfo, fmt.Sprintf("Deferring %d indexes of %s until after the data load", len(tableDetails.Indexes), tableName))
+			tableDetails.Indexes = nil
+		}
 		for _, idx := range tableDetails.Indexes {
 			indexDDL := e.typeMapper.GenerateIndexDDL(*tableDetails, idx)
 			if err := e.targetConn.ExecuteDDL(ctx, indexDDL); err != nil {
@@ -267,7 +604,8 @@ func (e *Engine) migrateSchema(ctx context.Context, tables []types.TableInfo) er
 			}
 		}
 
-		e.log(types.LogLevelInfo, fmt.Sprintf("Created table %s.%s", table.Schema, table.Name))
+		e.log(types.LogLevelInfo, fmt.Sprintf("Created table %s.%s", target.Schema, target.Name))
+		e.saveCheckpoint(table, 0, types.CheckpointPhaseSchema, nil, nil, 0)
 
 		// Log type mapper warnings
 		for _, warn := range e.typeMapper.GetWarnings() {
@@ -279,45 +617,117 @@ func (e *Engine) migrateSchema(ctx context.Context, tables []types.TableInfo) er
 	return nil
 }
 
-// migrateData migrates data for all tables
+// newTypeMapper returns a TypeMapper with the migration's time policy, type mapping rules and naming,
+// for workers that need their own warnings
+func (e *Engine) newTypeMapper() *converter.TypeMapper {
+	tm := converter.NewTypeMapper()
+	tm.SetTimePolicy(e.timePolicy)
+	tm.SetRules(e.typeRules)
+	tm.SetNames(e.names)
+	return tm
+}
+
+// createTableDDL returns the CREATE TABLE statement of a source table under a target name,
+// as UNLOGGED when UnloggedTables is set
+func (e *Engine) createTableDDL(tm *converter.TypeMapper, table types.TableInfo, name string) string {
+	ddl := tm.GenerateCreateTableDDLAs(table, name)
+	if e.config.UnloggedTables {
+		ddl = strings.Replace(ddl, "CREATE TABLE ", "CREATE UNLOGGED TABLE ", 1)
+	}
+	return ddl
+}
+
+// migrateData migrates data for all tables using a pool of ParallelTables workers
 func (e *Engine) migrateData(ctx context.Context, tables []types.TableInfo) error {
-	// Calculate total rows
+	// Calculate total rows（增量同步事先不知道變更筆數，不計總數）
 	var totalRows int64
-	for _, table := range tables {
-		totalRows += table.RowCount
+	if e.config.SyncMode != types.SyncModeDelta {
+		for _, table := range tables {
+			totalRows += table.RowCount
+		}
 	}
 
 	e.mu.Lock()
 	e.state.TotalRows = totalRows
 	e.mu.Unlock()
 
-	// Migrate tables (could be parallelized with e.config.ParallelTables)
-	for _, table := range tables {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
+	workerCount := e.config.ParallelTables
+	if workerCount > len(tables) {
+		workerCount = len(tables)
+	}
 
-		e.checkPaused()
+	// 載入設定只套用在 worker 的 session，不修改伺服器設定；worker 結束時連線關閉即恢復
+	profile := e.bulkLoadProfile()
+	tuned := len(profile.Settings()) > 0 || e.skipForeignKeyChecks()
+	if len(profile.Settings()) > 0 {
+		e.log(types.LogLevelInfo, fmt.Sprintf("Bulk load profile on data sessions: %s", profile))
+	}
+	if e.skipForeignKeyChecks() {
+		e.log(types.LogLevelInfo, "Skipping foreign key checks and user triggers on data sessions: session_replication_role=replica")
+	}
+	if tuned {
+		defer e.log(types.LogLevelInfo, "Bulk load session settings reverted: data sessions closed")
+	}
 
-		if err := e.migrateTableData(ctx, table); err != nil {
-			// migrateTableData 內部的 defer 已經處理了 log 寫入
-			// Continue with other tables
+	// 每個 worker 各自建立來源與目標連線，從 jobs 取出表格依序遷移
+	jobs := make(chan types.TableInfo)
+	var wg sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		w, err := e.openWorker(ctx, i)
+		if err != nil {
+			if i == 0 {
+				close(jobs)
+				return err
+			}
+			// 已有可用 worker 時，以較少的並行數繼續
+			e.log(types.LogLevelWarn, fmt.Sprintf("Running with %d workers: %v", i, err))
+			break
 		}
 
-		e.mu.Lock()
-		e.state.CompletedTables++
-		e.mu.Unlock()
+		wg.Add(1)
+		go func(w *tableWorker) {
+			defer wg.Done()
+			defer w.close()
+
+			migrateTable := e.migrateTableData
+			if e.config.SyncMode == types.SyncModeDelta {
+				migrateTable = e.syncTableDelta
+			}
+
+			for table := range jobs {
+				if err := migrateTable(ctx, w, table); err != nil {
+					// migrateTableData 內部的 defer 已經處理了 log 寫入
+					// Continue with other tables
+				}
 
-		e.updateProgress()
+				e.mu.Lock()
+				e.state.CompletedTables++
+				e.mu.Unlock()
+
+				e.updateProgress()
+			}
+		}(w)
 	}
 
-	return nil
+	// 依序派送表格，暫停時停止派送，取消時不再派送新表格
+dispatch:
+	for _, table := range tables {
+		e.checkPaused(ctx)
+
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- table:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return ctx.Err()
 }
 
 // migrateTableData migrates data for a single table
-func (e *Engine) migrateTableData(ctx context.Context, table types.TableInfo) error {
+func (e *Engine) migrateTableData(ctx context.Context, w *tableWorker, table types.TableInfo) error {
 	tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
 
 	// 狀態追蹤變數
@@ -334,18 +744,44 @@ func (e *Engine) migrateTableData(ctx context.Context, table types.TableInfo) er
 		case "completed":
 			level = types.LogLevelInfo
 			message = fmt.Sprintf("Completed %s: %d rows migrated", tableName, migratedRows)
+			e.mu.Lock()
+			if ts, ok := e.state.Tables[tableName]; ok && ts.QuarantinedRows > 0 {
+				message += fmt.Sprintf(", %d rows quarantined", ts.QuarantinedRows)
+			}
+			if ts, ok := e.state.Tables[tableName]; ok && ts.TextIssues > 0 {
+				message += fmt.Sprintf(", %d text values with undecodable bytes or end-user-defined characters", ts.TextIssues)
+			}
+			e.mu.Unlock()
 		case "failed":
 			level = types.LogLevelError
 			message = fmt.Sprintf("Failed %s: %s", tableName, errorMsg)
+		case "skipped":
+			level = types.LogLevelInfo
+			message = fmt.Sprintf("Skipped %s: completed by the interrupted run (%d rows)", tableName, migratedRows)
 		default:
 			level = types.LogLevelWarn
 			message = fmt.Sprintf("Interrupted %s: %d rows migrated (status: %s)", tableName, migratedRows, status)
 		}
 		e.logTableProgress(level, message, tableName, status, &totalRows, &migratedRows, errorMsg)
+
+		// 失敗或取消時同步更新 TableState，並行遷移時每張表各自保有最終狀態
+		if status == "failed" || status == "cancelled" {
+			e.mu.Lock()
+			if ts, ok := e.state.Tables[tableName]; ok {
+				ts.Status = types.MigrationStatus(status)
+				ts.Error = errorMsg
+				ts.EndTime = time.Now()
+			}
+			e.mu.Unlock()
+		}
+
+		e.mu.Lock()
+		e.state.removeCurrentTable(tableName)
+		e.mu.Unlock()
 	}()
 
 	e.mu.Lock()
-	e.state.CurrentTable = tableName
+	e.state.addCurrentTable(tableName)
 	e.state.Tables[tableName] = &TableState{
 		Name:      table.Name,
 		Schema:    table.Schema,
@@ -355,117 +791,276 @@ func (e *Engine) migrateTableData(ctx context.Context, table types.TableInfo) er
 	}
 	e.mu.Unlock()
 
+	// 續傳時上次已完成的表格直接略過
+	resume := e.resume[tableName]
+	if resume != nil && resume.phase == types.CheckpointPhaseCompleted {
+		status = "skipped"
+		migratedRows = resume.rowsCopied
+		e.mu.Lock()
+		e.state.MigratedRows += migratedRows
+		if ts, ok := e.state.Tables[tableName]; ok {
+			ts.Status = types.MigrationStatusCompleted
+			ts.MigratedRows = migratedRows
+			ts.EndTime = time.Now()
+		}
+		e.mu.Unlock()
+		return nil
+	}
+
 	// Get table details for column info
-	tableDetails, err := e.sourceConn.GetTableDetails(ctx, table.Schema, table.Name)
+	tableDetails, err := w.sourceConn.GetTableDetails(ctx, table.Schema, table.Name)
 	if err != nil {
 		status = "failed"
 		errorMsg = err.Error()
 		return err
 	}
 
-	// Prepare column list
-	var columns []string
-	var orderByCol string
-	for _, col := range tableDetails.Columns {
-		columns = append(columns, fmt.Sprintf("[%s]", col.Name))
-		if col.IsPrimaryKey && orderByCol == "" {
-			orderByCol = fmt.Sprintf("[%s]", col.Name)
-		}
+	// 決定分頁策略：主鍵或唯一索引用 keyset，都沒有時單次串流讀取，並記錄於表格結果
+	plan := planTableRead(tableDetails)
+	e.applyValuePolicies(table, plan)
+	// COPY 欄位清單與所有目標端操作皆使用命名方式對應的目標名稱
+	pgColumns := e.applyNaming(tableDetails, plan)
+	target := e.names.TargetTable(*tableDetails)
+	switch plan.strategy {
+	case ReadStrategyUniqueIndex:
+		e.log(types.LogLevelInfo, fmt.Sprintf("%s has no primary key, paging by unique index %s", tableName, plan.keyIndex))
+	case ReadStrategySinglePass:
+		e.log(types.LogLevelWarn, fmt.Sprintf("%s has no primary key or unique index, reading it in a single pass (an interrupted copy restarts from scratch)", tableName))
 	}
 
-	// Default order by first column if no primary key
-	if orderByCol == "" && len(columns) > 0 {
-		orderByCol = columns[0]
+	e.mu.Lock()
+	if ts, ok := e.state.Tables[tableName]; ok {
+		ts.ReadStrategy = plan.strategy
 	}
-
-	// ========== 停用觸發器 ==========
-	// 停用目標表的觸發器，避免插入時觸發額外邏輯，提升效能
-	if err := e.targetConn.DisableTriggers(ctx, table.Schema, table.Name); err != nil {
-		e.log(types.LogLevelWarn, fmt.Sprintf("Failed to disable triggers for %s: %v", tableName, err))
+	e.mu.Unlock()
+	if err := e.storage.SetTableReadStrategy(e.migrationID, table.Schema, table.Name, plan.strategy); err != nil {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Failed to record the read strategy of %s: %v", tableName, err))
 	}
 
-	// ========== 批次遷移迴圈 ==========
-	offset := 0 // 目前讀取的偏移位置
-
-	// 無限迴圈，直到來源資料讀完為止
-	for {
-		// 檢查是否已取消遷移
-		select {
-		case <-ctx.Done():
-			status = "cancelled"
-			errorMsg = ctx.Err().Error()
-			return ctx.Err()
-		default:
+	// ========== 暫存表載入 ==========
+	// 載入至隱藏的暫存表並建立索引，完成後在單一交易內換成正式表格
+	// 查詢端只會看到舊資料或完整的新資料；失敗時正式表格不受影響
+	var staging string
+	if e.config.StagingSwap {
+		if resume != nil && resume.phase != types.CheckpointPhaseSchema {
+			e.log(types.LogLevelInfo, fmt.Sprintf("Reloading %s into a fresh staging table", tableName))
 		}
-
-		// 檢查是否已暫停，若暫停則等待恢復
-		e.checkPaused()
-
-		// 從來源資料庫讀取一批資料
-		// 使用 ORDER BY + OFFSET 分頁讀取，每次讀取 BatchSize 筆
-		rows, err := e.sourceConn.ReadBatch(ctx, table.Schema, table.Name, columns, orderByCol, offset, e.config.BatchSize)
+		resume = nil
+		staging, err = e.createStagingTable(ctx, w, tableDetails)
 		if err != nil {
 			status = "failed"
-			errorMsg = fmt.Sprintf("failed to read batch at offset %d: %v", offset, err)
-			return fmt.Errorf("failed to read batch at offset %d: %w", offset, err)
+			errorMsg = err.Error()
+			return err
 		}
+		plan.targetName = staging
+		defer func() {
+			if status != "completed" {
+				// 使用新的 context，取消遷移時也能清除暫存表
+				if err := w.targetConn.DropTableIfExists(context.Background(), target.Schema, staging); err != nil {
+					e.log(types.LogLevelWarn, fmt.Sprintf("Failed to drop staging table %s.%s: %v", target.Schema, staging, err))
+				}
+			}
+		}()
+	}
 
-		// 若無資料則跳出迴圈，表示遷移完成
-		if len(rows) == 0 {
-			break
+	// ========== UNLOGGED 表格續傳 ==========
+	// UNLOGGED 表格在伺服器當機後會被清空，檢查點之前的資料不一定還在，整張表重新載入
+	if e.config.UnloggedTables && staging == "" && resume != nil && resume.phase != types.CheckpointPhaseSchema {
+		unlogged, err := w.targetConn.IsUnlogged(ctx, target.Schema, target.Name)
+		if err != nil {
+			e.log(types.LogLevelWarn, fmt.Sprintf("Failed to check whether %s is UNLOGGED: %v", tableName, err))
+		} else if unlogged {
+			if err := w.targetConn.TruncateTable(ctx, target.Schema, target.Name); err != nil {
+				status = "failed"
+				errorMsg = fmt.Sprintf("failed to truncate UNLOGGED table: %v", err)
+				return err
+			}
+			e.log(types.LogLevelInfo, fmt.Sprintf("Reloading %s from the start: it is still UNLOGGED, so rows copied before the interruption may be lost", tableName))
+			resume = nil
 		}
+	}
 
-		// 準備 PostgreSQL 欄位名稱陣列
-		// PostgreSQL 不使用中括號包裹欄位名，直接使用欄位名稱
-		pgColumns := make([]string, len(tableDetails.Columns))
-		for i, col := range tableDetails.Columns {
-			pgColumns[i] = col.Name
+	// ========== 載入方式 ==========
+	// upsert 依主鍵合併；truncate 在首次複製前清空目標（續傳時由檢查點處理）
+	// 暫存表載入時目標整張替換，不需要載入方式
+	loadMode := e.config.LoadMode
+	if staging != "" {
+		loadMode = types.LoadModeAppend
+	}
+	switch loadMode {
+	case types.LoadModeUpsert:
+		if len(plan.keyColumns) == 0 {
+			status = "failed"
+			errorMsg = "upsert load needs a primary key"
+			return fmt.Errorf("%s: %s", tableName, errorMsg)
 		}
+		e.upsertByKey(tableDetails, plan)
+	case types.LoadModeTruncate:
+		if resume == nil {
+			if err := w.targetConn.TruncateTable(ctx, target.Schema, target.Name); err != nil {
+				status = "failed"
+				errorMsg = fmt.Sprintf("failed to truncate target table: %v", err)
+				return err
+			}
+			e.log(types.LogLevelInfo, fmt.Sprintf("Truncated target table %s", tableName))
+		}
+	}
 
-		// 使用 PostgreSQL COPY 協議批次插入資料
-		// COPY 協議比 INSERT 效率高數倍，適合大量資料遷移
-		_, err = e.targetConn.CopyFrom(ctx, table.Schema, table.Name, pgColumns, rows)
+	// ========== 續傳準備 ==========
+	// 刪除目標端在最後一個檢查點之後提交的資料，從檢查點接續複製
+	var resumeParts []rangePart
+	if resume != nil {
+		startKey, parts, err := e.prepareResume(ctx, w, table, plan, resume)
 		if err != nil {
 			status = "failed"
-			errorMsg = fmt.Sprintf("failed to insert batch at offset %d: %v", offset, err)
-			return fmt.Errorf("failed to insert batch at offset %d: %w", offset, err)
+			errorMsg = fmt.Sprintf("failed to prepare resume: %v", err)
+			return err
+		}
+		plan = plan.withStartKey(startKey)
+		resumeParts = parts
+		migratedRows = resume.rowsCopied
+		if len(parts) > 0 {
+			migratedRows = 0
+			for _, p := range parts {
+				migratedRows += p.rowsCopied
+			}
 		}
 
-		// 更新計數器
-		migratedRows += int64(len(rows)) // 累加已遷移行數
-		offset += len(rows)              // 移動讀取偏移位置
+		e.mu.Lock()
+		e.state.MigratedRows += migratedRows
+		if ts, ok := e.state.Tables[tableName]; ok {
+			ts.MigratedRows = migratedRows
+		}
+		e.mu.Unlock()
+		e.log(types.LogLevelInfo, fmt.Sprintf("Resuming %s after %d rows", tableName, migratedRows))
+	}
+
+	// 記錄複製前的 rowversion，完成後作為增量同步水位
+	rowVersion := e.captureRowVersion(ctx, w, tableDetails, resume)
+	// 記錄複製前的 CT 版本與 CDC LSN，完成後作為持續複寫的起點
+	replStart := e.captureReplicationStart(ctx, w, tableDetails, resume)
+
+	// ========== 停用觸發器 ==========
+	// 停用目標表的觸發器，避免插入時觸發額外邏輯，提升效能（新建的暫存表沒有觸發器）
+	if staging == "" {
+		if err := w.targetConn.DisableTriggers(ctx, target.Schema, target.Name); err != nil {
+			e.log(types.LogLevelWarn, fmt.Sprintf("Failed to disable triggers for %s: %v", tableName, err))
+		}
+	}
+
+	// ========== 串流遷移 ==========
+	// 讀取端 goroutine 分頁讀取來源並送入 channel，COPY 同時從 channel 取出寫入目標
+	// 每次 COPY 提交後更新計數器與進度
+	stats := newPipelineStats()
+	onChunk := func(n int64) {
+		migratedRows += n // 累加已遷移行數
 
 		// 更新內部狀態（執行緒安全）
 		e.mu.Lock()
-		e.state.MigratedRows += int64(len(rows))
+		e.state.MigratedRows += n
 		if ts, ok := e.state.Tables[tableName]; ok {
 			ts.MigratedRows = migratedRows
+			ts.ReadRowsPerSec = stats.ReadRowsPerSec()
+			ts.WriteRowsPerSec = stats.WriteRowsPerSec()
 		}
 		e.mu.Unlock()
 
 		// 發送進度事件給前端，更新 UI 進度條
-		e.emitProgress(tableName, table.RowCount, migratedRows)
+		e.emitProgress(tableName, table.RowCount, migratedRows, stats)
+	}
+
+	// 大表依主鍵範圍切分，每個範圍各自一組讀取端與寫入端並行複製
+	// 續傳時沿用上次的範圍，各範圍的檢查點才對得上
+	ranges := resumeParts
+	if ranges == nil {
+		ranges = e.planKeyRanges(ctx, w, *tableDetails, plan)
+	}
+	e.saveCheckpoint(table, 0, types.CheckpointPhaseData, nil, plan.startKey, migratedRows)
+	if rowVersion != nil || replStart != nil {
+		var rv string
+		if rowVersion != nil {
+			rv = hex.EncodeToString(rowVersion)
+		}
+		if err := e.storage.SetCheckpointStart(e.migrationID, table.Schema, table.Name, rv, encodeReplicationStart(replStart)); err != nil {
+			e.log(types.LogLevelWarn, fmt.Sprintf("Failed to save checkpoint for %s: %v", tableName, err))
+		}
+	}
+	if len(ranges) > 1 {
+		_, err = e.copyTableRanges(ctx, w, table, plan, pgColumns, ranges, stats, onChunk)
+	} else {
+		_, err = e.copyTableRows(ctx, w, table, plan, pgColumns, stats, func(n int64, lastRow []interface{}) {
+			onChunk(n)
+			// 無主鍵時無法定位中斷位置，只記錄行數，續傳時從頭複製
+			var lastKey []interface{}
+			if plan.keyset() {
+				lastKey = plan.lastKey(lastRow)
+			}
+			e.saveCheckpoint(table, 0, types.CheckpointPhaseData, nil, lastKey, migratedRows)
+		})
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			status = "cancelled"
+			errorMsg = ctx.Err().Error()
+			return ctx.Err()
+		}
+		status = "failed"
+		errorMsg = err.Error()
+		return err
 	}
+	e.log(types.LogLevelInfo, fmt.Sprintf("Throughput %s: read %.0f rows/s, write %.0f rows/s", tableName, stats.ReadRowsPerSec(), stats.WriteRowsPerSec()))
 
 	// ========== 重新啟用觸發器 ==========
 	// 資料插入完成後，恢復觸發器
-	if err := e.targetConn.EnableTriggers(ctx, table.Schema, table.Name); err != nil {
-		e.log(types.LogLevelWarn, fmt.Sprintf("Failed to enable triggers for %s: %v", tableName, err))
+	if staging == "" {
+		if err := w.targetConn.EnableTriggers(ctx, target.Schema, target.Name); err != nil {
+			e.log(types.LogLevelWarn, fmt.Sprintf("Failed to enable triggers for %s: %v", tableName, err))
+		}
+	}
+
+	// ========== 切換為 LOGGED ==========
+	// 寫入完整 WAL 後表格才能在當機後保留資料並複寫至備援；既有的 LOGGED 表格不受影響
+	schema, name := plan.target(table)
+	if e.config.UnloggedTables {
+		start := time.Now()
+		if err := w.targetConn.SetLogged(ctx, schema, name); err != nil {
+			status = "failed"
+			errorMsg = fmt.Sprintf("failed to switch the table to LOGGED: %v", err)
+			return err
+		}
+		e.log(types.LogLevelInfo, fmt.Sprintf("Switched %s to LOGGED in %s", tableName, time.Since(start).Round(time.Millisecond)))
 	}
 
 	// ========== 同步自增序列 ==========
 	// 對於有 IDENTITY 欄位的表，需要同步 PostgreSQL 的 SEQUENCE
 	// 確保下次 INSERT 時自增值正確（從最大值 + 1 開始）
-	for _, col := range tableDetails.Columns {
+	for _, col := range target.Columns {
 		if col.IsIdentity {
-			if err := e.targetConn.SyncSequence(ctx, table.Schema, table.Name, col.Name); err != nil {
+			if err := w.targetConn.SyncSequence(ctx, schema, name, col.Name); err != nil {
 				e.log(types.LogLevelWarn, fmt.Sprintf("Failed to sync sequence for %s.%s: %v", tableName, col.Name, err))
 			}
 		}
 	}
 
+	// ========== 換成正式表格 ==========
+	if staging != "" {
+		indexRenames := e.buildStagingIndexes(ctx, w, tableDetails, staging)
+		if err := e.swapStagingTable(ctx, w, tableDetails, staging, indexRenames); err != nil {
+			status = "failed"
+			errorMsg = fmt.Sprintf("failed to swap in staging table: %v", err)
+			return err
+		}
+		e.log(types.LogLevelInfo, fmt.Sprintf("Swapped the loaded staging table in as %s", tableName))
+	}
+
+	if rowVersion != nil {
+		e.saveWatermark(tableDetails, rowVersion)
+	}
+	e.saveReplicationStart(tableDetails, replStart)
+
 	// ========== 標記表格遷移完成 ==========
 	status = "completed"
+	e.saveCheckpoint(table, 0, types.CheckpointPhaseCompleted, nil, nil, migratedRows)
 
 	e.mu.Lock()
 	if ts, ok := e.state.Tables[tableName]; ok {
@@ -484,20 +1079,44 @@ func (e *Engine) migrateTableData(ctx context.Context, table types.TableInfo) er
 	return nil
 }
 
-// createForeignKeys creates foreign key constraints
+// createForeignKeys creates foreign key constraints.
+// With ForeignKeysNotValid they are added without scanning the loaded rows and validated afterwards in parallel.
 func (e *Engine) createForeignKeys(ctx context.Context, tables []types.TableInfo) error {
+	var pending []foreignKeyTable
 	for _, table := range tables {
 		tableDetails, err := e.sourceConn.GetTableDetails(ctx, table.Schema, table.Name)
 		if err != nil {
 			continue
 		}
 
-		for _, fk := range tableDetails.ForeignKeys {
+		// 驗證與孤兒資料查詢都在目標端，使用目標名稱
+		target := e.names.TargetTable(*tableDetails)
+		var notValid []types.ForeignKey
+		for i, fk := range tableDetails.ForeignKeys {
 			fkDDL := e.typeMapper.GenerateForeignKeyDDL(*tableDetails, fk)
-			if err := e.targetConn.ExecuteDDL(ctx, fkDDL); err != nil {
+			fk = target.ForeignKeys[i]
+			if e.config.ForeignKeysNotValid {
+				fkDDL += " NOT VALID"
+			}
+			start := time.Now()
+			err := e.targetConn.ExecuteDDL(ctx, fkDDL)
+			if err != nil {
 				e.log(types.LogLevelWarn, fmt.Sprintf("Failed to create foreign key %s: %v", fk.Name, err))
 			}
+			// NOT VALID 建立成功的外鍵待驗證後才記錄結果
+			if err == nil && e.config.ForeignKeysNotValid {
+				notValid = append(notValid, fk)
+				continue
+			}
+			e.recordForeignKey(ctx, &target, fk, err, time.Since(start))
 		}
+		if len(notValid) > 0 {
+			pending = append(pending, foreignKeyTable{table: &target, foreignKeys: notValid})
+		}
+	}
+
+	if len(pending) > 0 {
+		return e.validateForeignKeys(ctx, pending)
 	}
 	return nil
 }
@@ -541,6 +1160,11 @@ func (e *Engine) migrateProgrammableObjects(ctx context.Context) {
 // Pause pauses the migration
 func (e *Engine) Pause() {
 	e.mu.Lock()
+	// 已結束的 migration 不再改為暫停
+	if e.state.Status != types.MigrationStatusRunning && e.state.Status != types.MigrationStatusPaused {
+		e.mu.Unlock()
+		return
+	}
 	e.paused = true
 	e.state.Status = types.MigrationStatusPaused
 	e.mu.Unlock()
@@ -553,10 +1177,11 @@ func (e *Engine) Resume() {
 	e.mu.Lock()
 	e.paused = false
 	e.state.Status = types.MigrationStatusRunning
-	e.mu.Unlock()
-	e.storage.UpdateMigrationStatus(e.migrationID, types.MigrationStatusRunning)
+	// 喚醒所有等待中的 worker，並換上新的 channel 供下次暫停使用
 	close(e.resumeCh)
 	e.resumeCh = make(chan struct{})
+	e.mu.Unlock()
+	e.storage.UpdateMigrationStatus(e.migrationID, types.MigrationStatusRunning)
 	e.log(types.LogLevelInfo, "Migration resumed")
 }
 
@@ -579,14 +1204,18 @@ func (e *Engine) GetStatus() *MigrationState {
 	return e.state
 }
 
-// checkPaused blocks if migration is paused
-func (e *Engine) checkPaused() {
+// checkPaused blocks while the migration is paused or until ctx is cancelled
+func (e *Engine) checkPaused(ctx context.Context) {
 	e.mu.RLock()
 	paused := e.paused
+	resumeCh := e.resumeCh
 	e.mu.RUnlock()
 
 	if paused {
-		<-e.resumeCh
+		select {
+		case <-resumeCh:
+		case <-ctx.Done():
+		}
 	}
 }
 
@@ -684,20 +1313,25 @@ func (e *Engine) emitEvent(eventName string, data interface{}) {
 	runtime.EventsEmit(e.ctx, eventName, data)
 }
 
-// emitProgress emits a progress update
-func (e *Engine) emitProgress(tableName string, totalRows, processedRows int64) {
+// emitProgress emits a progress update; stats may be nil
+func (e *Engine) emitProgress(tableName string, totalRows, processedRows int64, stats *pipelineStats) {
 	percentage := float64(0)
 	if totalRows > 0 {
 		percentage = float64(processedRows) / float64(totalRows) * 100
 	}
 
-	e.emitEvent("migration:progress", map[string]interface{}{
+	data := map[string]interface{}{
 		"migrationId":   e.migrationID,
 		"table":         tableName,
 		"totalRows":     totalRows,
 		"processedRows": processedRows,
 		"percentage":    percentage,
-	})
+	}
+	if stats != nil {
+		data["readRowsPerSec"] = stats.ReadRowsPerSec()
+		data["writeRowsPerSec"] = stats.WriteRowsPerSec()
+	}
+	e.emitEvent("migration:progress", data)
 }
 
 // updateProgress updates overall migration progress
@@ -735,6 +1369,7 @@ func (e *Engine) CreateMigrationRecord(name string) (string, error) {
 		SourceDatabase: e.config.SourceDatabase,
 		TargetDatabase: e.config.TargetDatabase,
 		Config:         string(configJSON),
+		ParentID:       e.config.ParentMigrationID,
 	}
 
 	if err := e.storage.CreateMigration(record); err != nil {
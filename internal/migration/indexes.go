@@ -0,0 +1,149 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"adaru-db-tool/internal/types"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// indexBuildStats counts the outcome of the deferred index builds of all tables
+type indexBuildStats struct {
+	mu     sync.Mutex
+	built  int
+	failed int
+}
+
+// buildDeferredIndexes builds the secondary indexes migrateSchema skipped, once the data is loaded.
+// Tables are processed by IndexParallelism workers, each on its own session with maintenance_work_mem set.
+// Indexes that already exist, e.g. built before the run was interrupted, are skipped.
+func (e *Engine) buildDeferredIndexes(ctx context.Context, tables []types.TableInfo) error {
+	settings := make(map[string]string)
+	if e.config.MaintenanceWorkMemMB > 0 {
+		settings["maintenance_work_mem"] = fmt.Sprintf("%dMB", e.config.MaintenanceWorkMemMB)
+	}
+
+	workerCount := e.config.IndexParallelism
+	if workerCount > len(tables) {
+		workerCount = len(tables)
+	}
+
+	start := time.Now()
+	stats := &indexBuildStats{}
+	jobs := make(chan types.TableInfo)
+	var wg sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for table := range jobs {
+				e.buildTableIndexes(ctx, table, settings, stats)
+			}
+		}()
+	}
+
+dispatch:
+	for _, table := range tables {
+		// 保留的既有表格已有自己的索引
+		if e.keptTables[table.Schema+"."+table.Name] {
+			continue
+		}
+		e.checkPaused(ctx)
+
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- table:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	e.log(types.LogLevelInfo, fmt.Sprintf("Built %d deferred indexes in %s (%d failed)",
+		stats.built, time.Since(start).Round(time.Millisecond), stats.failed))
+	return nil
+}
+
+// buildTableIndexes builds the missing secondary indexes of one table on a single session,
+// recording the duration or the error of each index
+func (e *Engine) buildTableIndexes(ctx context.Context, table types.TableInfo, settings map[string]string, stats *indexBuildStats) {
+	tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+
+	tableDetails, err := e.sourceConn.GetTableDetails(ctx, table.Schema, table.Name)
+	if err != nil {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Failed to get index definitions of %s: %v", tableName, err))
+		return
+	}
+	if len(tableDetails.Indexes) == 0 {
+		return
+	}
+
+	// 建表失敗的表格已記錄過錯誤，不再重複
+	target := e.names.TargetTable(*tableDetails)
+	exists, err := e.targetConn.TableExists(ctx, target.Schema, target.Name)
+	if err != nil || !exists {
+		return
+	}
+	existing, err := e.targetConn.IndexNames(ctx, target.Schema, target.Name)
+	if err != nil {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Failed to list the indexes of %s: %v", tableName, err))
+		return
+	}
+
+	// 並行的 worker 各自使用 TypeMapper
+	tm := e.newTypeMapper()
+	err = e.targetConn.WithSession(ctx, settings, func(conn *pgx.Conn) error {
+		for i, idx := range tableDetails.Indexes {
+			name := target.Indexes[i].Name
+			if existing[name] {
+				continue
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			start := time.Now()
+			_, err := conn.Exec(ctx, tm.GenerateIndexDDL(*tableDetails, idx))
+			elapsed := time.Since(start)
+			if err != nil && ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			build := &types.IndexBuild{
+				MigrationID: e.migrationID,
+				SchemaName:  target.Schema,
+				TableName:   target.Name,
+				IndexName:   name,
+				DurationMs:  elapsed.Milliseconds(),
+			}
+			stats.mu.Lock()
+			if err != nil {
+				build.Error = err.Error()
+				stats.failed++
+			} else {
+				stats.built++
+			}
+			stats.mu.Unlock()
+
+			if err != nil {
+				e.log(types.LogLevelWarn, fmt.Sprintf("Failed to create index %s on %s after %s: %v", name, tableName, elapsed.Round(time.Millisecond), err))
+			} else {
+				e.log(types.LogLevelInfo, fmt.Sprintf("Created index %s on %s in %s", name, tableName, elapsed.Round(time.Millisecond)))
+			}
+			if err := e.storage.SaveIndexBuild(build); err != nil {
+				e.log(types.LogLevelWarn, fmt.Sprintf("Failed to record the build of index %s: %v", name, err))
+			}
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Failed to build the indexes of %s: %v", tableName, err))
+	}
+}
@@ -0,0 +1,125 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"adaru-db-tool/internal/connection"
+	"adaru-db-tool/internal/types"
+)
+
+// clusterIndex returns the index of a target table matching the clustered index of its source table,
+// or an empty string when the table is a heap in SQL Server
+func clusterIndex(table *types.TableInfo) string {
+	for _, idx := range table.Indexes {
+		if idx.IsClustered {
+			return idx.Name
+		}
+	}
+	// 主鍵於 CREATE TABLE 中建立，名稱由 PostgreSQL 自動產生
+	if table.PrimaryKeyClustered && len(table.PrimaryKey) > 0 {
+		return connection.PrimaryKeyName(table.Name)
+	}
+	return ""
+}
+
+// maintenanceStatements returns the post-load maintenance statements of a target table in execution order.
+// CLUSTER rewrites the table, so it runs first and the statistics are collected on the final layout.
+func (e *Engine) maintenanceStatements(table *types.TableInfo, index string) []string {
+	var statements []string
+	if e.config.ClusterTables && index != "" {
+		statements = append(statements, connection.ClusterTableSQL(table.Schema, table.Name, index))
+	}
+	if e.config.VacuumTables {
+		statements = append(statements, connection.VacuumTableSQL(table.Schema, table.Name, e.config.AnalyzeTables))
+	} else if e.config.AnalyzeTables {
+		statements = append(statements, connection.AnalyzeTableSQL(table.Schema, table.Name))
+	}
+	return statements
+}
+
+// maintainTables runs ANALYZE, VACUUM and CLUSTER as configured on the tables whose data was loaded,
+// so the planner has statistics before the first queries hit the target.
+// Tables are processed one at a time since VACUUM and CLUSTER are I/O heavy and CLUSTER locks the table.
+func (e *Engine) maintainTables(ctx context.Context, tables []types.TableInfo) error {
+	var loaded []types.TableInfo
+	e.mu.Lock()
+	for _, table := range tables {
+		if ts, ok := e.state.Tables[table.Schema+"."+table.Name]; ok && ts.Status == types.MigrationStatusCompleted {
+			loaded = append(loaded, table)
+		}
+	}
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		e.state.CurrentTables = nil
+		e.mu.Unlock()
+	}()
+
+	start := time.Now()
+	maintained, failed := 0, 0
+	for i, table := range loaded {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		e.checkPaused(ctx)
+
+		tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+		e.mu.Lock()
+		e.state.CurrentTables = []string{tableName}
+		e.mu.Unlock()
+
+		// 維護陳述式作用於目標表格，使用命名方式對應的目標名稱
+		target := e.names.TargetTable(table)
+		index := ""
+		if e.config.ClusterTables {
+			tableDetails, err := e.sourceConn.GetTableDetails(ctx, table.Schema, table.Name)
+			if err != nil {
+				e.log(types.LogLevelWarn, fmt.Sprintf("Failed to get the clustered index of %s: %v", tableName, err))
+			} else {
+				targetDetails := e.names.TargetTable(*tableDetails)
+				index = clusterIndex(&targetDetails)
+			}
+			if index != "" {
+				// 建立失敗或保留的既有表格可能沒有對應的索引
+				existing, err := e.targetConn.IndexNames(ctx, target.Schema, target.Name)
+				if err != nil || !existing[index] {
+					e.log(types.LogLevelWarn, fmt.Sprintf("Clustered index %s of %s does not exist on the target; CLUSTER skipped", index, tableName))
+					index = ""
+				}
+			}
+		}
+
+		var done []string
+		var tableErr error
+		for _, stmt := range e.maintenanceStatements(&target, index) {
+			stepStart := time.Now()
+			if err := e.targetConn.ExecuteDDL(ctx, stmt); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				tableErr = fmt.Errorf("%s: %w", strings.Fields(stmt)[0], err)
+				break
+			}
+			done = append(done, fmt.Sprintf("%s %s", strings.Fields(stmt)[0], time.Since(stepStart).Round(time.Millisecond)))
+		}
+
+		progress := fmt.Sprintf("(%d/%d)", i+1, len(loaded))
+		if tableErr != nil {
+			failed++
+			e.logTableProgress(types.LogLevelWarn, fmt.Sprintf("Maintenance of %s failed %s: %v", tableName, progress, tableErr), tableName, "", nil, nil, tableErr.Error())
+			continue
+		}
+		if len(done) > 0 {
+			maintained++
+			e.logTableProgress(types.LogLevelInfo, fmt.Sprintf("Maintained %s %s: %s", tableName, progress, strings.Join(done, ", ")), tableName, "", nil, nil, "")
+		}
+	}
+
+	e.log(types.LogLevelInfo, fmt.Sprintf("Maintained %d tables in %s (%d failed)",
+		maintained, time.Since(start).Round(time.Millisecond), failed))
+	return nil
+}
@@ -0,0 +1,70 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	"adaru-db-tool/internal/types"
+)
+
+func TestMaintenanceStatements(t *testing.T) {
+	clusteredPK := &types.TableInfo{Schema: "dbo", Name: "Orders", PrimaryKey: []string{"OrderID"}, PrimaryKeyClustered: true}
+	clusteredIndex := &types.TableInfo{
+		Schema:     "dbo",
+		Name:       "OrderLines",
+		PrimaryKey: []string{"LineID"},
+		Indexes: []types.IndexInfo{
+			{Name: "IX_OrderLines_Product", Columns: []string{"ProductID"}},
+			{Name: "IX_OrderLines_Order", Columns: []string{"OrderID"}, IsClustered: true},
+		},
+	}
+	heap := &types.TableInfo{Schema: "dbo", Name: "AuditLog", PrimaryKey: []string{"ID"}}
+
+	tests := []struct {
+		name   string
+		config types.MigrationConfig
+		table  *types.TableInfo
+		want   []string
+	}{
+		{
+			name:   "analyze only",
+			config: types.MigrationConfig{AnalyzeTables: true},
+			table:  clusteredPK,
+			want:   []string{`ANALYZE "dbo"."Orders"`},
+		},
+		{
+			name:   "vacuum collects statistics in the same pass",
+			config: types.MigrationConfig{AnalyzeTables: true, VacuumTables: true},
+			table:  heap,
+			want:   []string{`VACUUM (ANALYZE) "dbo"."AuditLog"`},
+		},
+		{
+			name:   "cluster on the primary key before analyzing",
+			config: types.MigrationConfig{AnalyzeTables: true, ClusterTables: true},
+			table:  clusteredPK,
+			want:   []string{`CLUSTER "dbo"."Orders" USING "Orders_pkey"`, `ANALYZE "dbo"."Orders"`},
+		},
+		{
+			name:   "cluster on a secondary clustered index",
+			config: types.MigrationConfig{ClusterTables: true, VacuumTables: true},
+			table:  clusteredIndex,
+			want:   []string{`CLUSTER "dbo"."OrderLines" USING "IX_OrderLines_Order"`, `VACUUM "dbo"."OrderLines"`},
+		},
+		{
+			name:   "heap is not clustered",
+			config: types.MigrationConfig{ClusterTables: true},
+			table:  heap,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{config: &tt.config}
+			got := e.maintenanceStatements(tt.table, clusterIndex(tt.table))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("maintenanceStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+
+	"adaru-db-tool/internal/types"
+)
+
+// deferredConstraint is a foreign key that the load order cannot satisfy while rows are copied
+type deferredConstraint struct {
+	table      string // 參照端表格（schema.table）
+	constraint string
+	references string // 被參照的表格（schema.table）
+}
+
+// loadOrder is a table order computed from foreign keys
+type loadOrder struct {
+	tables   []types.TableInfo
+	cycles   [][]string           // 互相參照的表格群組，群組內依載入順序
+	deferred []deferredConstraint // 自我參照，以及循環中被參照表格較晚載入的外鍵
+	external []deferredConstraint // 參照未納入本次遷移之表格的外鍵
+}
+
+// orderByForeignKeys sorts tables so that referenced tables are loaded before the tables referencing them.
+// Tables with no dependency between them keep their given order, and so do the tables of a cycle;
+// the foreign keys that cannot be satisfied because of self-references or cycles are reported as deferred.
+func orderByForeignKeys(tables []types.TableInfo, foreignKeys map[string][]types.ForeignKey) loadOrder {
+	n := len(tables)
+	index := make(map[string]int, n)
+	for i, t := range tables {
+		index[t.Schema+"."+t.Name] = i
+	}
+
+	// parents[i]：表格 i 參照的其他表格（需先載入）
+	var order loadOrder
+	parents := make([][]int, n)
+	for i, t := range tables {
+		fullName := t.Schema + "." + t.Name
+		for _, fk := range foreignKeys[fullName] {
+			ref := fk.ReferencedSchema + "." + fk.ReferencedTable
+			j, ok := index[ref]
+			switch {
+			case !ok:
+				order.external = append(order.external, deferredConstraint{table: fullName, constraint: fk.Name, references: ref})
+			case j == i:
+				order.deferred = append(order.deferred, deferredConstraint{table: fullName, constraint: fk.Name, references: ref})
+			default:
+				parents[i] = append(parents[i], j)
+			}
+		}
+	}
+
+	// 強連通元件即為循環；元件內的表格維持原本順序
+	comp := stronglyConnected(parents)
+	components := 0
+	for _, c := range comp {
+		if c+1 > components {
+			components = c + 1
+		}
+	}
+	members := make([][]int, components)
+	for i := 0; i < n; i++ {
+		members[comp[i]] = append(members[comp[i]], i)
+	}
+
+	// 元件之間依相依關係做拓撲排序，可同時載入的元件取原本順序最前面者
+	pending := make([]int, components)
+	children := make([][]int, components)
+	for i := 0; i < n; i++ {
+		for _, j := range parents[i] {
+			if comp[i] != comp[j] {
+				pending[comp[i]]++
+				children[comp[j]] = append(children[comp[j]], comp[i])
+			}
+		}
+	}
+	done := make([]bool, components)
+	position := make([]int, n)
+	for placed := 0; placed < components; placed++ {
+		next := -1
+		for c := 0; c < components; c++ {
+			if !done[c] && pending[c] == 0 && (next < 0 || members[c][0] < members[next][0]) {
+				next = c
+			}
+		}
+		done[next] = true
+		for _, child := range children[next] {
+			pending[child]--
+		}
+
+		if len(members[next]) > 1 {
+			var cycle []string
+			for _, i := range members[next] {
+				cycle = append(cycle, tables[i].Schema+"."+tables[i].Name)
+			}
+			order.cycles = append(order.cycles, cycle)
+		}
+		for _, i := range members[next] {
+			position[i] = len(order.tables)
+			order.tables = append(order.tables, tables[i])
+		}
+	}
+
+	// 循環內參照較晚載入表格的外鍵，須等資料全部載入後才能成立
+	for i, t := range tables {
+		fullName := t.Schema + "." + t.Name
+		for _, fk := range foreignKeys[fullName] {
+			ref := fk.ReferencedSchema + "." + fk.ReferencedTable
+			if j, ok := index[ref]; ok && j != i && position[j] > position[i] {
+				order.deferred = append(order.deferred, deferredConstraint{table: fullName, constraint: fk.Name, references: ref})
+			}
+		}
+	}
+	return order
+}
+
+// stronglyConnected returns the strongly connected component of each node of a graph (Tarjan's algorithm)
+func stronglyConnected(edges [][]int) []int {
+	n := len(edges)
+	comp := make([]int, n)
+	lowLink := make([]int, n)
+	visitIndex := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range visitIndex {
+		visitIndex[i] = -1
+	}
+
+	var stack []int
+	visited, components := 0, 0
+	var visit func(v int)
+	visit = func(v int) {
+		visitIndex[v] = visited
+		lowLink[v] = visited
+		visited++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range edges[v] {
+			if visitIndex[w] < 0 {
+				visit(w)
+				lowLink[v] = min(lowLink[v], lowLink[w])
+			} else if onStack[w] {
+				lowLink[v] = min(lowLink[v], visitIndex[w])
+			}
+		}
+
+		if lowLink[v] == visitIndex[v] {
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				comp[w] = components
+				if w == v {
+					break
+				}
+			}
+			components++
+		}
+	}
+	for v := 0; v < n; v++ {
+		if visitIndex[v] < 0 {
+			visit(v)
+		}
+	}
+	return comp
+}
+
+// logLoadOrder reports the foreign-key load order, its cycles and the constraints that must be deferred
+func (e *Engine) logLoadOrder(order loadOrder) {
+	e.log(types.LogLevelInfo, fmt.Sprintf("Ordered %d tables by foreign keys: %d cycles, %d constraints deferred until after the load",
+		len(order.tables), len(order.cycles), len(order.deferred)))
+	for _, cycle := range order.cycles {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Foreign key cycle between %s; loading them in this order", strings.Join(cycle, ", ")))
+	}
+	for _, d := range order.deferred {
+		if d.table == d.references {
+			e.log(types.LogLevelInfo, fmt.Sprintf("Foreign key %s of %s references its own table; it is deferred until after the load", d.constraint, d.table))
+			continue
+		}
+		e.log(types.LogLevelWarn, fmt.Sprintf("Foreign key %s of %s references %s, which loads later because of a cycle; it is deferred until after the load", d.constraint, d.table, d.references))
+	}
+	for _, d := range order.external {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Foreign key %s of %s references %s, which is not part of this migration", d.constraint, d.table, d.references))
+	}
+}
@@ -0,0 +1,106 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	"adaru-db-tool/internal/types"
+)
+
+func TestOrderByForeignKeys(t *testing.T) {
+	table := func(name string) types.TableInfo {
+		return types.TableInfo{Schema: "dbo", Name: name}
+	}
+	fk := func(name, references string) types.ForeignKey {
+		return types.ForeignKey{Name: name, ReferencedSchema: "dbo", ReferencedTable: references}
+	}
+
+	tests := []struct {
+		name         string
+		tables       []string
+		foreignKeys  map[string][]types.ForeignKey
+		wantOrder    []string
+		wantCycles   [][]string
+		wantDeferred []string
+		wantExternal []string
+	}{
+		{
+			name:      "no foreign keys keeps the manual order",
+			tables:    []string{"C", "A", "B"},
+			wantOrder: []string{"dbo.C", "dbo.A", "dbo.B"},
+		},
+		{
+			name:   "referenced tables load first",
+			tables: []string{"OrderLines", "Orders", "Customers", "Products"},
+			foreignKeys: map[string][]types.ForeignKey{
+				"dbo.OrderLines": {fk("FK_Lines_Orders", "Orders"), fk("FK_Lines_Products", "Products")},
+				"dbo.Orders":     {fk("FK_Orders_Customers", "Customers")},
+			},
+			wantOrder: []string{"dbo.Customers", "dbo.Orders", "dbo.Products", "dbo.OrderLines"},
+		},
+		{
+			name:   "self-reference is deferred without affecting the order",
+			tables: []string{"Employees", "Departments"},
+			foreignKeys: map[string][]types.ForeignKey{
+				"dbo.Employees": {fk("FK_Employees_Manager", "Employees"), fk("FK_Employees_Departments", "Departments")},
+			},
+			wantOrder:    []string{"dbo.Departments", "dbo.Employees"},
+			wantDeferred: []string{"FK_Employees_Manager"},
+		},
+		{
+			name:   "cycle keeps its manual order and defers the back reference",
+			tables: []string{"Invoices", "Customers", "Regions"},
+			foreignKeys: map[string][]types.ForeignKey{
+				"dbo.Customers": {fk("FK_Customers_LastInvoice", "Invoices"), fk("FK_Customers_Regions", "Regions")},
+				"dbo.Invoices":  {fk("FK_Invoices_Customers", "Customers")},
+			},
+			wantOrder:    []string{"dbo.Regions", "dbo.Invoices", "dbo.Customers"},
+			wantCycles:   [][]string{{"dbo.Invoices", "dbo.Customers"}},
+			wantDeferred: []string{"FK_Invoices_Customers"},
+		},
+		{
+			name:   "tables outside the migration are reported",
+			tables: []string{"Orders"},
+			foreignKeys: map[string][]types.ForeignKey{
+				"dbo.Orders": {fk("FK_Orders_Customers", "Customers")},
+			},
+			wantOrder:    []string{"dbo.Orders"},
+			wantExternal: []string{"FK_Orders_Customers"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tables []types.TableInfo
+			for _, name := range tt.tables {
+				tables = append(tables, table(name))
+			}
+			order := orderByForeignKeys(tables, tt.foreignKeys)
+
+			var gotOrder []string
+			for _, tbl := range order.tables {
+				gotOrder = append(gotOrder, tbl.Schema+"."+tbl.Name)
+			}
+			constraints := func(list []deferredConstraint) []string {
+				var names []string
+				for _, d := range list {
+					names = append(names, d.constraint)
+				}
+				return names
+			}
+
+			if !reflect.DeepEqual(gotOrder, tt.wantOrder) {
+				t.Errorf("order = %v, want %v", gotOrder, tt.wantOrder)
+			}
+			if !reflect.DeepEqual(order.cycles, tt.wantCycles) {
+				t.Errorf("cycles = %v, want %v", order.cycles, tt.wantCycles)
+			}
+			if got := constraints(order.deferred); !reflect.DeepEqual(got, tt.wantDeferred) {
+				t.Errorf("deferred = %v, want %v", got, tt.wantDeferred)
+			}
+			if got := constraints(order.external); !reflect.DeepEqual(got, tt.wantExternal) {
+				t.Errorf("external = %v, want %v", got, tt.wantExternal)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package migration
+
+import (
+	"adaru-db-tool/internal/schema/converter"
+	"adaru-db-tool/internal/types"
+)
+
+// Read strategies used to page through a source table
+const (
+	ReadStrategyKeyset      = "keyset"       // 依完整主鍵 keyset 分頁：WHERE (pk1, pk2, ...) > 上一批最後一筆
+	ReadStrategyUniqueIndex = "unique-index" // 無主鍵時依唯一索引（鍵欄位不可為 NULL）keyset 分頁
+	ReadStrategySinglePass  = "single-pass"  // 無主鍵也無唯一索引：單一查詢串流讀出整張表，不分頁
+)
+
+// readPlan describes how the rows of a source table are paged through and written to the target
+type readPlan struct {
+	strategy   string
+	columns    []string                  // 來源欄位的 SELECT 運算式
+	values     *converter.ValueConverter // 將來源值轉為寫入目標的 pgx 值
+	keyColumns []types.ColumnInfo        // keyset 欄位，依主鍵（或唯一索引）順序
+	keyIndexes []int                     // 每個 keyset 欄位在 columns 中的位置
+	keyIndex   string                    // 作為 keyset 的唯一索引名稱（依主鍵分頁時為空）
+	keyRange   *keyRange                 // 範圍切分時只讀取主鍵第一欄落在此範圍的資料
+	startKey   []interface{}             // 續傳時從此主鍵之後開始讀取
+
+	// 增量同步：只讀取 versionFrom <= versionColumn < versionTo 的資料
+	versionColumn string
+	versionFrom   []byte
+	versionTo     []byte
+
+	upsertKeys []string // 非空時以 upsert（依這些目標欄位合併）寫入目標，而非直接 COPY
+
+	// 寫入的目標表格（命名方式或暫存表載入使其與來源不同，空白表示同名）
+	targetSchema string
+	targetName   string
+}
+
+// planTableRead chooses the paging strategy for a table.
+// Keyset paging needs a key that identifies every row: the primary key, or else a unique index
+// with no nullable key column. Without one, OFFSET paging over a non-unique order could skip or
+// repeat rows, so the table is read in a single pass instead.
+func planTableRead(table *types.TableInfo) *readPlan {
+	plan := &readPlan{}
+
+	positions := make(map[string]int)
+	for i, col := range table.Columns {
+		plan.columns = append(plan.columns, converter.SourceExpression(col, ""))
+		positions[col.Name] = i
+	}
+	plan.values = converter.NewValueConverter(table.Columns)
+
+	var key []string
+	switch {
+	case len(table.PrimaryKey) > 0:
+		plan.strategy = ReadStrategyKeyset
+		key = table.PrimaryKey
+	case table.UniqueKey != nil:
+		plan.strategy = ReadStrategyUniqueIndex
+		plan.keyIndex = table.UniqueKey.Name
+		key = table.UniqueKey.Columns
+	default:
+		plan.strategy = ReadStrategySinglePass
+		return plan
+	}
+
+	for _, name := range key {
+		idx := positions[name]
+		plan.keyColumns = append(plan.keyColumns, table.Columns[idx])
+		plan.keyIndexes = append(plan.keyIndexes, idx)
+	}
+	return plan
+}
+
+// applyValuePolicies makes the plan convert values by the configured policies: datetimes by the time policy,
+// columns matched by a type mapping rule to the rule's target type, and non-Unicode strings by the
+// undecodable text policy, reporting the rows whose strings held undecodable bytes or end-user-defined characters
+func (e *Engine) applyValuePolicies(table types.TableInfo, plan *readPlan) {
+	plan.values = plan.values.
+		WithTimePolicy(e.timePolicy).
+		WithRules(e.typeRules, table.Schema, table.Name).
+		WithText(e.config.UndecodableText, e.textReporter(table, plan.keyColumns, plan.keyIndexes))
+}
+
+// applyNaming makes the plan write to the target table of a source table under the naming policy.
+// It returns the target names of the table's columns, in source order, for the COPY column list.
+func (e *Engine) applyNaming(table *types.TableInfo, plan *readPlan) []string {
+	target := e.names.TargetTable(*table)
+	plan.targetSchema = target.Schema
+	plan.targetName = target.Name
+
+	columns := make([]string, len(target.Columns))
+	for i, col := range target.Columns {
+		columns[i] = col.Name
+	}
+	return columns
+}
+
+// upsertByKey makes the plan merge rows into the target by the target names of its keyset columns
+func (e *Engine) upsertByKey(table *types.TableInfo, plan *readPlan) {
+	for _, key := range plan.keyColumns {
+		plan.upsertKeys = append(plan.upsertKeys, e.names.Column(table.Schema, table.Name, key.Name))
+	}
+}
+
+// keyset reports whether the plan pages by a unique key, so a read can continue after any committed row
+func (p *readPlan) keyset() bool {
+	return len(p.keyColumns) > 0
+}
+
+// lastKey extracts the keyset values of a row read with this plan
+func (p *readPlan) lastKey(row []interface{}) []interface{} {
+	key := make([]interface{}, len(p.keyIndexes))
+	for i, idx := range p.keyIndexes {
+		key[i] = row[idx]
+	}
+	return key
+}
+
+// targetKey converts keyset values read from the source into the values written to the target.
+// The source values stay as read for the SQL Server seek, e.g. the raw bytes of a legacy code-page
+// string, but the target compares decoded text. key may hold only the leading key columns.
+func (p *readPlan) targetKey(key []interface{}) ([]interface{}, error) {
+	if key == nil || p.values == nil {
+		return key, nil
+	}
+	row := make([]interface{}, len(p.columns))
+	for i, idx := range p.keyIndexes[:len(key)] {
+		row[idx] = key[i]
+	}
+	converted, err := p.values.Quiet().Row(row)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(key))
+	for i, idx := range p.keyIndexes[:len(key)] {
+		out[i] = converted[idx]
+	}
+	return out, nil
+}
+
+// reportKey returns the keyset values of a row for reports, decoded as on the target when possible
+func (p *readPlan) reportKey(row []interface{}) []interface{} {
+	key := p.lastKey(row)
+	if decoded, err := p.targetKey(key); err == nil {
+		return decoded
+	}
+	return key
+}
+
+// target returns the schema and name of the target table the rows of a source table are written to
+func (p *readPlan) target(table types.TableInfo) (string, string) {
+	schema, name := table.Schema, table.Name
+	if p.targetSchema != "" {
+		schema = p.targetSchema
+	}
+	if p.targetName != "" {
+		name = p.targetName
+	}
+	return schema, name
+}
+
+// withRange returns a copy of the plan restricted to one key range
+func (p *readPlan) withRange(r keyRange) *readPlan {
+	ranged := *p
+	ranged.keyRange = &r
+	return &ranged
+}
+
+// withStartKey returns a copy of the plan that continues after key
+func (p *readPlan) withStartKey(key []interface{}) *readPlan {
+	resumed := *p
+	resumed.startKey = key
+	return &resumed
+}
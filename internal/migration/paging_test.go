@@ -0,0 +1,106 @@
+package migration
+
+import (
+	"testing"
+
+	"adaru-db-tool/internal/types"
+)
+
+func TestPlanTableRead(t *testing.T) {
+	columns := []types.ColumnInfo{{Name: "code"}, {Name: "id"}, {Name: "region"}, {Name: "note"}}
+
+	tests := []struct {
+		name         string
+		table        types.TableInfo
+		wantStrategy string
+		wantKey      []string
+		wantIndexes  []int
+	}{
+		{
+			name:         "primary key",
+			table:        types.TableInfo{Columns: columns, PrimaryKey: []string{"id"}},
+			wantStrategy: ReadStrategyKeyset,
+			wantKey:      []string{"id"},
+			wantIndexes:  []int{1},
+		},
+		{
+			name: "primary key wins over unique index",
+			table: types.TableInfo{Columns: columns, PrimaryKey: []string{"id"},
+				UniqueKey: &types.IndexInfo{Name: "ux_code", Columns: []string{"code"}}},
+			wantStrategy: ReadStrategyKeyset,
+			wantKey:      []string{"id"},
+			wantIndexes:  []int{1},
+		},
+		{
+			// 唯一索引的欄位順序依索引定義，不依表格欄位順序
+			name: "unique index",
+			table: types.TableInfo{Columns: columns,
+				UniqueKey: &types.IndexInfo{Name: "ux_region_code", Columns: []string{"region", "code"}}},
+			wantStrategy: ReadStrategyUniqueIndex,
+			wantKey:      []string{"region", "code"},
+			wantIndexes:  []int{2, 0},
+		},
+		{
+			name:         "heap without unique index",
+			table:        types.TableInfo{Columns: columns},
+			wantStrategy: ReadStrategySinglePass,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planTableRead(&tt.table)
+			if plan.strategy != tt.wantStrategy {
+				t.Fatalf("strategy = %q, want %q", plan.strategy, tt.wantStrategy)
+			}
+			if plan.keyset() != (len(tt.wantKey) > 0) {
+				t.Fatalf("keyset() = %v, want %v", plan.keyset(), len(tt.wantKey) > 0)
+			}
+			if len(plan.keyColumns) != len(tt.wantKey) {
+				t.Fatalf("key columns = %v, want %v", plan.keyColumns, tt.wantKey)
+			}
+			for i, name := range tt.wantKey {
+				if plan.keyColumns[i].Name != name || plan.keyIndexes[i] != tt.wantIndexes[i] {
+					t.Errorf("key column %d = %s at %d, want %s at %d",
+						i, plan.keyColumns[i].Name, plan.keyIndexes[i], name, tt.wantIndexes[i])
+				}
+			}
+			if len(plan.columns) != len(columns) {
+				t.Errorf("columns = %v, want all %d columns", plan.columns, len(columns))
+			}
+		})
+	}
+}
+
+func TestReadPlan_TargetKey(t *testing.T) {
+	table := types.TableInfo{
+		Columns: []types.ColumnInfo{
+			{Name: "note", DataType: "nvarchar"},
+			{Name: "code", DataType: "varchar", CodePage: 950},
+			{Name: "id", DataType: "int"},
+		},
+		PrimaryKey: []string{"code", "id"},
+	}
+	plan := planTableRead(&table)
+
+	// 舊字碼頁字串以原始位元組讀取（Big5 的「中」），目標端比較解碼後的文字
+	raw := []interface{}{[]byte{0xA4, 0xA4}, int64(7)}
+	got, err := plan.targetKey(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0] != "中" || got[1] != int64(7) {
+		t.Errorf("targetKey() = %#v, want [中 7]", got)
+	}
+	if _, ok := raw[0].([]byte); !ok {
+		t.Error("targetKey() modified the source key")
+	}
+
+	lead, err := plan.targetKey([]interface{}{[]byte{0xA4, 0xA4}})
+	if err != nil || len(lead) != 1 || lead[0] != "中" {
+		t.Errorf("targetKey(leading column) = %#v, %v", lead, err)
+	}
+	if got := plan.reportKey([]interface{}{"x", []byte{0xA4, 0xA4}, int64(7)}); got[0] != "中" {
+		t.Errorf("reportKey() = %#v", got)
+	}
+}
@@ -0,0 +1,249 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"adaru-db-tool/internal/connection"
+	"adaru-db-tool/internal/schema/converter"
+	"adaru-db-tool/internal/types"
+)
+
+// pipelineBufferRows bounds the rows buffered between the source reader and COPY.
+// 讀取端最多領先寫入端這麼多筆，channel 滿了讀取端就會阻塞（backpressure），記憶體用量因此有上限
+const pipelineBufferRows = 5000
+
+// pipelineStats measures both sides of one or more parallel copy pipelines.
+// Wait time is time a side spent blocked on the other one, so rows per
+// (elapsed - wait) second is the rate that side could sustain on its own.
+type pipelineStats struct {
+	started   time.Time
+	lanes     atomic.Int64 // 共用此統計的 pipeline 數（範圍切分時大於 1）
+	readRows  atomic.Int64
+	readWait  atomic.Int64 // 讀取端等待寫入端（channel 已滿）的時間（ns）
+	writeRows atomic.Int64
+	writeWait atomic.Int64 // 寫入端等待讀取端（channel 為空）的時間（ns）
+}
+
+func newPipelineStats() *pipelineStats {
+	return &pipelineStats{started: time.Now()}
+}
+
+// ReadRowsPerSec returns the combined source read rate excluding time blocked on the writer
+func (s *pipelineStats) ReadRowsPerSec() float64 {
+	return s.rowsPerSec(s.readRows.Load(), s.readWait.Load())
+}
+
+// WriteRowsPerSec returns the combined target write rate excluding time blocked on the reader
+func (s *pipelineStats) WriteRowsPerSec() float64 {
+	return s.rowsPerSec(s.writeRows.Load(), s.writeWait.Load())
+}
+
+// rowsPerSec divides rows by the average busy time of one lane and scales by the lane count
+func (s *pipelineStats) rowsPerSec(rows, waitNanos int64) float64 {
+	lanes := s.lanes.Load()
+	if lanes < 1 {
+		lanes = 1
+	}
+	busy := time.Since(s.started)*time.Duration(lanes) - time.Duration(waitNanos)
+	if busy <= 0 {
+		return 0
+	}
+	return float64(rows) * float64(lanes) / busy.Seconds()
+}
+
+// chunkSource feeds at most limit rows from the pipeline channel into one COPY.
+// It implements pgx.CopyFromSource.
+type chunkSource struct {
+	rows    <-chan []interface{}
+	limit   int
+	values  *converter.ValueConverter // 為 nil 時原樣寫入
+	stats   *pipelineStats
+	readErr *error
+
+	count   int
+	current []interface{}
+	done    bool // channel 已關閉，來源資料讀完（或讀取失敗）
+
+	keep bool            // 保留本批已送出的資料列，寫入失敗時才能拆批找出問題資料列
+	kept [][]interface{} // keep 為 true 時本批已送出的資料列
+}
+
+// Next advances to the next row; it returns false at the end of the chunk or of the stream
+func (s *chunkSource) Next() bool {
+	if s.count >= s.limit {
+		return false
+	}
+
+	start := time.Now()
+	row, ok := <-s.rows
+	s.stats.writeWait.Add(int64(time.Since(start)))
+	if !ok {
+		s.done = true
+		return false
+	}
+
+	s.current = row
+	s.count++
+	if s.keep {
+		s.kept = append(s.kept, row)
+	}
+	return true
+}
+
+// Values returns the current row converted for the target; current keeps the source values for checkpoints
+func (s *chunkSource) Values() ([]interface{}, error) {
+	if s.values == nil {
+		return s.current, nil
+	}
+	return s.values.Row(s.current)
+}
+
+// Err reports a reader failure so COPY aborts instead of committing a partial chunk
+func (s *chunkSource) Err() error {
+	if s.done {
+		return *s.readErr
+	}
+	return nil
+}
+
+// copyTableRows streams a table from the source into the target.
+// A reader goroutine pages through the source and pushes rows into a bounded channel
+// while COPY drains it, so source reads and target writes overlap.
+// Each COPY commits at most BatchSize rows and onChunk is called after every commit
+// with the last row committed, so callers can checkpoint its key.
+// With QuarantineBadRows, a chunk rejected because of bad values is bisected and
+// the rows that fail on their own are quarantined instead of failing the table.
+func (e *Engine) copyTableRows(ctx context.Context, w *tableWorker, table types.TableInfo, plan *readPlan, pgColumns []string, stats *pipelineStats, onChunk func(rows int64, lastRow []interface{})) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stats.lanes.Add(1)
+	bufferSize := pipelineBufferRows
+	if e.config.BatchSize < bufferSize {
+		bufferSize = e.config.BatchSize
+	}
+	rowsCh := make(chan []interface{}, bufferSize)
+
+	// readErr 在關閉 rowsCh 之前寫入，寫入端看到 channel 關閉後即可安全讀取
+	var readErr error
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		defer close(rowsCh)
+		readErr = e.readTableRows(ctx, w, table, plan, rowsCh, stats)
+	}()
+
+	var copied int64
+	for {
+		src := &chunkSource{rows: rowsCh, limit: e.config.BatchSize, values: plan.values, stats: stats, readErr: &readErr, keep: e.config.QuarantineBadRows}
+		var n int64
+		var err error
+		schema, name := plan.target(table)
+		if len(plan.upsertKeys) > 0 {
+			n, err = w.targetConn.CopyUpsert(ctx, schema, name, pgColumns, plan.upsertKeys, src)
+		} else {
+			n, err = w.targetConn.CopyFromSource(ctx, schema, name, pgColumns, src)
+		}
+		// 資料值造成的失敗：拆批重寫，只隔離問題資料列（本批未送出的資料留在 channel，由下一批處理）
+		salvaged := false
+		if err != nil && src.keep && isRowError(err) && ctx.Err() == nil {
+			n, err = e.salvageRows(ctx, w, table, plan, pgColumns, src.kept, err)
+			salvaged = err == nil
+		}
+		if err != nil {
+			// 停止讀取端並等待其結束，區分是來源讀取失敗還是目標寫入失敗
+			cancel()
+			<-readDone
+			if readErr != nil && !errors.Is(readErr, context.Canceled) {
+				return copied, readErr
+			}
+			return copied, fmt.Errorf("failed to insert batch after %d rows: %w", copied, err)
+		}
+
+		copied += n
+		stats.writeRows.Add(n)
+		if n > 0 || salvaged {
+			onChunk(n, src.current)
+		}
+		if src.done {
+			break
+		}
+	}
+
+	<-readDone
+	return copied, readErr
+}
+
+// readTableRows pages through the source table and sends every row to out
+func (e *Engine) readTableRows(ctx context.Context, w *tableWorker, table types.TableInfo, plan *readPlan, out chan<- []interface{}, stats *pipelineStats) error {
+	read := 0
+	lastKey := plan.startKey
+
+	for {
+		// 暫停時停在頁與頁之間，寫入端會因 channel 清空而自然等待
+		e.checkPaused(ctx)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		q := connection.BatchQuery{
+			Schema:  table.Schema,
+			Table:   table.Name,
+			Columns: plan.columns,
+			Limit:   e.config.BatchSize,
+		}
+		if plan.strategy == ReadStrategySinglePass {
+			q.Limit = 0
+		} else if plan.keyset() {
+			q.KeyColumns = plan.keyColumns
+			q.LastKey = lastKey
+			if plan.keyRange != nil {
+				q.RangeColumn = plan.keyColumns[0].Name
+				q.RangeFrom = plan.keyRange.From
+				q.RangeTo = plan.keyRange.To
+			}
+			q.VersionColumn = plan.versionColumn
+			q.VersionFrom = plan.versionFrom
+			q.VersionTo = plan.versionTo
+		}
+
+		var last []interface{}
+		n, err := w.sourceConn.StreamBatch(ctx, q, func(row []interface{}) error {
+			last = row
+			// 單次讀取沒有分頁之間的空檔，改為每 BatchSize 筆檢查一次暫停
+			if q.Limit == 0 && read > 0 && read%e.config.BatchSize == 0 {
+				e.checkPaused(ctx)
+			}
+			read++
+			if err := e.throttle.wait(ctx, 1, rowBytes(row)); err != nil {
+				return err
+			}
+			start := time.Now()
+			select {
+			case out <- row:
+				stats.readWait.Add(int64(time.Since(start)))
+				stats.readRows.Add(1)
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to read batch after %d rows: %w", read, err)
+		}
+
+		if q.Limit == 0 || n < e.config.BatchSize {
+			return nil
+		}
+		if plan.keyset() {
+			lastKey = plan.lastKey(last) // 記住本頁最後一筆主鍵，下一頁從其後開始
+		}
+	}
+}
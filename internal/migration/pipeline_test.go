@@ -0,0 +1,60 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChunkSource_StopsAtLimit(t *testing.T) {
+	rows := make(chan []interface{}, 5)
+	for i := 0; i < 5; i++ {
+		rows <- []interface{}{i}
+	}
+	close(rows)
+
+	var readErr error
+	stats := newPipelineStats()
+
+	// 每個 chunk 最多 2 筆：2 + 2 + 1，最後一個 chunk 看到 channel 關閉
+	var sizes []int
+	for {
+		src := &chunkSource{rows: rows, limit: 2, stats: stats, readErr: &readErr}
+		for src.Next() {
+		}
+		sizes = append(sizes, src.count)
+		if src.done {
+			break
+		}
+	}
+
+	want := []int{2, 2, 1}
+	if len(sizes) != len(want) {
+		t.Fatalf("chunk sizes = %v, want %v", sizes, want)
+	}
+	for i := range want {
+		if sizes[i] != want[i] {
+			t.Fatalf("chunk sizes = %v, want %v", sizes, want)
+		}
+	}
+}
+
+func TestChunkSource_ReportsReaderError(t *testing.T) {
+	rows := make(chan []interface{}, 1)
+	rows <- []interface{}{1}
+	readErr := errors.New("source read failed")
+	close(rows)
+
+	src := &chunkSource{rows: rows, limit: 10, stats: newPipelineStats(), readErr: &readErr}
+	if !src.Next() {
+		t.Fatal("expected first row")
+	}
+	if err := src.Err(); err != nil {
+		t.Fatalf("Err() before end of stream = %v, want nil", err)
+	}
+	if src.Next() {
+		t.Fatal("expected end of stream")
+	}
+	if err := src.Err(); err != readErr {
+		t.Fatalf("Err() = %v, want %v", err, readErr)
+	}
+}
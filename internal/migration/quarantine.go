@@ -0,0 +1,148 @@
+package migration
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"adaru-db-tool/internal/schema/converter"
+	"adaru-db-tool/internal/types"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// isRowError reports whether a write failed because of the values of some row,
+// as opposed to the connection or the target table, so bisecting the batch can isolate it
+func isRowError(err error) bool {
+	var valueErr *converter.ValueError
+	if errors.As(err, &valueErr) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	// 22：資料例外（字串含 NUL、日期超出範圍、數值溢位等）；23：違反完整性限制（重複主鍵、NOT NULL 等）
+	return strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")
+}
+
+// quarantineValue converts a source value into a readable JSON value
+func quarantineValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []byte:
+		return "0x" + hex.EncodeToString(val)
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	default:
+		return val
+	}
+}
+
+// quarantineJSON encodes values as a JSON object keyed by column name
+func quarantineJSON(columns []string, values []interface{}) string {
+	obj := make(map[string]interface{}, len(columns))
+	for i, col := range columns {
+		obj[col] = quarantineValue(values[i])
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Sprintf("%v", values)
+	}
+	return string(data)
+}
+
+// writeRows writes rows already read from the source in one COPY (or upsert)
+func (e *Engine) writeRows(ctx context.Context, w *tableWorker, table types.TableInfo, plan *readPlan, pgColumns []string, rows [][]interface{}) (int64, error) {
+	if plan.values != nil {
+		// 拆批重寫的資料列在第一次送出時已回報過字串解碼問題
+		vc := plan.values.Quiet()
+		converted := make([][]interface{}, len(rows))
+		for i, row := range rows {
+			values, err := vc.Row(row)
+			if err != nil {
+				return 0, err
+			}
+			converted[i] = values
+		}
+		rows = converted
+	}
+	src := pgx.CopyFromRows(rows)
+	schema, name := plan.target(table)
+	if len(plan.upsertKeys) > 0 {
+		return w.targetConn.CopyUpsert(ctx, schema, name, pgColumns, plan.upsertKeys, src)
+	}
+	return w.targetConn.CopyFromSource(ctx, schema, name, pgColumns, src)
+}
+
+// salvageRows writes a batch that failed because of bad rows by bisecting it:
+// each half is retried on its own until the rows that fail alone are found and quarantined.
+// It returns the number of rows written.
+func (e *Engine) salvageRows(ctx context.Context, w *tableWorker, table types.TableInfo, plan *readPlan, pgColumns []string, rows [][]interface{}, copyErr error) (int64, error) {
+	if len(rows) == 1 {
+		return 0, e.quarantineRow(table, plan, pgColumns, rows[0], copyErr)
+	}
+
+	var written int64
+	mid := len(rows) / 2
+	for _, half := range [][][]interface{}{rows[:mid], rows[mid:]} {
+		n, err := e.writeRows(ctx, w, table, plan, pgColumns, half)
+		if err == nil {
+			written += n
+			continue
+		}
+		if !isRowError(err) {
+			return written, err
+		}
+		n, err = e.salvageRows(ctx, w, table, plan, pgColumns, half, err)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// quarantineRow stores a row the target rejected and counts it against the table's limit
+func (e *Engine) quarantineRow(table types.TableInfo, plan *readPlan, pgColumns []string, row []interface{}, rowErr error) error {
+	tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+
+	var sourceKey string
+	if plan.keyset() {
+		keyNames := make([]string, len(plan.keyColumns))
+		for i, col := range plan.keyColumns {
+			keyNames[i] = col.Name
+		}
+		sourceKey = quarantineJSON(keyNames, plan.reportKey(row))
+	}
+
+	err := e.storage.AddQuarantinedRow(&types.QuarantinedRow{
+		MigrationID: e.migrationID,
+		SchemaName:  table.Schema,
+		TableName:   table.Name,
+		SourceKey:   sourceKey,
+		RowData:     quarantineJSON(pgColumns, row),
+		Error:       rowErr.Error(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to quarantine row %s: %w", sourceKey, err)
+	}
+
+	e.mu.Lock()
+	var count int64
+	if ts, ok := e.state.Tables[tableName]; ok {
+		ts.QuarantinedRows++
+		count = ts.QuarantinedRows
+	}
+	e.mu.Unlock()
+
+	e.log(types.LogLevelWarn, fmt.Sprintf("Quarantined a row of %s %s: %v", tableName, sourceKey, rowErr))
+	if count > int64(e.config.QuarantineMaxRows) {
+		return fmt.Errorf("more than %d rows quarantined, stopping (last error: %w)", e.config.QuarantineMaxRows, rowErr)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsRowError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"invalid byte sequence", &pgconn.PgError{Code: "22021"}, true},
+		{"datetime out of range", &pgconn.PgError{Code: "22008"}, true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, true},
+		{"wrapped not null violation", fmt.Errorf("copy failed: %w", &pgconn.PgError{Code: "23502"}), true},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, false},
+		{"undefined table", &pgconn.PgError{Code: "42P01"}, false},
+		{"not a PostgreSQL error", errors.New("context canceled"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRowError(tt.err); got != tt.want {
+				t.Errorf("isRowError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuarantineJSON(t *testing.T) {
+	at := time.Date(2024, 2, 29, 13, 45, 0, 500, time.UTC)
+	got := quarantineJSON(
+		[]string{"id", "name", "photo", "updated_at", "note"},
+		[]interface{}{int64(7), "a\x00b", []byte{0xde, 0xad}, at, nil},
+	)
+	want := `{"id":7,"name":"a\u0000b","note":null,"photo":"0xdead","updated_at":"2024-02-29T13:45:00.0000005Z"}`
+	if got != want {
+		t.Errorf("quarantineJSON() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"adaru-db-tool/internal/types"
+)
+
+// keyRange is a half-open range [From, To) of the leading primary key column; nil bounds are open
+type keyRange struct {
+	From interface{}
+	To   interface{}
+}
+
+// String formats the range for logs and progress
+func (r keyRange) String() string {
+	from, to := "-inf", "+inf"
+	if r.From != nil {
+		from = fmt.Sprintf("%v", r.From)
+	}
+	if r.To != nil {
+		to = fmt.Sprintf("%v", r.To)
+	}
+	return fmt.Sprintf("[%s, %s)", from, to)
+}
+
+// rangePart is one key range of a split table together with its checkpointed progress.
+// Parts are numbered from 1; part 0 is the checkpoint of an unsplit table.
+type rangePart struct {
+	part       int
+	keyRange   keyRange
+	startKey   []interface{} // 續傳時從此主鍵之後開始
+	rowsCopied int64
+	done       bool
+}
+
+// RangeState tracks the progress of one key range of a split table
+type RangeState struct {
+	Range        string
+	Status       types.MigrationStatus
+	MigratedRows int64
+}
+
+// planKeyRanges splits a large table into primary key ranges, or returns nil
+// when the table should be copied by a single reader/writer pair
+func (e *Engine) planKeyRanges(ctx context.Context, w *tableWorker, table types.TableInfo, plan *readPlan) []rangePart {
+	tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+	if e.config.RangeParallelism <= 1 || table.RowCount < e.config.SplitTableRows {
+		return nil
+	}
+	if !plan.keyset() {
+		e.log(types.LogLevelInfo, fmt.Sprintf("%s not split into ranges: no primary key or unique index", tableName))
+		return nil
+	}
+
+	// 只支援主鍵第一欄為整數型別的表格，範圍條件才能用單一欄位的 >= / < 表示
+	lead := plan.keyColumns[0]
+	switch strings.ToLower(lead.DataType) {
+	case "bigint", "int", "smallint", "tinyint":
+	default:
+		e.log(types.LogLevelInfo, fmt.Sprintf("%s not split into ranges: leading key column %s is %s, not an integer", tableName, lead.Name, lead.DataType))
+		return nil
+	}
+
+	bounds, method, err := w.sourceConn.GetKeyBoundaries(ctx, table.Schema, table.Name, lead.Name, e.config.RangeParallelism)
+	if err != nil {
+		e.log(types.LogLevelWarn, fmt.Sprintf("%s not split into ranges: %v", tableName, err))
+		return nil
+	}
+	if len(bounds) == 0 {
+		return nil
+	}
+
+	ranges := make([]rangePart, 0, len(bounds)+1)
+	var from interface{}
+	for _, b := range bounds {
+		ranges = append(ranges, rangePart{part: len(ranges) + 1, keyRange: keyRange{From: from, To: b}})
+		from = b
+	}
+	ranges = append(ranges, rangePart{part: len(ranges) + 1, keyRange: keyRange{From: from}})
+
+	e.log(types.LogLevelInfo, fmt.Sprintf("%s split into %d ranges on %s using %s boundaries", tableName, len(ranges), lead.Name, method))
+	return ranges
+}
+
+// copyTableRanges copies each key range with its own reader/writer pair.
+// The first unfinished range reuses the table's worker; the others open dedicated connections.
+// Ranges already completed by an earlier run are skipped and each range is checkpointed after every chunk.
+// onChunk is serialized, so callers may update per-table counters without locking.
+func (e *Engine) copyTableRanges(ctx context.Context, w *tableWorker, table types.TableInfo, plan *readPlan, pgColumns []string, ranges []rangePart, stats *pipelineStats, onChunk func(rows int64)) (int64, error) {
+	tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	states := make([]*RangeState, len(ranges))
+	for i, r := range ranges {
+		states[i] = &RangeState{Range: r.keyRange.String(), Status: types.MigrationStatusPending, MigratedRows: r.rowsCopied}
+		if r.done {
+			states[i].Status = types.MigrationStatusCompleted
+		} else {
+			e.saveCheckpoint(table, r.part, types.CheckpointPhaseData, &r.keyRange, r.startKey, r.rowsCopied)
+		}
+	}
+	e.mu.Lock()
+	if ts, ok := e.state.Tables[tableName]; ok {
+		ts.Ranges = states
+	}
+	e.mu.Unlock()
+
+	var (
+		chunkMu  sync.Mutex
+		copied   int64
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	reuse := true
+	for i, r := range ranges {
+		if r.done {
+			continue
+		}
+		ownWorker := !reuse
+		reuse = false
+
+		wg.Add(1)
+		go func(i int, r rangePart) {
+			defer wg.Done()
+
+			rw := w
+			if ownWorker {
+				var err error
+				rw, err = e.openWorker(ctx, w.id)
+				if err != nil {
+					e.setRangeStatus(states[i], types.MigrationStatusFailed)
+					chunkMu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("range %s: %w", r.keyRange, err)
+					}
+					chunkMu.Unlock()
+					cancel()
+					return
+				}
+				defer rw.close()
+			}
+
+			e.setRangeStatus(states[i], types.MigrationStatusRunning)
+			rows := r.rowsCopied
+			rangePlan := plan.withRange(r.keyRange).withStartKey(r.startKey)
+			_, err := e.copyTableRows(ctx, rw, table, rangePlan, pgColumns, stats, func(n int64, lastRow []interface{}) {
+				rows += n
+				e.saveCheckpoint(table, r.part, types.CheckpointPhaseData, &r.keyRange, plan.lastKey(lastRow), rows)
+
+				chunkMu.Lock()
+				defer chunkMu.Unlock()
+				copied += n
+				e.mu.Lock()
+				states[i].MigratedRows += n
+				e.mu.Unlock()
+				onChunk(n)
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					e.setRangeStatus(states[i], types.MigrationStatusCancelled)
+				} else {
+					e.setRangeStatus(states[i], types.MigrationStatusFailed)
+				}
+				chunkMu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("range %s: %w", r.keyRange, err)
+				}
+				chunkMu.Unlock()
+				// 任一範圍失敗即取消其餘範圍
+				cancel()
+				return
+			}
+			e.saveCheckpoint(table, r.part, types.CheckpointPhaseCompleted, &r.keyRange, nil, rows)
+			e.setRangeStatus(states[i], types.MigrationStatusCompleted)
+		}(i, r)
+	}
+	wg.Wait()
+
+	return copied, firstErr
+}
+
+// setRangeStatus updates the status of a range under the engine lock
+func (e *Engine) setRangeStatus(state *RangeState, status types.MigrationStatus) {
+	e.mu.Lock()
+	state.Status = status
+	e.mu.Unlock()
+}
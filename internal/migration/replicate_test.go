@@ -0,0 +1,38 @@
+package migration
+
+import (
+	"testing"
+
+	"adaru-db-tool/internal/connection"
+)
+
+func TestSortChanges(t *testing.T) {
+	orders := &replicaTable{name: "Orders"}
+	lines := &replicaTable{name: "Lines"}
+	change := func(table *replicaTable, order ...byte) tableChange {
+		return tableChange{table: table, change: connection.SourceChange{Order: order}}
+	}
+
+	// 各表格內已依順序排列，合併後須依提交順序交錯，同順序的變更維持原本先後
+	changes := []tableChange{
+		change(orders, 0, 1),
+		change(orders, 0, 3),
+		change(orders, 0, 3),
+		change(lines, 0, 2),
+		change(lines, 0, 3),
+		change(lines, 1, 0),
+	}
+	sortChanges(changes)
+
+	want := []struct {
+		table *replicaTable
+		order byte
+	}{
+		{orders, 1}, {lines, 2}, {orders, 3}, {orders, 3}, {lines, 3}, {lines, 0},
+	}
+	for i, w := range want {
+		if changes[i].table != w.table || changes[i].change.Order[len(changes[i].change.Order)-1] != w.order {
+			t.Errorf("change %d = %s %v, want %s ..%d", i, changes[i].table.name, changes[i].change.Order, w.table.name, w.order)
+		}
+	}
+}
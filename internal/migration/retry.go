@@ -0,0 +1,41 @@
+package migration
+
+import (
+	"adaru-db-tool/internal/types"
+)
+
+// RetryTables returns the tables of a previous run that need to be migrated again, in the original order.
+// planned lists the tables the run was started with; tables whose last logged status is not
+// completed (failed, cancelled, not possible to delta-sync, still running when the run was
+// interrupted, or never reached) are kept.
+// Tables that only appear in the logs are appended after the planned ones.
+func RetryTables(planned []string, results []types.TableResult) []string {
+	last := make(map[string]string, len(results))
+	for _, r := range results {
+		last[r.TableName] = r.Status
+	}
+
+	done := func(name string) bool {
+		switch last[name] {
+		case string(types.MigrationStatusCompleted), "skipped":
+			return true
+		}
+		return false
+	}
+
+	var tables []string
+	seen := make(map[string]bool, len(planned))
+	for _, name := range planned {
+		seen[name] = true
+		if !done(name) {
+			tables = append(tables, name)
+		}
+	}
+	for _, r := range results {
+		if !seen[r.TableName] && !done(r.TableName) {
+			seen[r.TableName] = true
+			tables = append(tables, r.TableName)
+		}
+	}
+	return tables
+}
@@ -0,0 +1,64 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	"adaru-db-tool/internal/types"
+)
+
+func TestRetryTables(t *testing.T) {
+	tests := []struct {
+		name    string
+		planned []string
+		results []types.TableResult
+		want    []string
+	}{
+		{
+			name:    "keeps failed, cancelled and interrupted tables in order",
+			planned: []string{"dbo.a", "dbo.b", "dbo.c", "dbo.d", "dbo.e"},
+			results: []types.TableResult{
+				{TableName: "dbo.a", Status: "completed"},
+				{TableName: "dbo.b", Status: "failed"},
+				{TableName: "dbo.c", Status: "cancelled"},
+				{TableName: "dbo.d", Status: "running"},
+			},
+			want: []string{"dbo.b", "dbo.c", "dbo.d", "dbo.e"},
+		},
+		{
+			name:    "skipped tables were completed by an earlier attempt",
+			planned: []string{"dbo.a", "dbo.b"},
+			results: []types.TableResult{
+				{TableName: "dbo.a", Status: "skipped"},
+				{TableName: "dbo.b", Status: "completed"},
+			},
+			want: nil,
+		},
+		{
+			name:    "tables a delta sync could not update are retried",
+			planned: []string{"dbo.a", "dbo.b"},
+			results: []types.TableResult{
+				{TableName: "dbo.a", Status: "not-possible"},
+				{TableName: "dbo.b", Status: "completed"},
+			},
+			want: []string{"dbo.a"},
+		},
+		{
+			name:    "tables only known from logs are appended",
+			planned: nil,
+			results: []types.TableResult{
+				{TableName: "dbo.a", Status: "completed"},
+				{TableName: "dbo.b", Status: "failed"},
+			},
+			want: []string{"dbo.b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RetryTables(tt.planned, tt.results); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RetryTables() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"adaru-db-tool/internal/connection"
+	"adaru-db-tool/internal/types"
+)
+
+// scriptFile accumulates the statements of one .sql file of a dry-run script
+type scriptFile struct {
+	name       string
+	sb         strings.Builder
+	statements int
+}
+
+// newScriptFile starts a script file with a header describing when to run it
+func newScriptFile(name, purpose string) *scriptFile {
+	f := &scriptFile{name: name}
+	f.comment(fmt.Sprintf("%s: %s", name, purpose))
+	f.sb.WriteString("\n")
+	return f
+}
+
+// comment writes text as SQL line comments
+func (f *scriptFile) comment(text string) {
+	for _, line := range strings.Split(text, "\n") {
+		f.sb.WriteString("-- " + line + "\n")
+	}
+}
+
+// statement writes one statement, preceded by the type mapping warnings raised while generating it
+func (f *scriptFile) statement(sql string, warnings []string) {
+	for _, warn := range warnings {
+		f.comment("WARNING: " + warn)
+	}
+	f.sb.WriteString(sql + ";\n\n")
+	f.statements++
+}
+
+// generateScript runs the schema pipeline without touching the target and stores the
+// statements it would execute, in execution order, as a DDL script for review.
+// skipped lists the tables left out because they cannot be named on the target.
+func (e *Engine) generateScript(ctx context.Context, tables []types.TableInfo, skipped []string) error {
+	// 獨立的 TypeMapper，每個陳述式產生前清空警告，警告才能對應到各自的陳述式
+	tm := e.newTypeMapper()
+
+	header := fmt.Sprintf("Source: %s (SQL Server) -> Target: %s (PostgreSQL)\nGenerated by dry run %s at %s",
+		e.config.SourceDatabase, e.config.TargetDatabase, e.migrationID, time.Now().Format("2006-01-02 15:04:05"))
+	var files []*scriptFile
+	add := func(name, purpose string) *scriptFile {
+		f := newScriptFile(fmt.Sprintf("%02d_%s.sql", len(files)+1, name), purpose)
+		files = append(files, f)
+		return f
+	}
+	schemaPurpose := "schemas, tables and indexes; run before loading data"
+	if e.config.DeferIndexes {
+		schemaPurpose = "schemas and tables; run before loading data"
+	}
+	schemaFile := add("schema", schemaPurpose)
+	// UNLOGGED 載入與實際遷移相同：每個表格載入後、同步序列前切換為 LOGGED
+	var loggedFile *scriptFile
+	if e.config.UnloggedTables {
+		loggedFile = add("set_logged", "switch the UNLOGGED tables to LOGGED; run after loading data")
+	}
+	sequenceFile := add("sequences", "identity sequence sync; run after loading data")
+	// 延後建立索引時與實際遷移相同：次要索引於資料全部載入後、外鍵之前建立
+	indexFile := schemaFile
+	if e.config.DeferIndexes {
+		indexFile = add("indexes", "secondary indexes; run after loading data, before the foreign keys")
+	}
+	foreignKeyFile := add("foreign_keys", "foreign keys; run after loading data")
+	maintenanceFile := add("maintenance", "statistics and physical order; run after loading data")
+	for _, f := range files {
+		f.comment(header)
+		f.sb.WriteString("\n")
+	}
+	for _, msg := range skipped {
+		schemaFile.comment("SKIPPED " + msg)
+		schemaFile.sb.WriteString("\n")
+	}
+
+	createdSchemas := make(map[string]bool)
+	var scripted []*types.TableInfo
+	for _, table := range tables {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		e.checkPaused(ctx)
+
+		tableName := fmt.Sprintf("%s.%s", table.Schema, table.Name)
+		tableDetails, err := e.sourceConn.GetTableDetails(ctx, table.Schema, table.Name)
+		if err != nil {
+			e.logTableProgress(types.LogLevelError, fmt.Sprintf("Failed to get details for %s: %v", tableName, err), tableName, "failed", nil, nil, err.Error())
+			schemaFile.comment(fmt.Sprintf("SKIPPED %s: failed to read table details: %v", tableName, err))
+			schemaFile.sb.WriteString("\n")
+			continue
+		}
+
+		target := e.names.TargetTable(*tableDetails)
+		if !createdSchemas[target.Schema] {
+			createdSchemas[target.Schema] = true
+			schemaFile.statement(connection.CreateSchemaSQL(target.Schema), nil)
+		}
+		if e.config.DropTargetIfExists {
+			schemaFile.statement(connection.DropTableSQL(target.Schema, target.Name), nil)
+		}
+
+		tm.ClearWarnings()
+		createDDL := e.createTableDDL(tm, *tableDetails, target.Name)
+		warnings := len(tm.GetWarnings())
+		schemaFile.statement(createDDL, tm.GetWarnings())
+
+		for _, idx := range tableDetails.Indexes {
+			tm.ClearWarnings()
+			indexDDL := tm.GenerateIndexDDL(*tableDetails, idx)
+			warnings += len(tm.GetWarnings())
+			indexFile.statement(indexDDL, tm.GetWarnings())
+		}
+
+		if loggedFile != nil {
+			loggedFile.statement(connection.SetLoggedSQL(target.Schema, target.Name), nil)
+		}
+		for _, col := range target.Columns {
+			if col.IsIdentity {
+				sequenceFile.statement(connection.SyncSequenceSQL(target.Schema, target.Name, col.Name), nil)
+			}
+		}
+		scripted = append(scripted, tableDetails)
+
+		e.mu.Lock()
+		e.state.CompletedTables++
+		e.mu.Unlock()
+		e.logTableProgress(types.LogLevelInfo, fmt.Sprintf("Scripted %s (%d type mapping warnings)", tableName, warnings), tableName, "completed", nil, nil, "")
+		e.updateProgress()
+	}
+
+	// 外鍵在所有表格建立且資料載入後才建立，與實際遷移的 Phase 3 相同
+	for _, tableDetails := range scripted {
+		for _, fk := range tableDetails.ForeignKeys {
+			tm.ClearWarnings()
+			fkDDL := tm.GenerateForeignKeyDDL(*tableDetails, fk)
+			if e.config.ForeignKeysNotValid {
+				fkDDL += " NOT VALID"
+			}
+			foreignKeyFile.statement(fkDDL, tm.GetWarnings())
+		}
+	}
+	if e.config.ForeignKeysNotValid {
+		foreignKeyFile.comment("Validate the foreign keys added as NOT VALID; each statement can run in its own session")
+		for _, tableDetails := range scripted {
+			target := e.names.TargetTable(*tableDetails)
+			for _, fk := range target.ForeignKeys {
+				foreignKeyFile.statement(connection.ValidateConstraintSQL(target.Schema, target.Name, fk.Name), nil)
+			}
+		}
+	}
+
+	for _, tableDetails := range scripted {
+		target := e.names.TargetTable(*tableDetails)
+		for _, stmt := range e.maintenanceStatements(&target, clusterIndex(&target)) {
+			maintenanceFile.statement(stmt, nil)
+		}
+	}
+
+	var result []types.ScriptFile
+	statements := 0
+	for _, f := range files {
+		if f.statements == 0 {
+			continue
+		}
+		statements += f.statements
+		result = append(result, types.ScriptFile{Name: f.name, Content: f.sb.String()})
+	}
+	if err := e.storage.SaveDDLScript(e.migrationID, result); err != nil {
+		return fmt.Errorf("failed to save DDL script: %w", err)
+	}
+	e.log(types.LogLevelInfo, fmt.Sprintf("Dry run: generated %d statements in %d files; nothing was executed on the target", statements, len(result)))
+	return nil
+}
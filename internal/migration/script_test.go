@@ -0,0 +1,23 @@
+package migration
+
+import (
+	"testing"
+)
+
+func TestScriptFile_Statement(t *testing.T) {
+	f := newScriptFile("01_schema.sql", "tables")
+	f.statement(`CREATE SCHEMA IF NOT EXISTS "dbo"`, nil)
+	f.statement("CREATE TABLE \"dbo\".\"T\" (\n    \"V\" TEXT\n)", []string{"sql_variant mapped to TEXT", "multi\nline"})
+
+	want := "-- 01_schema.sql: tables\n\n" +
+		"CREATE SCHEMA IF NOT EXISTS \"dbo\";\n\n" +
+		"-- WARNING: sql_variant mapped to TEXT\n" +
+		"-- WARNING: multi\n-- line\n" +
+		"CREATE TABLE \"dbo\".\"T\" (\n    \"V\" TEXT\n);\n\n"
+	if got := f.sb.String(); got != want {
+		t.Errorf("script =\n%s\nwant\n%s", got, want)
+	}
+	if f.statements != 2 {
+		t.Errorf("statements = %d, want 2", f.statements)
+	}
+}
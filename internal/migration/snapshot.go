@@ -0,0 +1,130 @@
+package migration
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"adaru-db-tool/internal/types"
+)
+
+// sourceSnapshot is the consistent point in time all data workers read the source from
+type sourceSnapshot struct {
+	database string // 資料庫快照名稱（SNAPSHOT 隔離模式為空）
+	// 建立快照前記錄的變更位置。由快照複製的表格以此作為增量同步與持續複寫的起點，
+	// 若改用各表格開始複製時的位置，快照之後到該時間點之間的變更會被略過
+	rowVersion []byte
+	ctVersion  string // 未啟用 Change Tracking 時為空
+	cdcLSN     string // 未啟用 CDC 時為空
+}
+
+// openSourceSnapshot records the change positions of the source and then establishes the snapshot
+// the data workers read from: a database snapshot, or SNAPSHOT isolation transactions the workers begin.
+func (e *Engine) openSourceSnapshot(ctx context.Context) error {
+	snapshot := &sourceSnapshot{}
+	if rv, err := e.sourceConn.GetMinActiveRowVersion(ctx); err == nil {
+		snapshot.rowVersion = rv
+	}
+	if version, err := e.sourceConn.CurrentChangeTrackingVersion(ctx); err == nil {
+		snapshot.ctVersion = strconv.FormatInt(version, 10)
+	}
+	if lsn, err := e.sourceConn.CDCMaxLSN(ctx); err == nil {
+		snapshot.cdcLSN = hex.EncodeToString(lsn)
+	}
+
+	switch e.config.SnapshotMode {
+	case types.SnapshotModeIsolation:
+		allowed, err := e.sourceConn.SnapshotIsolationAllowed(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check snapshot isolation: %w", err)
+		}
+		if !allowed {
+			return fmt.Errorf("snapshot isolation is not allowed on database %s; run ALTER DATABASE [%s] SET ALLOW_SNAPSHOT_ISOLATION ON or use a database snapshot",
+				e.config.SourceDatabase, e.config.SourceDatabase)
+		}
+		e.log(types.LogLevelInfo, "Reading all tables in one SNAPSHOT isolation transaction; tables are copied one at a time and row versions are kept in tempdb until the load ends")
+
+	case types.SnapshotModeDatabase:
+		snapshot.database = fmt.Sprintf("%s_snapshot_%s", e.config.SourceDatabase, time.Now().Format("20060102150405"))
+		start := time.Now()
+		if err := e.sourceConn.CreateDatabaseSnapshot(ctx, snapshot.database); err != nil {
+			return fmt.Errorf("failed to create database snapshot %s: %w", snapshot.database, err)
+		}
+		e.log(types.LogLevelInfo, fmt.Sprintf("Created database snapshot %s in %s; all tables are read from it",
+			snapshot.database, time.Since(start).Round(time.Millisecond)))
+	}
+
+	if e.resume != nil {
+		e.log(types.LogLevelWarn, "Resumed tables keep the rows copied before the interruption, which come from an earlier point in time than the new snapshot")
+	}
+	e.snapshot = snapshot
+	return nil
+}
+
+// closeSourceSnapshot drops the database snapshot created for the run; the data workers must be closed
+func (e *Engine) closeSourceSnapshot() {
+	if e.snapshot == nil || e.snapshot.database == "" {
+		return
+	}
+	name := e.snapshot.database
+	e.snapshot.database = ""
+	// migration 已取消時 ctx 也已取消，刪除快照改用獨立的 context
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := e.sourceConn.DropDatabaseSnapshot(ctx, name); err != nil {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Failed to drop database snapshot %s, drop it manually: %v", name, err))
+		return
+	}
+	e.log(types.LogLevelInfo, fmt.Sprintf("Dropped database snapshot %s", name))
+}
+
+// readDatabase returns the source database the data workers read from
+func (e *Engine) readDatabase() string {
+	if e.snapshot != nil && e.snapshot.database != "" {
+		return e.snapshot.database
+	}
+	return e.config.SourceDatabase
+}
+
+// minActiveRowVersion returns the rowversion bound of a table copy: the one recorded before the snapshot, or the current one
+func (e *Engine) minActiveRowVersion(ctx context.Context, w *tableWorker) ([]byte, error) {
+	if e.snapshot != nil {
+		if e.snapshot.rowVersion == nil {
+			return nil, fmt.Errorf("no rowversion was recorded before the snapshot")
+		}
+		return e.snapshot.rowVersion, nil
+	}
+	return w.sourceConn.GetMinActiveRowVersion(ctx)
+}
+
+// changeTrackingVersion returns the Change Tracking version a table copy starts replication from
+func (e *Engine) changeTrackingVersion(ctx context.Context, w *tableWorker) (string, error) {
+	if e.snapshot != nil {
+		if e.snapshot.ctVersion == "" {
+			return "", fmt.Errorf("no Change Tracking version was recorded before the snapshot")
+		}
+		return e.snapshot.ctVersion, nil
+	}
+	version, err := w.sourceConn.CurrentChangeTrackingVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(version, 10), nil
+}
+
+// cdcStartLSN returns the CDC LSN a table copy starts replication from
+func (e *Engine) cdcStartLSN(ctx context.Context, w *tableWorker) (string, error) {
+	if e.snapshot != nil {
+		if e.snapshot.cdcLSN == "" {
+			return "", fmt.Errorf("no CDC LSN was recorded before the snapshot")
+		}
+		return e.snapshot.cdcLSN, nil
+	}
+	lsn, err := w.sourceConn.CDCMaxLSN(ctx)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(lsn), nil
+}
@@ -0,0 +1,74 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	"adaru-db-tool/internal/connection"
+	"adaru-db-tool/internal/types"
+)
+
+// stagingPrefix marks the hidden tables and indexes a staged load writes to before the swap
+const stagingPrefix = "_staging_"
+
+// stagingName returns the name of the staging copy of a table or index
+func stagingName(name string) string {
+	return connection.TruncateIdentifier(stagingPrefix + name)
+}
+
+// createStagingTable recreates the empty staging table of a source table, without indexes.
+// Indexes are built after the load, which is faster than maintaining them row by row.
+func (e *Engine) createStagingTable(ctx context.Context, w *tableWorker, table *types.TableInfo) (string, error) {
+	schema := e.names.Schema(table.Schema)
+	staging := stagingName(e.names.Table(table.Schema, table.Name))
+
+	// 上次中斷留下的暫存表直接捨棄，正式表格不受影響
+	if err := w.targetConn.DropTableIfExists(ctx, schema, staging); err != nil {
+		return "", fmt.Errorf("failed to drop old staging table: %w", err)
+	}
+	if err := w.targetConn.CreateSchema(ctx, schema); err != nil {
+		return "", fmt.Errorf("failed to create schema %s: %w", schema, err)
+	}
+
+	// 並行的 worker 各自使用 TypeMapper，警告才不會互相混雜
+	tm := e.newTypeMapper()
+	ddl := e.createTableDDL(tm, *table, staging)
+	if err := w.targetConn.ExecuteDDL(ctx, ddl); err != nil {
+		e.writeFailedDDLLog(fmt.Sprintf("%s.%s", schema, staging), err, ddl)
+		return "", fmt.Errorf("failed to create staging table: %w", err)
+	}
+	for _, warn := range tm.GetWarnings() {
+		e.log(types.LogLevelWarn, warn)
+	}
+	return staging, nil
+}
+
+// buildStagingIndexes creates a source table's indexes on its staging table under staging names.
+// It returns the staging name of each created index mapped to its final target name.
+func (e *Engine) buildStagingIndexes(ctx context.Context, w *tableWorker, table *types.TableInfo, staging string) map[string]string {
+	tm := e.newTypeMapper()
+
+	renames := make(map[string]string)
+	for _, idx := range table.Indexes {
+		name := e.names.Constraint(idx.Name)
+		stagingIdx := stagingName(name)
+		if err := w.targetConn.ExecuteDDL(ctx, tm.GenerateIndexDDLAs(*table, idx, staging, stagingIdx)); err != nil {
+			e.log(types.LogLevelWarn, fmt.Sprintf("Failed to create index %s: %v", name, err))
+			continue
+		}
+		renames[stagingIdx] = name
+	}
+	return renames
+}
+
+// swapStagingTable replaces the target table of a source table with its loaded staging table in one transaction
+func (e *Engine) swapStagingTable(ctx context.Context, w *tableWorker, table *types.TableInfo, staging string, indexRenames map[string]string) error {
+	target := e.names.TargetTable(*table)
+	var serialColumns []string
+	for _, col := range target.Columns {
+		if col.IsIdentity {
+			serialColumns = append(serialColumns, col.Name)
+		}
+	}
+	return w.targetConn.SwapTable(ctx, target.Schema, staging, target.Name, indexRenames, serialColumns)
+}
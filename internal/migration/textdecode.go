@@ -0,0 +1,75 @@
+package migration
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"adaru-db-tool/internal/schema/converter"
+	"adaru-db-tool/internal/types"
+)
+
+// maxTextIssueSamples is the number of text issues stored per table; further ones are only counted
+const maxTextIssueSamples = 1000
+
+// textReporter returns a reporter that records the text issues of a table.
+// keyIndexes locate keyColumns in the reported rows; without them issues are stored without a source key.
+func (e *Engine) textReporter(table types.TableInfo, keyColumns []types.ColumnInfo, keyIndexes []int) converter.TextReporter {
+	keyNames := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		keyNames[i] = col.Name
+	}
+	return func(row []interface{}, col types.ColumnInfo, kind string, raw []byte, text string) {
+		var sourceKey string
+		if len(keyIndexes) > 0 {
+			key := make([]interface{}, len(keyIndexes))
+			for i, idx := range keyIndexes {
+				// 主鍵本身也可能是舊字碼頁字串，以解碼後的文字呈現
+				if b, ok := row[idx].([]byte); ok && keyColumns[i].CodePage != 0 {
+					key[i], _ = converter.DecodeText(keyColumns[i].CodePage, b)
+					continue
+				}
+				key[i] = row[idx]
+			}
+			sourceKey = quarantineJSON(keyNames, key)
+		}
+		e.recordTextIssue(&types.TextIssue{
+			MigrationID: e.migrationID,
+			SchemaName:  table.Schema,
+			TableName:   table.Name,
+			ColumnName:  col.Name,
+			SourceKey:   sourceKey,
+			Kind:        kind,
+			CodePage:    col.CodePage,
+			RawBytes:    hex.EncodeToString(raw),
+			Text:        text,
+		})
+	}
+}
+
+// recordTextIssue counts a text issue against its table and stores the first maxTextIssueSamples of them
+func (e *Engine) recordTextIssue(issue *types.TextIssue) {
+	tableName := fmt.Sprintf("%s.%s", issue.SchemaName, issue.TableName)
+
+	e.mu.Lock()
+	var count int64
+	if ts, ok := e.state.Tables[tableName]; ok {
+		ts.TextIssues++
+		count = ts.TextIssues
+	}
+	e.mu.Unlock()
+
+	if count > maxTextIssueSamples {
+		return
+	}
+	if err := e.storage.AddTextIssue(issue); err != nil {
+		e.log(types.LogLevelWarn, fmt.Sprintf("Failed to record a text issue of %s.%s: %v", tableName, issue.ColumnName, err))
+		return
+	}
+	switch count {
+	case 1:
+		e.log(types.LogLevelWarn, fmt.Sprintf("%s.%s holds %s text in code page %d (row %s); affected rows are listed in the migration history",
+			tableName, issue.ColumnName, issue.Kind, issue.CodePage, issue.SourceKey))
+	case maxTextIssueSamples:
+		e.log(types.LogLevelWarn, fmt.Sprintf("%s has %d text issues; further ones are counted but not listed", tableName, count))
+	}
+}
@@ -0,0 +1,250 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"adaru-db-tool/internal/types"
+)
+
+// throttleMinSleep is the shortest wait of the throttle. Shorter debts accumulate until they reach it,
+// so a high limit is not undershot by timer granularity.
+const throttleMinSleep = 10 * time.Millisecond
+
+// timeWindowCheckInterval is how often the engine checks whether it is inside an allowed time window
+const timeWindowCheckInterval = 30 * time.Second
+
+// throttle limits the combined source read rate of all pipelines in rows and bytes per second.
+// The limits can change while the migration runs; zero means unlimited.
+type throttle struct {
+	mu          sync.Mutex
+	rowsPerSec  float64
+	bytesPerSec float64
+	next        time.Time   // 以限制速率讀完已讀資料的時間；早於現在表示沒有欠額
+	enabled     atomic.Bool // 未限制時讀取端不必取得鎖
+}
+
+// setLimits replaces the limits; the new rates apply from the next row
+func (t *throttle) setLimits(rowsPerSec int, mbPerSec float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rowsPerSec = float64(rowsPerSec)
+	t.bytesPerSec = mbPerSec * 1024 * 1024
+	t.next = time.Time{}
+	t.enabled.Store(rowsPerSec > 0 || mbPerSec > 0)
+}
+
+// wait accounts for rows and bytes just read and sleeps long enough to keep the combined rates under the limits
+func (t *throttle) wait(ctx context.Context, rows, bytes int64) error {
+	if !t.enabled.Load() {
+		return nil
+	}
+
+	t.mu.Lock()
+	var cost float64
+	if t.rowsPerSec > 0 {
+		cost = float64(rows) / t.rowsPerSec
+	}
+	if t.bytesPerSec > 0 {
+		cost = max(cost, float64(bytes)/t.bytesPerSec)
+	}
+	now := time.Now()
+	// 暫停或閒置期間不累積額度，避免恢復後瞬間超速
+	if t.next.Before(now) {
+		t.next = now
+	}
+	t.next = t.next.Add(time.Duration(cost * float64(time.Second)))
+	delay := t.next.Sub(now)
+	t.mu.Unlock()
+
+	if delay < throttleMinSleep {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rowBytes approximates the size of a source row as read from the wire
+func rowBytes(row []interface{}) int64 {
+	var size int64
+	for _, v := range row {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			size += int64(len(v))
+		case []byte:
+			size += int64(len(v))
+		default:
+			size += 8
+		}
+	}
+	return size
+}
+
+// SetThrottle changes the source read limits of a running migration; zero removes a limit
+func (e *Engine) SetThrottle(rowsPerSec int, mbPerSec float64) error {
+	if rowsPerSec < 0 || mbPerSec < 0 {
+		return fmt.Errorf("throttle limits cannot be negative")
+	}
+	e.throttle.setLimits(rowsPerSec, mbPerSec)
+
+	e.mu.Lock()
+	e.config.ThrottleRowsPerSec = rowsPerSec
+	e.config.ThrottleMBPerSec = mbPerSec
+	if e.state != nil {
+		e.state.ThrottleRowsPerSec = rowsPerSec
+		e.state.ThrottleMBPerSec = mbPerSec
+	}
+	e.mu.Unlock()
+
+	e.log(types.LogLevelInfo, "Source read throttle: "+describeThrottle(rowsPerSec, mbPerSec))
+	return nil
+}
+
+// describeThrottle formats the read limits for the log
+func describeThrottle(rowsPerSec int, mbPerSec float64) string {
+	var limits []string
+	if rowsPerSec > 0 {
+		limits = append(limits, fmt.Sprintf("%d rows/s", rowsPerSec))
+	}
+	if mbPerSec > 0 {
+		limits = append(limits, fmt.Sprintf("%g MB/s", mbPerSec))
+	}
+	if len(limits) == 0 {
+		return "unlimited"
+	}
+	return strings.Join(limits, ", ")
+}
+
+// timeWindow is a daily period in local time during which the migration may run.
+// A window whose end is before its start runs past midnight, e.g. 22:00-06:00.
+type timeWindow struct {
+	start time.Duration // 自午夜起算
+	end   time.Duration
+}
+
+// parseTimeWindows parses comma-separated HH:MM-HH:MM windows such as "22:00-06:00, 12:00-13:00"
+func parseTimeWindows(s string) ([]timeWindow, error) {
+	var windows []timeWindow
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		// 也接受全形破折號 22:00–06:00
+		bounds := strings.Split(strings.ReplaceAll(part, "–", "-"), "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid time window %q, expected HH:MM-HH:MM", part)
+		}
+		var w timeWindow
+		for i, bound := range bounds {
+			t, err := time.Parse("15:04", strings.TrimSpace(bound))
+			if err != nil {
+				return nil, fmt.Errorf("invalid time window %q, expected HH:MM-HH:MM", part)
+			}
+			offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+			if i == 0 {
+				w.start = offset
+			} else {
+				w.end = offset
+			}
+		}
+		if w.start == w.end {
+			return nil, fmt.Errorf("time window %q is empty", part)
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// contains reports whether t falls inside the window
+func (w timeWindow) contains(t time.Time) bool {
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.start < w.end {
+		return offset >= w.start && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
+
+// inTimeWindows reports whether t falls inside any of the windows; no windows means always allowed
+func inTimeWindows(windows []timeWindow, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextWindowStart returns the start time of the earliest window opening after t, formatted HH:MM
+func nextWindowStart(windows []timeWindow, t time.Time) string {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	var starts []time.Time
+	for _, w := range windows {
+		start := midnight.Add(w.start)
+		if !start.After(t) {
+			start = start.AddDate(0, 0, 1)
+		}
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	return starts[0].Format("15:04")
+}
+
+// enforceTimeWindows pauses the migration when it leaves the allowed time windows and resumes it when
+// a window opens again. Only a pause made here is resumed here: a migration paused by the user stays paused,
+// and one resumed by the user outside a window keeps running until the next window closes.
+func (e *Engine) enforceTimeWindows(ctx context.Context) {
+	ticker := time.NewTicker(timeWindowCheckInterval)
+	defer ticker.Stop()
+
+	inside := true
+	windowPaused := false
+	for {
+		now := time.Now()
+		open := inTimeWindows(e.windows, now)
+		switch {
+		case inside && !open:
+			inside = false
+			e.mu.RLock()
+			running := e.state.Status == types.MigrationStatusRunning
+			e.mu.RUnlock()
+			if running {
+				e.log(types.LogLevelInfo, fmt.Sprintf("Outside the allowed time windows (%s); pausing until %s",
+					e.config.TimeWindows, nextWindowStart(e.windows, now)))
+				e.Pause()
+				windowPaused = true
+			}
+		case !inside && open:
+			inside = true
+			e.mu.RLock()
+			paused := e.paused
+			e.mu.RUnlock()
+			if windowPaused && paused {
+				e.log(types.LogLevelInfo, "Allowed time window opened; resuming")
+				e.Resume()
+			}
+			windowPaused = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package migration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeWindows(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []timeWindow
+		wantErr bool
+	}{
+		{name: "empty", in: ""},
+		{name: "overnight", in: "22:00-06:00", want: []timeWindow{{22 * time.Hour, 6 * time.Hour}}},
+		{name: "several with spaces and en dash", in: "12:00 - 13:30, 22:00–06:00", want: []timeWindow{
+			{12 * time.Hour, 13*time.Hour + 30*time.Minute}, {22 * time.Hour, 6 * time.Hour},
+		}},
+		{name: "missing end", in: "22:00", wantErr: true},
+		{name: "invalid hour", in: "25:00-06:00", wantErr: true},
+		{name: "empty window", in: "08:00-08:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTimeWindows(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTimeWindows(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseTimeWindows(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("window %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestInTimeWindows(t *testing.T) {
+	windows, err := parseTimeWindows("22:00-06:00, 12:00-13:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		at       time.Time
+		want     bool
+		wantNext string
+	}{
+		{at(23, 30), true, "12:00"},
+		{at(0, 0), true, "12:00"},
+		{at(5, 59), true, "12:00"},
+		{at(6, 0), false, "12:00"},
+		{at(12, 30), true, "22:00"},
+		{at(13, 0), false, "22:00"},
+		{at(21, 59), false, "22:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.at.Format("15:04"), func(t *testing.T) {
+			if got := inTimeWindows(windows, tt.at); got != tt.want {
+				t.Errorf("inTimeWindows() = %v, want %v", got, tt.want)
+			}
+			if got := nextWindowStart(windows, tt.at); got != tt.wantNext {
+				t.Errorf("nextWindowStart() = %s, want %s", got, tt.wantNext)
+			}
+		})
+	}
+
+	if !inTimeWindows(nil, at(3, 0)) {
+		t.Error("no windows should always be allowed")
+	}
+}
+
+func TestRowBytes(t *testing.T) {
+	row := []interface{}{int64(1), "abc", []byte{1, 2}, nil, time.Now()}
+	if got := rowBytes(row); got != 8+3+2+0+8 {
+		t.Errorf("rowBytes() = %d, want 21", got)
+	}
+}
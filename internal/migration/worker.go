@@ -0,0 +1,87 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	"adaru-db-tool/internal/connection"
+	"adaru-db-tool/internal/types"
+)
+
+// tableWorker holds the dedicated source and target connections of one data worker.
+// 每個 worker 使用獨立連線，避免多張表同時讀寫時共用同一個 session
+type tableWorker struct {
+	id         int
+	sourceConn *connection.MSSQLConnection
+	targetConn *connection.PostgresConnection
+}
+
+// openWorker connects a new worker to the source and target databases
+func (e *Engine) openWorker(ctx context.Context, id int) (*tableWorker, error) {
+	w := &tableWorker{id: id}
+
+	w.sourceConn = connection.NewMSSQLConnection(e.config.SourceConnectionString)
+	if err := w.sourceConn.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("worker %d failed to connect to source: %w", id, err)
+	}
+	if err := w.sourceConn.SetDatabase(e.readDatabase()); err != nil {
+		w.close()
+		return nil, fmt.Errorf("worker %d failed to set source database: %w", id, err)
+	}
+	if e.config.SnapshotMode == types.SnapshotModeIsolation {
+		if err := w.sourceConn.BeginSnapshot(ctx); err != nil {
+			w.close()
+			return nil, fmt.Errorf("worker %d failed to begin snapshot transaction: %w", id, err)
+		}
+	}
+
+	w.targetConn = connection.NewPostgresConnection(e.config.TargetConnectionString)
+	if err := w.targetConn.Connect(ctx); err != nil {
+		w.close()
+		return nil, fmt.Errorf("worker %d failed to connect to target: %w", id, err)
+	}
+
+	// 大量載入設定只存在於此 worker 的 session，連線關閉即恢復
+	if profile := e.bulkLoadProfile(); len(profile.Settings()) > 0 {
+		if err := w.targetConn.ApplyBulkLoadProfile(ctx, profile); err != nil {
+			e.log(types.LogLevelWarn, fmt.Sprintf("Worker %d runs without the bulk load profile: %v", id, err))
+		}
+	}
+	if e.skipForeignKeyChecks() {
+		if err := w.targetConn.DisableForeignKeyChecks(ctx); err != nil {
+			e.log(types.LogLevelWarn, fmt.Sprintf("Worker %d cannot skip foreign key checks: %v", id, err))
+		}
+	}
+
+	return w, nil
+}
+
+// bulkLoadProfile returns the session tuning of the data workers of an initial load
+// (empty for delta sync and replication, which write into live tables)
+func (e *Engine) bulkLoadProfile() connection.BulkLoadProfile {
+	var profile connection.BulkLoadProfile
+	if e.config.SyncMode != types.SyncModeFull || !e.config.BulkLoadProfile {
+		return profile
+	}
+	profile.SynchronousCommitOff = true
+	profile.MaintenanceWorkMemMB = e.config.MaintenanceWorkMemMB
+	if profile.MaintenanceWorkMemMB <= 0 {
+		profile.MaintenanceWorkMemMB = 1024
+	}
+	return profile
+}
+
+// skipForeignKeyChecks reports whether the data workers of an initial load skip foreign key checks
+func (e *Engine) skipForeignKeyChecks() bool {
+	return e.config.SyncMode == types.SyncModeFull && e.config.SkipForeignKeyChecks
+}
+
+// close releases the worker's connections
+func (w *tableWorker) close() {
+	if w.sourceConn != nil {
+		w.sourceConn.Close()
+	}
+	if w.targetConn != nil {
+		w.targetConn.Close()
+	}
+}
@@ -0,0 +1,200 @@
+package converter
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"adaru-db-tool/internal/types"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Money is an amount in cents written to a PostgreSQL money column.
+// pgx has no money codec; it encodes the underlying int64 in binary, which is money's own format.
+type Money int64
+
+// String formats the amount as a decimal with two fraction digits, e.g. -12.30
+func (m Money) String() string {
+	n := pgtype.Numeric{Int: big.NewInt(int64(m)), Exp: -2, Valid: true}
+	text, _ := n.Value()
+	return fmt.Sprintf("%v", text)
+}
+
+// castFunc returns the conversion of a column whose type mapping rule casts its values:
+// the value is first converted as its MSSQL type, then cast to the rule's target type
+func castFunc(col types.ColumnInfo, cast string, policy TimePolicy) valueFunc {
+	dataType := strings.ToLower(col.DataType)
+	convert := valueFuncFor(dataType)
+
+	var to valueFunc
+	switch cast {
+	case types.TypeCastText:
+		switch dataType {
+		case "date":
+			return castDateText
+		case "datetime", "datetime2", "smalldatetime", "datetimeoffset":
+			// 以來源的完整精度格式化，不經過微秒四捨五入
+			return variantText
+		}
+		to = castText
+	case types.TypeCastInteger:
+		to = castInteger
+	case types.TypeCastNumeric:
+		to = castNumeric
+	case types.TypeCastBoolean:
+		to = castBoolean
+	case types.TypeCastMoney:
+		to = castMoney
+	case types.TypeCastTimestampTZ:
+		if isWallClock(dataType) {
+			return zonedTimestamp(policy)
+		}
+		return convert
+	default:
+		return convert
+	}
+	if convert == nil {
+		return to
+	}
+	return func(v interface{}) (interface{}, error) {
+		converted, err := convert(v)
+		if err != nil {
+			return nil, err
+		}
+		return to(converted)
+	}
+}
+
+// castText formats a converted value as text; UUIDs, numerics and times of day in PostgreSQL's own text form
+func castText(v interface{}) (interface{}, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		text, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := text.(string); ok {
+			return s, nil
+		}
+	}
+	return variantText(v)
+}
+
+// castDateText formats a date as YYYY-MM-DD
+func castDateText(v interface{}) (interface{}, error) {
+	t, ok := v.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %T for a date", v)
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// castInteger converts a value to an integer; a decimal must have no fraction
+func castInteger(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case int64:
+		return val, nil
+	case bool:
+		if val {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case float64:
+		if val != math.Trunc(val) || math.Abs(val) > math.MaxInt64 {
+			return nil, fmt.Errorf("%v is not an integer", val)
+		}
+		return int64(val), nil
+	case pgtype.Numeric, string:
+		n, err := castNumeric(val)
+		if err != nil {
+			return nil, err
+		}
+		r := numericRat(n.(pgtype.Numeric))
+		if !r.IsInt() || !r.Num().IsInt64() {
+			return nil, fmt.Errorf("%v is not an integer", v)
+		}
+		return r.Num().Int64(), nil
+	}
+	return nil, fmt.Errorf("cannot cast %T to an integer", v)
+}
+
+// castNumeric converts a value to an exact numeric
+func castNumeric(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case pgtype.Numeric:
+		return val, nil
+	case int64:
+		return pgtype.Numeric{Int: big.NewInt(val), Valid: true}, nil
+	case bool:
+		if val {
+			return pgtype.Numeric{Int: big.NewInt(1), Valid: true}, nil
+		}
+		return pgtype.Numeric{Int: big.NewInt(0), Valid: true}, nil
+	case float64:
+		if math.IsInf(val, 0) || math.IsNaN(val) {
+			return nil, fmt.Errorf("invalid float %v", val)
+		}
+		return castNumeric(strconv.FormatFloat(val, 'f', -1, 64))
+	case string:
+		var n pgtype.Numeric
+		if err := n.Scan(strings.TrimSpace(val)); err != nil {
+			return nil, fmt.Errorf("invalid decimal %q: %w", val, err)
+		}
+		if n.NaN || n.InfinityModifier != pgtype.Finite {
+			return nil, fmt.Errorf("invalid decimal %q", val)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("cannot cast %T to a decimal", v)
+}
+
+// castBoolean converts a value to a boolean: non-zero numbers are true, as are the strings 1 and true
+func castBoolean(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case bool:
+		return val, nil
+	case int64:
+		return val != 0, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(val))
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", val)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("cannot cast %T to a boolean", v)
+}
+
+// castMoney converts a value to cents, rounding half away from zero as PostgreSQL does for numeric to money
+func castMoney(v interface{}) (interface{}, error) {
+	n, err := castNumeric(v)
+	if err != nil {
+		return nil, err
+	}
+	r := numericRat(n.(pgtype.Numeric))
+	r.Mul(r, big.NewRat(100, 1))
+
+	// 商向零截斷，餘數達一半時遠離零進位
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if m.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(m), big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(m.Sign())))
+	}
+	if !q.IsInt64() {
+		return nil, fmt.Errorf("%v is out of the range of money", v)
+	}
+	return Money(q.Int64()), nil
+}
+
+// numericRat returns the exact value of a finite numeric
+func numericRat(n pgtype.Numeric) *big.Rat {
+	r := new(big.Rat).SetInt(n.Int)
+	exp := int64(n.Exp)
+	if exp >= 0 {
+		return r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil)))
+	}
+	return r.Quo(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(-exp), nil)))
+}
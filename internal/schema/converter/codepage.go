@@ -0,0 +1,173 @@
+package converter
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// Problems found while decoding a non-Unicode string
+const (
+	TextIssueUndecodable = "undecodable" // 位元組不是該字碼頁的合法字元，以 U+FFFD 取代
+	TextIssuePrivateUse  = "private-use" // 造字區字元，對應到 Unicode 私用區，需有對應字型才能正確顯示
+)
+
+// codePageUTF8 is the code page of the _UTF8 collations of SQL Server 2019 and later
+const codePageUTF8 = 65001
+
+// codePages maps the code pages of SQL Server collations to their decoders.
+// 950 is decoded by decodeCP950, which follows Windows for the end-user-defined areas.
+var codePages = map[int]encoding.Encoding{
+	437:  charmap.CodePage437,
+	850:  charmap.CodePage850,
+	874:  charmap.Windows874,
+	932:  japanese.ShiftJIS,
+	936:  simplifiedchinese.GBK,
+	949:  korean.EUCKR,
+	1250: charmap.Windows1250,
+	1251: charmap.Windows1251,
+	1252: charmap.Windows1252,
+	1253: charmap.Windows1253,
+	1254: charmap.Windows1254,
+	1255: charmap.Windows1255,
+	1256: charmap.Windows1256,
+	1257: charmap.Windows1257,
+	1258: charmap.Windows1258,
+}
+
+// SupportsCodePage reports whether DecodeText can decode strings of a code page
+func SupportsCodePage(codePage int) bool {
+	_, ok := codePages[codePage]
+	return ok || codePage == 950 || codePage == codePageUTF8
+}
+
+// DecodeText decodes the bytes of a char, varchar or text value stored in the given code page.
+// Bytes that are not valid in the code page are replaced with U+FFFD. The second return value
+// names the problem found, undecodable bytes taking precedence over private use characters.
+func DecodeText(codePage int, b []byte) (string, string) {
+	switch codePage {
+	case 950:
+		return decodeCP950(b)
+	case codePageUTF8:
+		if utf8.Valid(b) {
+			return string(b), ""
+		}
+		return strings.ToValidUTF8(string(b), "\uFFFD"), TextIssueUndecodable
+	}
+
+	enc, ok := codePages[codePage]
+	if !ok {
+		return string(b), ""
+	}
+	decoded, err := enc.NewDecoder().Bytes(b)
+	if err != nil {
+		return strings.ToValidUTF8(string(b), "\uFFFD"), TextIssueUndecodable
+	}
+	// 舊字碼頁沒有 U+FFFD，解碼結果出現即表示有無法解碼的位元組
+	text := string(decoded)
+	if strings.ContainsRune(text, utf8.RuneError) {
+		return text, TextIssueUndecodable
+	}
+	return text, ""
+}
+
+// cp950Table maps the double-byte characters of Big5 outside the end-user-defined areas, indexed by lead<<8|trail
+var (
+	cp950Once  sync.Once
+	cp950Table map[uint16]rune
+)
+
+// loadCP950 builds the double-byte table from the Big5 decoder of x/text. Its index follows CP950 outside
+// the end-user-defined areas, where it maps HKSCS characters instead of the private use area Windows uses.
+func loadCP950() {
+	cp950Table = make(map[uint16]rune, 14000)
+	dec := traditionalchinese.Big5.NewDecoder()
+	for lead := 0x81; lead <= 0xFE; lead++ {
+		for trail := 0x40; trail <= 0xFE; trail++ {
+			if !isBig5Trail(byte(trail)) || cp950PrivateUse(byte(lead), byte(trail)) != 0 {
+				continue
+			}
+			out, err := dec.Bytes([]byte{byte(lead), byte(trail)})
+			if err != nil {
+				continue
+			}
+			r, size := utf8.DecodeRune(out)
+			if r != utf8.RuneError && size == len(out) {
+				cp950Table[uint16(lead)<<8|uint16(trail)] = r
+			}
+		}
+	}
+}
+
+// isBig5Trail reports whether b can be the second byte of a Big5 character
+func isBig5Trail(b byte) bool {
+	return (b >= 0x40 && b <= 0x7E) || (b >= 0xA1 && b <= 0xFE)
+}
+
+// cp950PrivateUse returns the private use code point Windows assigns to a character of the
+// CP950 end-user-defined areas, where Taiwanese systems keep their custom characters, or 0
+func cp950PrivateUse(lead, trail byte) rune {
+	// 每個 lead byte 有 157 個 trail byte：0x40-0x7E 與 0xA1-0xFE
+	offset := int(trail) - 0x40
+	if trail >= 0xA1 {
+		offset = int(trail) - 0xA1 + 63
+	}
+	switch {
+	case lead >= 0xFA && lead <= 0xFE:
+		return 0xE000 + rune(int(lead-0xFA)*157+offset)
+	case lead >= 0x8E && lead <= 0xA0:
+		return 0xE311 + rune(int(lead-0x8E)*157+offset)
+	case lead >= 0x81 && lead <= 0x8D:
+		return 0xEEB8 + rune(int(lead-0x81)*157+offset)
+	case lead == 0xC6 && trail >= 0xA1:
+		return 0xF6B1 + rune(int(trail)-0xA1)
+	case lead == 0xC7 || lead == 0xC8:
+		return 0xF6B1 + 94 + rune(int(lead-0xC7)*157+offset)
+	}
+	return 0
+}
+
+// decodeCP950 decodes Big5 as code page 950 does, including the end-user-defined areas
+func decodeCP950(b []byte) (string, string) {
+	cp950Once.Do(loadCP950)
+
+	var sb strings.Builder
+	sb.Grow(len(b) * 3 / 2)
+	issue := ""
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if c < 0x80 {
+			sb.WriteByte(c)
+			continue
+		}
+		// 0x80 與 0xFF 不是合法的 lead byte；trail byte 不合法時只略過 lead byte，ASCII 仍保留
+		if c == 0x80 || c == 0xFF || i+1 == len(b) || !isBig5Trail(b[i+1]) {
+			sb.WriteRune(utf8.RuneError)
+			issue = TextIssueUndecodable
+			continue
+		}
+		trail := b[i+1]
+		i++
+		if r := cp950PrivateUse(c, trail); r != 0 {
+			sb.WriteRune(r)
+			if issue == "" {
+				issue = TextIssuePrivateUse
+			}
+			continue
+		}
+		if r, ok := cp950Table[uint16(c)<<8|uint16(trail)]; ok {
+			sb.WriteRune(r)
+			continue
+		}
+		sb.WriteRune(utf8.RuneError)
+		issue = TextIssueUndecodable
+	}
+	return sb.String(), issue
+}
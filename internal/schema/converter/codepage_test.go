@@ -0,0 +1,52 @@
+package converter
+
+import "testing"
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name      string
+		codePage  int
+		input     []byte
+		want      string
+		wantIssue string
+	}{
+		{"CP950 ordinary characters", 950, []byte{0xA4, 0xA4, 0xA4, 0xE5}, "中文", ""},
+		{"CP950 mixed with ASCII", 950, []byte{'I', 'D', 0xBD, 0x73, 0xB8, 0xB9}, "ID編號", ""},
+		{"CP950 euro sign", 950, []byte{0xA3, 0xE1}, "€", ""},
+		{"CP950 end-user-defined FA40", 950, []byte{0xFA, 0x40}, "\uE000", TextIssuePrivateUse},
+		{"CP950 end-user-defined FEFE", 950, []byte{0xFE, 0xFE}, "\uE310", TextIssuePrivateUse},
+		{"CP950 end-user-defined 8E40", 950, []byte{0x8E, 0x40}, "\uE311", TextIssuePrivateUse},
+		{"CP950 end-user-defined 8140", 950, []byte{0x81, 0x40}, "\uEEB8", TextIssuePrivateUse},
+		{"CP950 end-user-defined C6A1", 950, []byte{0xC6, 0xA1}, "\uF6B1", TextIssuePrivateUse},
+		{"CP950 end-user-defined C740", 950, []byte{0xC7, 0x40}, "\uF70F", TextIssuePrivateUse},
+		{"CP950 end-user-defined C8FE", 950, []byte{0xC8, 0xFE}, "\uF848", TextIssuePrivateUse},
+		{"CP950 invalid lead byte", 950, []byte{'a', 0x80, 'b'}, "a�b", TextIssueUndecodable},
+		{"CP950 invalid trail keeps ASCII", 950, []byte{0xA4, '0'}, "�0", TextIssueUndecodable},
+		{"CP950 truncated character", 950, []byte{'a', 0xA4}, "a�", TextIssueUndecodable},
+		{"CP950 undecodable wins over private use", 950, []byte{0xFA, 0x40, 0xFF}, "\uE000�", TextIssueUndecodable},
+		{"CP1252", 1252, []byte{0x80, ' ', 0xE9}, "€ é", ""},
+		{"CP936", 936, []byte{0xD6, 0xD0, 0xCE, 0xC4}, "中文", ""},
+		{"UTF-8 collation", 65001, []byte("中文"), "中文", ""},
+		{"UTF-8 collation invalid", 65001, []byte{'a', 0xE4, 'b'}, "a�b", TextIssueUndecodable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, issue := DecodeText(tt.codePage, tt.input)
+			if got != tt.want || issue != tt.wantIssue {
+				t.Errorf("DecodeText(%d, % X) = %q, %q; want %q, %q", tt.codePage, tt.input, got, issue, tt.want, tt.wantIssue)
+			}
+		})
+	}
+}
+
+func TestSupportsCodePage(t *testing.T) {
+	for _, cp := range []int{950, 936, 932, 949, 1252, 65001} {
+		if !SupportsCodePage(cp) {
+			t.Errorf("SupportsCodePage(%d) = false", cp)
+		}
+	}
+	if SupportsCodePage(0) || SupportsCodePage(1361) {
+		t.Error("SupportsCodePage accepts a code page it cannot decode")
+	}
+}
@@ -0,0 +1,310 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"adaru-db-tool/internal/types"
+)
+
+// maxIdentifierLength is PostgreSQL's NAMEDATALEN - 1: longer identifiers are silently truncated
+const maxIdentifierLength = 63
+
+// Namer maps source identifiers to the names created on the target: explicit renames first,
+// then the naming policy. Renames are keyed by the source schema, schema.table or schema.table.column,
+// ignoring case as SQL Server's default collations do. A nil *Namer keeps every name as is.
+type Namer struct {
+	policy  string
+	renames map[string]string // 小寫的來源名稱 -> 目標名稱
+}
+
+// NewNamer validates a naming policy and rename map. It returns nil when both keep the source names.
+func NewNamer(policy string, renames map[string]string) (*Namer, error) {
+	switch policy {
+	case "", types.NamingPolicyPreserve, types.NamingPolicyLowercase, types.NamingPolicySnakeCase:
+	default:
+		return nil, fmt.Errorf("unknown naming policy %q", policy)
+	}
+	if (policy == "" || policy == types.NamingPolicyPreserve) && len(renames) == 0 {
+		return nil, nil
+	}
+
+	n := &Namer{policy: policy, renames: make(map[string]string, len(renames))}
+	for source, target := range renames {
+		key := strings.ToLower(strings.TrimSpace(source))
+		parts := strings.Split(key, ".")
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("rename %q: expected schema, schema.table or schema.table.column", source)
+		}
+		for _, part := range parts {
+			if part == "" {
+				return nil, fmt.Errorf("rename %q: expected schema, schema.table or schema.table.column", source)
+			}
+		}
+		target = strings.TrimSpace(target)
+		if target == "" {
+			return nil, fmt.Errorf("rename %q: target name is required", source)
+		}
+		if len(target) > maxIdentifierLength {
+			return nil, fmt.Errorf("rename %q: %q is longer than %d bytes", source, target, maxIdentifierLength)
+		}
+		if _, ok := n.renames[key]; ok {
+			return nil, fmt.Errorf("rename %q is listed more than once", source)
+		}
+		n.renames[key] = target
+	}
+	return n, nil
+}
+
+// Schema returns the target name of a schema
+func (n *Namer) Schema(schema string) string {
+	return n.name(schema, schema)
+}
+
+// Table returns the target name of a table of schema
+func (n *Namer) Table(schema, table string) string {
+	return n.name(schema+"."+table, table)
+}
+
+// Column returns the target name of a column of schema.table
+func (n *Namer) Column(schema, table, column string) string {
+	return n.name(schema+"."+table+"."+column, column)
+}
+
+// Columns returns the target names of columns of schema.table
+func (n *Namer) Columns(schema, table string, columns []string) []string {
+	if columns == nil {
+		return nil
+	}
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = n.Column(schema, table, col)
+	}
+	return names
+}
+
+// Constraint returns the target name of an index or a foreign key constraint.
+// Their names are not renamed individually, only converted by the naming policy.
+func (n *Namer) Constraint(name string) string {
+	if n == nil {
+		return name
+	}
+	return applyPolicy(n.policy, name)
+}
+
+// name returns the rename of key, or name converted by the naming policy
+func (n *Namer) name(key, name string) string {
+	if n == nil {
+		return name
+	}
+	if target, ok := n.renames[strings.ToLower(key)]; ok {
+		return target
+	}
+	return applyPolicy(n.policy, name)
+}
+
+// TargetTable returns a copy of a source table with every identifier replaced by its target name:
+// the schema, table, columns, keys, indexes and the tables and columns foreign keys reference.
+func (n *Namer) TargetTable(table types.TableInfo) types.TableInfo {
+	if n == nil {
+		return table
+	}
+	target := table
+	target.Schema = n.Schema(table.Schema)
+	target.Name = n.Table(table.Schema, table.Name)
+
+	if table.Columns != nil {
+		target.Columns = make([]types.ColumnInfo, len(table.Columns))
+		for i, col := range table.Columns {
+			col.Name = n.Column(table.Schema, table.Name, col.Name)
+			target.Columns[i] = col
+		}
+	}
+	target.PrimaryKey = n.Columns(table.Schema, table.Name, table.PrimaryKey)
+	if table.UniqueKey != nil {
+		key := n.targetIndex(table, *table.UniqueKey)
+		target.UniqueKey = &key
+	}
+	if table.Indexes != nil {
+		target.Indexes = make([]types.IndexInfo, len(table.Indexes))
+		for i, idx := range table.Indexes {
+			target.Indexes[i] = n.targetIndex(table, idx)
+		}
+	}
+	if table.ForeignKeys != nil {
+		target.ForeignKeys = make([]types.ForeignKey, len(table.ForeignKeys))
+		for i, fk := range table.ForeignKeys {
+			target.ForeignKeys[i] = n.ForeignKey(table.Schema, table.Name, fk)
+		}
+	}
+	return target
+}
+
+// ForeignKey returns a foreign key of schema.table under its target names and those of the table it references
+func (n *Namer) ForeignKey(schema, table string, fk types.ForeignKey) types.ForeignKey {
+	if n == nil {
+		return fk
+	}
+	fk.Name = n.Constraint(fk.Name)
+	fk.Columns = n.Columns(schema, table, fk.Columns)
+	fk.ReferencedColumns = n.Columns(fk.ReferencedSchema, fk.ReferencedTable, fk.ReferencedColumns)
+	fk.ReferencedTable = n.Table(fk.ReferencedSchema, fk.ReferencedTable)
+	fk.ReferencedSchema = n.Schema(fk.ReferencedSchema)
+	return fk
+}
+
+// targetIndex returns an index of a source table under its target names
+func (n *Namer) targetIndex(table types.TableInfo, idx types.IndexInfo) types.IndexInfo {
+	idx.Name = n.Constraint(idx.Name)
+	idx.Columns = n.Columns(table.Schema, table.Name, idx.Columns)
+	return idx
+}
+
+// NameMappings returns the mapping document of source tables: every schema, table, column, index and
+// foreign key with its source and target name, in table order
+func (n *Namer) NameMappings(tables []types.TableInfo) []types.NameMapping {
+	var mappings []types.NameMapping
+	schemas := make(map[string]bool)
+	for _, table := range tables {
+		target := n.TargetTable(table)
+		if !schemas[table.Schema] {
+			schemas[table.Schema] = true
+			mappings = append(mappings, types.NameMapping{Kind: "schema", Source: table.Schema, Target: target.Schema})
+		}
+
+		source := table.Schema + "." + table.Name
+		qualified := target.Schema + "." + target.Name
+		mappings = append(mappings, types.NameMapping{Kind: "table", Source: source, Target: qualified})
+		for i, col := range table.Columns {
+			mappings = append(mappings, types.NameMapping{Kind: "column", Source: source + "." + col.Name, Target: qualified + "." + target.Columns[i].Name})
+		}
+		for i, idx := range table.Indexes {
+			mappings = append(mappings, types.NameMapping{Kind: "index", Source: source + "." + idx.Name, Target: qualified + "." + target.Indexes[i].Name})
+		}
+		for i, fk := range table.ForeignKeys {
+			mappings = append(mappings, types.NameMapping{Kind: "foreign_key", Source: source + "." + fk.Name, Target: qualified + "." + target.ForeignKeys[i].Name})
+		}
+	}
+	return mappings
+}
+
+// Conflicts returns, by source schema.table, why tables cannot be created under their target names:
+// columns or foreign keys of a table mapped to one name, a table or index mapped to the name of a table
+// or index listed before it in the same target schema (they share PostgreSQL's relation namespace),
+// and names the policy makes longer than PostgreSQL keeps, which it would silently truncate.
+// Renames are length-checked by NewNamer. A table with a conflict is left out, so it takes no names.
+func (n *Namer) Conflicts(tables []types.TableInfo) map[string][]string {
+	if n == nil {
+		return nil
+	}
+	conflicts := make(map[string][]string)
+	relations := make(map[string]string) // 目標 schema.名稱 -> 先取用此名稱的來源表格或索引
+	for _, table := range tables {
+		source := table.Schema + "." + table.Name
+		target := n.TargetTable(table)
+		var problems []string
+		long := func(kind, key, from, to string) {
+			if _, renamed := n.renames[strings.ToLower(key)]; !renamed && len(to) > maxIdentifierLength {
+				problems = append(problems, fmt.Sprintf("%s %s maps to %s, longer than %d bytes", kind, from, to, maxIdentifierLength))
+			}
+		}
+
+		long("schema", table.Schema, table.Schema, target.Schema)
+		long("table", source, table.Name, target.Name)
+		seen := make(map[string]string)
+		for i, col := range target.Columns {
+			name := table.Columns[i].Name
+			long("column", source+"."+name, name, col.Name)
+			if other, ok := seen[col.Name]; ok {
+				problems = append(problems, fmt.Sprintf("columns %s and %s both map to %s", other, name, col.Name))
+				continue
+			}
+			seen[col.Name] = name
+		}
+		seen = make(map[string]string)
+		for i, fk := range target.ForeignKeys {
+			name := table.ForeignKeys[i].Name
+			long("foreign key", "", name, fk.Name)
+			if other, ok := seen[fk.Name]; ok {
+				problems = append(problems, fmt.Sprintf("foreign keys %s and %s both map to %s", other, name, fk.Name))
+				continue
+			}
+			seen[fk.Name] = name
+		}
+
+		// 表格與索引共用 schema 內的名稱
+		claims := map[string]string{target.Schema + "." + target.Name: "table " + source}
+		if other, ok := relations[target.Schema+"."+target.Name]; ok {
+			problems = append(problems, fmt.Sprintf("the table maps to %s.%s, already taken by %s", target.Schema, target.Name, other))
+		}
+		for i, idx := range target.Indexes {
+			name := table.Indexes[i].Name
+			long("index", "", name, idx.Name)
+			key := target.Schema + "." + idx.Name
+			other, ok := relations[key]
+			if !ok {
+				other, ok = claims[key]
+			}
+			if ok {
+				problems = append(problems, fmt.Sprintf("index %s maps to %s, already taken by %s", name, key, other))
+				continue
+			}
+			claims[key] = "index " + source + "." + name
+		}
+
+		if len(problems) > 0 {
+			conflicts[source] = problems
+			continue
+		}
+		for key, owner := range claims {
+			relations[key] = owner
+		}
+	}
+	return conflicts
+}
+
+// applyPolicy converts a name by a naming policy
+func applyPolicy(policy, name string) string {
+	switch policy {
+	case types.NamingPolicyLowercase:
+		return strings.ToLower(name)
+	case types.NamingPolicySnakeCase:
+		return SnakeCase(name)
+	}
+	return name
+}
+
+// SnakeCase converts an identifier to lower-case words separated by underscores:
+// OrderDetails -> order_details, CustomerID -> customer_id, HTTPServer -> http_server, Order Date -> order_date.
+// Digits stay with the word before them (Address2 -> address2), and existing underscores are kept.
+func SnakeCase(name string) string {
+	runes := []rune(strings.TrimSpace(name))
+	var sb strings.Builder
+	separate := func() {
+		// 不在開頭或既有底線之後重複加入分隔
+		if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "_") {
+			sb.WriteByte('_')
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// 小寫或數字之後的大寫開始新字詞；連續大寫（縮寫）在下一個字詞的第一個大寫前分隔
+			if i > 0 {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					separate()
+				}
+			}
+			sb.WriteRune(unicode.ToLower(r))
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(r)
+		default:
+			// 空白、連字號等不適合不加引號使用的字元改為底線
+			separate()
+		}
+	}
+	return sb.String()
+}
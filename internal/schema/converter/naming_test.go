@@ -0,0 +1,230 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"adaru-db-tool/internal/types"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"OrderDetails", "order_details"},
+		{"CustomerID", "customer_id"},
+		{"HTTPServer", "http_server"},
+		{"Order Date", "order_date"},
+		{"Address2", "address2"},
+		{"Line2Total", "line2_total"},
+		{"already_snake", "already_snake"},
+		{"Ship_Via", "ship_via"},
+		{"unit-price", "unit_price"},
+		{"ID", "id"},
+	}
+	for _, tt := range tests {
+		if got := SnakeCase(tt.in); got != tt.want {
+			t.Errorf("SnakeCase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewNamer(t *testing.T) {
+	if n, err := NewNamer(types.NamingPolicyPreserve, nil); n != nil || err != nil {
+		t.Errorf("NewNamer(preserve, nil) = %v, %v; want nil, nil", n, err)
+	}
+
+	invalid := []struct {
+		name    string
+		policy  string
+		renames map[string]string
+		want    string
+	}{
+		{"unknown policy", "camelCase", nil, "unknown naming policy"},
+		{"too many parts", "", map[string]string{"db.dbo.Orders.Id": "id"}, "expected schema"},
+		{"empty part", "", map[string]string{"dbo..Id": "id"}, "expected schema"},
+		{"no target", "", map[string]string{"dbo.Orders": " "}, "target name is required"},
+		{"too long", "", map[string]string{"dbo.Orders": strings.Repeat("x", 64)}, "longer than 63"},
+		{"duplicate", "", map[string]string{"dbo.Orders": "a", "DBO.orders": "b"}, "more than once"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewNamer(tt.policy, tt.renames)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewNamer() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func testNamer(t *testing.T) *Namer {
+	t.Helper()
+	n, err := NewNamer(types.NamingPolicySnakeCase, map[string]string{
+		"Sales":                       "sales_v2",
+		"dbo.Customers":               "clients",
+		"DBO.OrderDetails.CustomerID": "customer_ref",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func testOrderDetails() types.TableInfo {
+	return types.TableInfo{
+		Schema: "dbo",
+		Name:   "OrderDetails",
+		Columns: []types.ColumnInfo{
+			{Name: "OrderDetailID", DataType: "int", IsIdentity: true},
+			{Name: "CustomerID", DataType: "int"},
+			{Name: "UnitPrice", DataType: "decimal", Precision: 10, Scale: 2},
+		},
+		PrimaryKey: []string{"OrderDetailID"},
+		Indexes:    []types.IndexInfo{{Name: "IX_OrderDetails_CustomerID", Columns: []string{"CustomerID"}}},
+		ForeignKeys: []types.ForeignKey{{
+			Name:              "FK_OrderDetails_Customers",
+			Columns:           []string{"CustomerID"},
+			ReferencedSchema:  "dbo",
+			ReferencedTable:   "Customers",
+			ReferencedColumns: []string{"CustomerID"},
+		}},
+	}
+}
+
+func TestNamer_TargetTable(t *testing.T) {
+	n := testNamer(t)
+	table := testOrderDetails()
+	target := n.TargetTable(table)
+
+	if target.Schema != "dbo" || target.Name != "order_details" {
+		t.Errorf("TargetTable() name = %s.%s, want dbo.order_details", target.Schema, target.Name)
+	}
+	var cols []string
+	for _, col := range target.Columns {
+		cols = append(cols, col.Name)
+	}
+	if got := strings.Join(cols, ","); got != "order_detail_id,customer_ref,unit_price" {
+		t.Errorf("TargetTable() columns = %s", got)
+	}
+	if target.PrimaryKey[0] != "order_detail_id" || target.Indexes[0].Columns[0] != "customer_ref" {
+		t.Errorf("TargetTable() keys = %v, %+v", target.PrimaryKey, target.Indexes)
+	}
+
+	fk := target.ForeignKeys[0]
+	if fk.Name != "fk_order_details_customers" || fk.ReferencedTable != "clients" ||
+		fk.Columns[0] != "customer_ref" || fk.ReferencedColumns[0] != "customer_id" {
+		t.Errorf("TargetTable() foreign key = %+v", fk)
+	}
+	if table.Columns[1].Name != "CustomerID" || table.ForeignKeys[0].ReferencedTable != "Customers" {
+		t.Error("TargetTable() modified the source table")
+	}
+
+	if got := n.Schema("sales"); got != "sales_v2" {
+		t.Errorf("Schema(sales) = %q, want the rename", got)
+	}
+	var none *Namer
+	if got := none.TargetTable(table); got.Name != "OrderDetails" || got.Columns[1].Name != "CustomerID" {
+		t.Errorf("nil namer renamed %+v", got)
+	}
+}
+
+func TestTypeMapper_Names(t *testing.T) {
+	tm := NewTypeMapper()
+	tm.SetNames(testNamer(t))
+	table := testOrderDetails()
+
+	ddl := tm.GenerateCreateTableDDL(table)
+	for _, want := range []string{
+		`CREATE TABLE "dbo"."order_details"`,
+		`"order_detail_id" SERIAL`,
+		`"customer_ref" INTEGER`,
+		`PRIMARY KEY ("order_detail_id")`,
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("GenerateCreateTableDDL() = %s\nwant it to contain %s", ddl, want)
+		}
+	}
+
+	index := tm.GenerateIndexDDL(table, table.Indexes[0])
+	if !strings.Contains(index, `"ix_order_details_customer_id" ON "dbo"."order_details" ("customer_ref")`) {
+		t.Errorf("GenerateIndexDDL() = %s", index)
+	}
+
+	fk := tm.GenerateForeignKeyDDL(table, table.ForeignKeys[0])
+	for _, want := range []string{
+		`ALTER TABLE "dbo"."order_details"`,
+		`CONSTRAINT "fk_order_details_customers" FOREIGN KEY ("customer_ref")`,
+		`REFERENCES "dbo"."clients" ("customer_id")`,
+	} {
+		if !strings.Contains(fk, want) {
+			t.Errorf("GenerateForeignKeyDDL() = %s\nwant it to contain %s", fk, want)
+		}
+	}
+}
+
+func TestNamer_NameMappings(t *testing.T) {
+	mappings := testNamer(t).NameMappings([]types.TableInfo{testOrderDetails()})
+	want := []types.NameMapping{
+		{Kind: "schema", Source: "dbo", Target: "dbo"},
+		{Kind: "table", Source: "dbo.OrderDetails", Target: "dbo.order_details"},
+		{Kind: "column", Source: "dbo.OrderDetails.OrderDetailID", Target: "dbo.order_details.order_detail_id"},
+		{Kind: "column", Source: "dbo.OrderDetails.CustomerID", Target: "dbo.order_details.customer_ref"},
+		{Kind: "column", Source: "dbo.OrderDetails.UnitPrice", Target: "dbo.order_details.unit_price"},
+		{Kind: "index", Source: "dbo.OrderDetails.IX_OrderDetails_CustomerID", Target: "dbo.order_details.ix_order_details_customer_id"},
+		{Kind: "foreign_key", Source: "dbo.OrderDetails.FK_OrderDetails_Customers", Target: "dbo.order_details.fk_order_details_customers"},
+	}
+	if len(mappings) != len(want) {
+		t.Fatalf("NameMappings() = %+v", mappings)
+	}
+	for i := range want {
+		if mappings[i] != want[i] {
+			t.Errorf("NameMappings()[%d] = %+v, want %+v", i, mappings[i], want[i])
+		}
+	}
+}
+
+func TestNamer_Conflicts(t *testing.T) {
+	n, err := NewNamer(types.NamingPolicySnakeCase, map[string]string{"dbo.Archive": "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := strings.Repeat("AbCd", 15) // 60 個位元組，snake_case 後為 89 個
+
+	tables := []types.TableInfo{
+		{Schema: "dbo", Name: "Orders", Indexes: []types.IndexInfo{{Name: "IX_Orders"}}},
+		// 表格與索引共用 schema 內的名稱
+		{Schema: "dbo", Name: "ORDERS"},
+		{Schema: "dbo", Name: "Archive"},
+		{Schema: "dbo", Name: "Lines", Indexes: []types.IndexInfo{{Name: "ix_orders"}, {Name: "IX_Lines"}, {Name: "ixLines"}}},
+		{Schema: "dbo", Name: "Codes", Columns: []types.ColumnInfo{{Name: "Code"}, {Name: "Name"}, {Name: "CODE"}}},
+		{Schema: "dbo", Name: "Wide", Columns: []types.ColumnInfo{{Name: long}}},
+		{Schema: "dbo", Name: "Refs", ForeignKeys: []types.ForeignKey{{Name: "FK_Ref"}, {Name: "FKRef"}}},
+		// 前面的表格有衝突而不建立，不佔用名稱
+		{Schema: "dbo", Name: "ix_lines"},
+		{Schema: "sales", Name: "Orders"},
+	}
+	want := map[string]string{
+		"dbo.ORDERS":  "the table maps to dbo.orders, already taken by table dbo.Orders",
+		"dbo.Archive": "the table maps to dbo.orders, already taken by table dbo.Orders",
+		"dbo.Lines":   "index ix_orders maps to dbo.ix_orders, already taken by index dbo.Orders.IX_Orders; index ixLines maps to dbo.ix_lines, already taken by index dbo.Lines.IX_Lines",
+		"dbo.Codes":   "columns Code and CODE both map to code",
+		"dbo.Wide":    "longer than 63 bytes",
+		"dbo.Refs":    "foreign keys FK_Ref and FKRef both map to fk_ref",
+	}
+
+	got := n.Conflicts(tables)
+	if len(got) != len(want) {
+		t.Errorf("Conflicts() = %v", got)
+	}
+	for table, msg := range want {
+		if joined := strings.Join(got[table], "; "); !strings.Contains(joined, msg) {
+			t.Errorf("Conflicts()[%s] = %q, want it to contain %q", table, joined, msg)
+		}
+	}
+
+	var none *Namer
+	if got := none.Conflicts(tables); got != nil {
+		t.Errorf("nil namer Conflicts() = %v", got)
+	}
+}
@@ -0,0 +1,237 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"adaru-db-tool/internal/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// targetTypePattern limits the target type of a rule to a type name with an optional modifier,
+// since it is written into the DDL as is
+var targetTypePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ .]*(\(\s*\d+\s*(,\s*\d+\s*)?\))?[A-Za-z ]*(\[\])?$`)
+
+// TypeRules are validated type mapping rules, matched against the columns of a table.
+// A nil *TypeRules matches nothing, so the built-in mapping applies.
+type TypeRules struct {
+	rules []types.TypeMappingRule
+}
+
+// NewTypeRules validates rules and returns them ready for matching
+func NewTypeRules(rules []types.TypeMappingRule) (*TypeRules, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	compiled := make([]types.TypeMappingRule, len(rules))
+	for i, rule := range rules {
+		rule.SourceType = strings.ToLower(strings.TrimSpace(rule.SourceType))
+		rule.Table = strings.TrimSpace(rule.Table)
+		rule.Column = strings.TrimSpace(rule.Column)
+		rule.TargetType = strings.TrimSpace(rule.TargetType)
+		rule.Cast = strings.ToLower(strings.TrimSpace(rule.Cast))
+
+		if rule.TargetType == "" {
+			return nil, fmt.Errorf("rule %d: target type is required", i+1)
+		}
+		if !targetTypePattern.MatchString(rule.TargetType) {
+			return nil, fmt.Errorf("rule %d: invalid target type %q", i+1, rule.TargetType)
+		}
+		if rule.SourceType == "" && rule.Table == "" && rule.Column == "" {
+			return nil, fmt.Errorf("rule %d: a source type, table or column is required", i+1)
+		}
+		for _, pattern := range []string{rule.Table, rule.Column} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid pattern %q", i+1, pattern)
+			}
+		}
+		switch rule.Cast {
+		case "", types.TypeCastNone, types.TypeCastText, types.TypeCastInteger, types.TypeCastNumeric,
+			types.TypeCastBoolean, types.TypeCastMoney, types.TypeCastTimestampTZ:
+		default:
+			return nil, fmt.Errorf("rule %d: unknown cast %q", i+1, rule.Cast)
+		}
+		compiled[i] = rule
+	}
+	return &TypeRules{rules: compiled}, nil
+}
+
+// ParseTypeRuleSet reads a rule set from a JSON or YAML document and validates its rules
+func ParseTypeRuleSet(data []byte) (*types.TypeMappingRuleSet, error) {
+	// YAML 為 JSON 的超集，兩種格式以同一個解析器讀取；拼錯的欄位名稱視為錯誤，避免規則被默默忽略
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var set types.TypeMappingRuleSet
+	if err := dec.Decode(&set); err != nil {
+		return nil, fmt.Errorf("invalid rule set: %w", err)
+	}
+	set.Name = strings.TrimSpace(set.Name)
+	if len(set.Rules) == 0 {
+		return nil, fmt.Errorf("rule set has no rules")
+	}
+	if _, err := NewTypeRules(set.Rules); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+// Match returns the rule that applies to a column of schema.table, or nil
+func (r *TypeRules) Match(schema, table string, col types.ColumnInfo) *types.TypeMappingRule {
+	if r == nil {
+		return nil
+	}
+	var best *types.TypeMappingRule
+	bestRank := -1
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if !ruleMatches(rule, schema, table, col) {
+			continue
+		}
+		rank := 0
+		if rule.Column != "" {
+			rank += 2
+		}
+		if rule.Table != "" {
+			rank++
+		}
+		// 同等具體時以先列出者為準
+		if rank > bestRank {
+			best, bestRank = rule, rank
+		}
+	}
+	return best
+}
+
+// ruleMatches reports whether every criterion of a rule holds for a column
+func ruleMatches(rule *types.TypeMappingRule, schema, table string, col types.ColumnInfo) bool {
+	if rule.SourceType != "" && rule.SourceType != strings.ToLower(col.DataType) {
+		return false
+	}
+	if rule.Length != nil && *rule.Length != declaredLength(col) {
+		return false
+	}
+	if rule.Precision != nil && *rule.Precision != col.Precision {
+		return false
+	}
+	if rule.Scale != nil && *rule.Scale != col.Scale {
+		return false
+	}
+	if rule.Table != "" {
+		// 含 . 的樣式比對 schema.table，否則只比對表格名稱
+		name := table
+		if strings.Contains(rule.Table, ".") {
+			name = schema + "." + table
+		}
+		if !matchName(rule.Table, name) {
+			return false
+		}
+	}
+	if rule.Column != "" && !matchName(rule.Column, col.Name) {
+		return false
+	}
+	return true
+}
+
+// matchName matches a name against a wildcard pattern, ignoring case as SQL Server's default collations do
+func matchName(pattern, name string) bool {
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return ok
+}
+
+// declaredLength returns the length a column is declared with: characters for nchar and nvarchar,
+// which MSSQL reports in bytes, and -1 for (max)
+func declaredLength(col types.ColumnInfo) int {
+	switch strings.ToLower(col.DataType) {
+	case "nchar", "nvarchar":
+		if col.MaxLength > 0 {
+			return col.MaxLength / 2
+		}
+	}
+	return col.MaxLength
+}
+
+// targetBase returns the lower-case name of a PostgreSQL type without its modifier,
+// e.g. "timestamptz" for TIMESTAMPTZ(3) and "timestamp with time zone" for TIMESTAMP(3) WITH TIME ZONE
+func targetBase(targetType string) string {
+	base := strings.ToLower(targetType)
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		if j := strings.IndexByte(base[i:], ')'); j >= 0 {
+			base = base[:i] + " " + base[i+j+1:]
+		}
+	}
+	return strings.Join(strings.Fields(base), " ")
+}
+
+// hasTimeZone reports whether a PostgreSQL type is a timestamp with time zone
+func hasTimeZone(targetType string) bool {
+	switch targetBase(targetType) {
+	case "timestamptz", "timestamp with time zone":
+		return true
+	}
+	return false
+}
+
+// isIntegerTarget reports whether a PostgreSQL type is an integer type
+func isIntegerTarget(base string) bool {
+	switch base {
+	case "smallint", "int2", "integer", "int", "int4", "bigint", "int8":
+		return true
+	}
+	return false
+}
+
+// serialType returns the SERIAL type of an identity column mapped to an integer type, or "" for other types
+func serialType(base string) string {
+	switch base {
+	case "smallint", "int2":
+		return "SMALLSERIAL"
+	case "integer", "int", "int4":
+		return "SERIAL"
+	case "bigint", "int8":
+		return "BIGSERIAL"
+	}
+	return ""
+}
+
+// RuleCast returns the value cast of a rule for a column: the rule's own, or the one implied by
+// converting the column's MSSQL type to the target type, e.g. money for a MONEY target
+func RuleCast(rule *types.TypeMappingRule, col types.ColumnInfo) string {
+	if rule == nil {
+		return types.TypeCastNone
+	}
+	if rule.Cast != "" {
+		return rule.Cast
+	}
+
+	source := strings.ToLower(col.DataType)
+	base := targetBase(rule.TargetType)
+	switch {
+	case base == "money":
+		return types.TypeCastMoney
+	case hasTimeZone(rule.TargetType):
+		if isWallClock(source) {
+			return types.TypeCastTimestampTZ
+		}
+	case isIntegerTarget(base):
+		if source == "bit" {
+			return types.TypeCastInteger
+		}
+	case base == "boolean" || base == "bool":
+		switch source {
+		case "tinyint", "smallint", "int", "bigint":
+			return types.TypeCastBoolean
+		}
+	case base == "text" || base == "varchar" || base == "character varying" || base == "char" ||
+		base == "character" || base == "bpchar" || base == "citext":
+		switch source {
+		case "char", "varchar", "text", "nchar", "nvarchar", "ntext", "sysname", "xml":
+		default:
+			return types.TypeCastText
+		}
+	}
+	return types.TypeCastNone
+}
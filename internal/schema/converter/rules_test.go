@@ -0,0 +1,222 @@
+package converter
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"adaru-db-tool/internal/types"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const testRuleSet = `
+name: finance
+rules:
+  - sourceType: nvarchar
+    length: -1
+    targetType: VARCHAR
+  - sourceType: datetime
+    targetType: TIMESTAMPTZ(3)
+  - sourceType: bit
+    targetType: SMALLINT
+  - sourceType: bit
+    table: sales.*
+    column: "is_*"
+    targetType: BOOLEAN
+  - sourceType: decimal
+    precision: 19
+    scale: 4
+    targetType: MONEY
+  - sourceType: int
+    table: Orders
+    column: id
+    targetType: BIGINT
+`
+
+func testRules(t *testing.T) *TypeRules {
+	t.Helper()
+	set, err := ParseTypeRuleSet([]byte(testRuleSet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := NewTypeRules(set.Rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func TestParseTypeRuleSet(t *testing.T) {
+	set, err := ParseTypeRuleSet([]byte(testRuleSet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Name != "finance" || len(set.Rules) != 6 || *set.Rules[0].Length != -1 {
+		t.Errorf("ParseTypeRuleSet(yaml) = %+v", set)
+	}
+
+	json := `{"name": "json", "rules": [{"sourceType": "bit", "targetType": "SMALLINT", "cast": "integer"}]}`
+	if set, err := ParseTypeRuleSet([]byte(json)); err != nil || set.Rules[0].Cast != "integer" {
+		t.Errorf("ParseTypeRuleSet(json) = %+v, %v", set, err)
+	}
+
+	invalid := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"misspelled field", `{"rules": [{"sourceTyp": "bit", "targetType": "SMALLINT"}]}`, "sourceTyp"},
+		{"no rules", `name: empty`, "no rules"},
+		{"no target", `{"rules": [{"sourceType": "bit"}]}`, "target type is required"},
+		{"matches everything", `{"rules": [{"targetType": "TEXT"}]}`, "source type, table or column"},
+		{"unknown cast", `{"rules": [{"sourceType": "bit", "targetType": "TEXT", "cast": "json"}]}`, "unknown cast"},
+		{"injected ddl", `{"rules": [{"sourceType": "bit", "targetType": "TEXT; DROP TABLE x"}]}`, "invalid target type"},
+		{"bad pattern", `{"rules": [{"column": "[a", "targetType": "TEXT"}]}`, "invalid pattern"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTypeRuleSet([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseTypeRuleSet() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeRules_Match(t *testing.T) {
+	rules := testRules(t)
+
+	tests := []struct {
+		name   string
+		schema string
+		table  string
+		col    types.ColumnInfo
+		want   string // 空白表示沒有規則
+	}{
+		{"nvarchar(max)", "dbo", "Notes", types.ColumnInfo{Name: "body", DataType: "nvarchar", MaxLength: -1}, "VARCHAR"},
+		{"nvarchar(50) keeps the built-in mapping", "dbo", "Notes", types.ColumnInfo{Name: "title", DataType: "nvarchar", MaxLength: 100}, ""},
+		{"type rule", "dbo", "Notes", types.ColumnInfo{Name: "is_active", DataType: "bit"}, "SMALLINT"},
+		{"column rule wins", "Sales", "Orders", types.ColumnInfo{Name: "IS_PAID", DataType: "bit"}, "BOOLEAN"},
+		{"table pattern without schema", "dbo", "orders", types.ColumnInfo{Name: "Id", DataType: "int"}, "BIGINT"},
+		{"precision and scale", "dbo", "Ledger", types.ColumnInfo{Name: "amount", DataType: "decimal", Precision: 19, Scale: 4}, "MONEY"},
+		{"other precision", "dbo", "Ledger", types.ColumnInfo{Name: "rate", DataType: "decimal", Precision: 9, Scale: 4}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if rule := rules.Match(tt.schema, tt.table, tt.col); rule != nil {
+				got = rule.TargetType
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var none *TypeRules
+	if none.Match("dbo", "t", types.ColumnInfo{Name: "c", DataType: "bit"}) != nil {
+		t.Error("nil rules matched a column")
+	}
+}
+
+func TestTypeMapper_Rules(t *testing.T) {
+	tm := NewTypeMapper()
+	tm.SetRules(testRules(t))
+
+	one, now := "((1))", "(getdate())"
+	table := types.TableInfo{Schema: "dbo", Name: "Orders", Columns: []types.ColumnInfo{
+		{Name: "id", DataType: "int", IsIdentity: true},
+		{Name: "body", DataType: "nvarchar", MaxLength: -1, IsNullable: true},
+		{Name: "flag", DataType: "bit", DefaultValue: &one},
+		{Name: "created", DataType: "datetime", DefaultValue: &now},
+		{Name: "amount", DataType: "decimal", Precision: 19, Scale: 4},
+		{Name: "name", DataType: "nvarchar", MaxLength: 100, IsNullable: true},
+	}}
+
+	ddl := tm.GenerateCreateTableDDL(table)
+	for _, want := range []string{
+		`"id" BIGSERIAL`,
+		`"body" VARCHAR,`,
+		`"flag" SMALLINT NOT NULL DEFAULT 1`,
+		`"created" TIMESTAMPTZ(3) NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+		`"amount" MONEY NOT NULL`,
+		`"name" VARCHAR(50)`,
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("GenerateCreateTableDDL() = %s\nwant it to contain %s", ddl, want)
+		}
+	}
+}
+
+func TestValueConverter_Rules(t *testing.T) {
+	rules := testRules(t)
+	columns := []types.ColumnInfo{
+		{Name: "flag", DataType: "bit"},
+		{Name: "created", DataType: "datetime"},
+		{Name: "amount", DataType: "decimal", Precision: 19, Scale: 4},
+		{Name: "name", DataType: "nvarchar", MaxLength: 100},
+	}
+	policy, err := NewTimePolicy("Asia/Taipei", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vc := NewValueConverter(columns).WithTimePolicy(policy).WithRules(rules, "dbo", "Ledger")
+
+	wall := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	out, err := vc.Row([]interface{}{true, wall, []byte("-12.3450"), "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out[0] != int64(1) {
+		t.Errorf("bit to SMALLINT = %#v, want int64(1)", out[0])
+	}
+	if got := out[1].(time.Time); !got.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("datetime to TIMESTAMPTZ in Asia/Taipei = %v", got)
+	}
+	if out[2] != Money(-1235) || out[2].(Money).String() != "-12.35" {
+		t.Errorf("decimal to MONEY = %#v, want -12.35 rounded away from zero", out[2])
+	}
+	if out[3] != "x" {
+		t.Errorf("unmatched column = %#v, want it unchanged", out[3])
+	}
+
+	// pgx 沒有 money 的型別，依底層 int64 以 binary 編碼，即 money 的格式
+	buf, err := pgtype.NewMap().Encode(790, pgtype.BinaryFormatCode, Money(-1235), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf) != 8 || int64(binary.BigEndian.Uint64(buf)) != -1235 {
+		t.Errorf("money encodes as %x, want the cents as int8", buf)
+	}
+}
+
+func TestCasts(t *testing.T) {
+	tests := []struct {
+		name string
+		cast valueFunc
+		in   interface{}
+		want interface{}
+	}{
+		{"integer from whole decimal", castInteger, "12.000", int64(12)},
+		{"boolean from int", castBoolean, int64(2), true},
+		{"boolean from text", castBoolean, "0", false},
+		{"money half up", castMoney, "0.125", Money(13)},
+		{"money from float", castMoney, 1.5, Money(150)},
+		{"text from uuid", castText, pgtype.UUID{Bytes: [16]byte{0x6f, 0x96, 0x19, 0xff, 15: 1}, Valid: true}, "6f9619ff-0000-0000-0000-000000000001"},
+		{"text from bit", castText, true, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cast(tt.in)
+			if err != nil || got != tt.want {
+				t.Errorf("cast(%#v) = %#v, %v; want %#v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := castInteger("1.5"); err == nil {
+		t.Error("castInteger accepted a fraction")
+	}
+}
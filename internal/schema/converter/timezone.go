@@ -0,0 +1,78 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// Windows 上沒有 zoneinfo，內嵌時區資料才能載入 IANA 時區
+	_ "time/tzdata"
+)
+
+// TimePolicy says how datetime, datetime2 and smalldatetime values, which carry no offset, are migrated.
+// The zero value keeps them as wall-clock TIMESTAMP values, as SQL Server stores them.
+type TimePolicy struct {
+	Zone        string // 來源時間所屬的時區（IANA 名稱，如 Asia/Taipei）；空白表示未指定
+	TimestampTZ bool   // 對應為 TIMESTAMPTZ，依 Zone 將牆上時間換算為時間點
+
+	location *time.Location
+}
+
+// NewTimePolicy returns the policy for source times in zone, mapped to TIMESTAMPTZ when timestampTZ is set.
+// Targeting TIMESTAMPTZ without a zone takes the source times as UTC.
+func NewTimePolicy(zone string, timestampTZ bool) (TimePolicy, error) {
+	zone = strings.TrimSpace(zone)
+	if zone == "" && timestampTZ {
+		zone = "UTC"
+	}
+	policy := TimePolicy{Zone: zone, TimestampTZ: timestampTZ}
+	if zone == "" {
+		return policy, nil
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return TimePolicy{}, fmt.Errorf("unknown time zone %q", zone)
+	}
+	policy.location = loc
+	return policy, nil
+}
+
+// isWallClock reports whether a MSSQL data type holds a date and time without an offset
+func isWallClock(dataType string) bool {
+	switch strings.ToLower(dataType) {
+	case "datetime", "datetime2", "smalldatetime":
+		return true
+	}
+	return false
+}
+
+// WithTimeZone reports whether a column of the MSSQL data type is migrated to TIMESTAMPTZ
+func (p TimePolicy) WithTimeZone(dataType string) bool {
+	return strings.EqualFold(dataType, "datetimeoffset") || (p.TimestampTZ && isWallClock(dataType))
+}
+
+// instant converts the wall-clock time go-mssqldb returns (labelled UTC) to the instant it denotes in the policy's zone.
+// Times skipped by a daylight saving change are normalized forward, as time.Date does.
+func (p TimePolicy) instant(t time.Time) time.Time {
+	loc := p.location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc).UTC()
+}
+
+// currentTime returns the PostgreSQL expression of the current time for a column, with or without a time zone.
+// zone is the zone the MSSQL function reports the time in; empty means the server's local zone.
+func (p TimePolicy) currentTime(withTimeZone bool, zone string) string {
+	if withTimeZone {
+		return "CURRENT_TIMESTAMP"
+	}
+	if zone == "" {
+		zone = p.Zone
+	}
+	if zone == "" {
+		// 未指定時區：沿用 PostgreSQL session 的時區
+		return "CURRENT_TIMESTAMP"
+	}
+	return fmt.Sprintf("CURRENT_TIMESTAMP AT TIME ZONE '%s'", strings.ReplaceAll(zone, "'", "''"))
+}
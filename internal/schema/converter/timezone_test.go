@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"adaru-db-tool/internal/types"
+)
+
+func TestNewTimePolicy(t *testing.T) {
+	if _, err := NewTimePolicy("Mars/Olympus", false); err == nil {
+		t.Error("NewTimePolicy accepted an unknown zone")
+	}
+	p, err := NewTimePolicy("", true)
+	if err != nil || p.Zone != "UTC" {
+		t.Errorf("NewTimePolicy(\"\", true) = %+v, %v; want zone UTC", p, err)
+	}
+}
+
+func TestTypeMapper_TimePolicy(t *testing.T) {
+	taipei, err := NewTimePolicy("Asia/Taipei", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taipeiTZ, err := NewTimePolicy("Asia/Taipei", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		policy   TimePolicy
+		dataType string
+		scale    int
+		def      string
+		wantType string
+		wantDef  string
+	}{
+		{"no policy keeps session time", TimePolicy{}, "datetime", 3, "(getdate())", "TIMESTAMP(3)", "CURRENT_TIMESTAMP"},
+		{"no policy utc", TimePolicy{}, "datetime2", 7, "(sysutcdatetime())", "TIMESTAMP(6)", "CURRENT_TIMESTAMP AT TIME ZONE 'UTC'"},
+		{"local time in source zone", taipei, "datetime", 3, "(getdate())", "TIMESTAMP(3)", "CURRENT_TIMESTAMP AT TIME ZONE 'Asia/Taipei'"},
+		{"utc time in source zone", taipei, "smalldatetime", 0, "(getutcdate())", "TIMESTAMP(0)", "CURRENT_TIMESTAMP AT TIME ZONE 'UTC'"},
+		{"date default in source zone", taipei, "date", 0, "(getdate())", "DATE", "CURRENT_TIMESTAMP AT TIME ZONE 'Asia/Taipei'"},
+		{"timestamptz local", taipeiTZ, "datetime2", 3, "(sysdatetime())", "TIMESTAMPTZ(3)", "CURRENT_TIMESTAMP"},
+		{"timestamptz utc", taipeiTZ, "datetime", 3, "(getutcdate())", "TIMESTAMPTZ(3)", "CURRENT_TIMESTAMP"},
+		{"datetimeoffset utc", TimePolicy{}, "datetimeoffset", 7, "(getutcdate())", "TIMESTAMPTZ(6)", "CURRENT_TIMESTAMP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewTypeMapper()
+			tm.SetTimePolicy(tt.policy)
+			col := types.ColumnInfo{Name: "c", DataType: tt.dataType, Scale: tt.scale}
+			if got := tm.MapType(col); got != tt.wantType {
+				t.Errorf("MapType(%s) = %q, want %q", tt.dataType, got, tt.wantType)
+			}
+			if got := tm.MapDefaultValue(tt.def, tt.dataType); got != tt.wantDef {
+				t.Errorf("MapDefaultValue(%q, %s) = %q, want %q", tt.def, tt.dataType, got, tt.wantDef)
+			}
+		})
+	}
+}
+
+func TestValueConverter_TimePolicy(t *testing.T) {
+	columns := []types.ColumnInfo{
+		{Name: "created", DataType: "datetime"},
+		{Name: "at", DataType: "datetimeoffset"},
+	}
+	// go-mssqldb 以 UTC 標示 datetime 的牆上時間
+	wall := time.Date(2024, 1, 2, 8, 0, 0, 3000000, time.UTC)
+	offset := time.Date(2024, 1, 2, 8, 0, 0, 0, time.FixedZone("", 8*3600))
+
+	out, err := NewValueConverter(columns).Row([]interface{}{wall, offset})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out[0].(time.Time).Equal(wall) {
+		t.Errorf("without a policy datetime = %v, want the wall-clock time kept", out[0])
+	}
+
+	policy, err := NewTimePolicy("Asia/Taipei", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = NewValueConverter(columns).WithTimePolicy(policy).Row([]interface{}{wall, offset})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 1, 2, 0, 0, 0, 3000000, time.UTC)
+	if got := out[0].(time.Time); !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("datetime in Asia/Taipei = %v, want %v", got, want)
+	}
+	if got := out[1].(time.Time); !got.Equal(offset) {
+		t.Errorf("datetimeoffset = %v, want %v unchanged by the policy", got, offset)
+	}
+}
@@ -9,7 +9,10 @@ import (
 
 // TypeMapper handles MSSQL to PostgreSQL data type mapping
 type TypeMapper struct {
-	warnings []string
+	warnings   []string
+	timePolicy TimePolicy
+	rules      *TypeRules // 型別對應規則，優先於內建對應；nil 表示不使用
+	names      *Namer     // 目標識別字的命名；nil 表示沿用來源名稱
 }
 
 // NewTypeMapper creates a new TypeMapper
@@ -19,6 +22,21 @@ func NewTypeMapper() *TypeMapper {
 	}
 }
 
+// SetTimePolicy sets how datetime columns and the current-time defaults are mapped
+func (tm *TypeMapper) SetTimePolicy(policy TimePolicy) {
+	tm.timePolicy = policy
+}
+
+// SetRules sets the type mapping rules applied before the built-in mapping
+func (tm *TypeMapper) SetRules(rules *TypeRules) {
+	tm.rules = rules
+}
+
+// SetNames sets how the identifiers in the generated DDL are named on the target
+func (tm *TypeMapper) SetNames(names *Namer) {
+	tm.names = names
+}
+
 // GetWarnings returns accumulated warnings
 func (tm *TypeMapper) GetWarnings() []string {
 	return tm.warnings
@@ -96,7 +114,7 @@ func (tm *TypeMapper) MapType(col types.ColumnInfo) string {
 		return "TIME"
 
 	case "datetime":
-		return "TIMESTAMP(3)"
+		return tm.timestampType(3)
 
 	case "datetime2":
 		precision := col.Scale
@@ -105,10 +123,10 @@ func (tm *TypeMapper) MapType(col types.ColumnInfo) string {
 				fmt.Sprintf("Column %s: datetime2(%d) precision truncated to 6 (PostgreSQL max)", col.Name, precision))
 			precision = 6
 		}
-		return fmt.Sprintf("TIMESTAMP(%d)", precision)
+		return tm.timestampType(precision)
 
 	case "smalldatetime":
-		return "TIMESTAMP(0)"
+		return tm.timestampType(0)
 
 	case "datetimeoffset":
 		precision := col.Scale
@@ -202,6 +220,7 @@ func (tm *TypeMapper) MapType(col types.ColumnInfo) string {
 
 	case "timestamp", "rowversion":
 		// MSSQL timestamp is not a real timestamp, it's a binary row version
+		// 值原樣保留；增量同步的水位記錄在本機 SQLite，不依賴目標端的值
 		return "BYTEA"
 
 	case "sysname":
@@ -214,8 +233,38 @@ func (tm *TypeMapper) MapType(col types.ColumnInfo) string {
 	}
 }
 
+// MapColumnType maps a column of schema.table by the type mapping rule that matches it,
+// or by MapType when none does. Identity columns mapped to an integer type keep their sequence.
+func (tm *TypeMapper) MapColumnType(schema, table string, col types.ColumnInfo) string {
+	rule := tm.rules.Match(schema, table, col)
+	if rule == nil {
+		return tm.MapType(col)
+	}
+	if col.IsIdentity {
+		if serial := serialType(targetBase(rule.TargetType)); serial != "" {
+			return serial
+		}
+		tm.warnings = append(tm.warnings,
+			fmt.Sprintf("Column %s: identity column mapped to %s by a type mapping rule has no sequence", col.Name, rule.TargetType))
+	}
+	return rule.TargetType
+}
+
+// timestampType returns the type of a datetime column under the time policy
+func (tm *TypeMapper) timestampType(precision int) string {
+	if tm.timePolicy.TimestampTZ {
+		return fmt.Sprintf("TIMESTAMPTZ(%d)", precision)
+	}
+	return fmt.Sprintf("TIMESTAMP(%d)", precision)
+}
+
 // MapDefaultValue converts MSSQL default value to PostgreSQL syntax
 func (tm *TypeMapper) MapDefaultValue(defaultValue string, dataType string) string {
+	return tm.mapDefaultValue(defaultValue, dataType, "")
+}
+
+// mapDefaultValue converts a default value for a column of pgType, or of the type MapType chooses when pgType is empty
+func (tm *TypeMapper) mapDefaultValue(defaultValue, dataType, pgType string) string {
 	if defaultValue == "" {
 		return ""
 	}
@@ -227,14 +276,26 @@ func (tm *TypeMapper) MapDefaultValue(defaultValue string, dataType string) stri
 	}
 
 	lower := strings.ToLower(defaultValue)
+	withTimeZone := tm.timePolicy.WithTimeZone(dataType)
+	boolean := strings.ToLower(dataType) == "bit"
+	if pgType != "" {
+		// 規則指定的型別：bit 對應為 SMALLINT 時預設值維持 0/1
+		withTimeZone = hasTimeZone(pgType)
+		base := targetBase(pgType)
+		boolean = base == "boolean" || base == "bool"
+	}
 
 	// Convert common MSSQL functions to PostgreSQL equivalents
 	switch {
-	case lower == "getdate()" || lower == "current_timestamp":
-		return "CURRENT_TIMESTAMP"
+	// 本地時間以來源時區表示；目標為 TIMESTAMPTZ 時一律是當下的時間點
+	case lower == "getdate()" || lower == "current_timestamp" || lower == "sysdatetime()":
+		return tm.timePolicy.currentTime(withTimeZone, "")
+
+	case lower == "getutcdate()" || lower == "sysutcdatetime()":
+		return tm.timePolicy.currentTime(withTimeZone, "UTC")
 
-	case lower == "getutcdate()":
-		return "CURRENT_TIMESTAMP AT TIME ZONE 'UTC'"
+	case lower == "sysdatetimeoffset()":
+		return "CURRENT_TIMESTAMP"
 
 	case lower == "newid()":
 		return "gen_random_uuid()"
@@ -242,12 +303,6 @@ func (tm *TypeMapper) MapDefaultValue(defaultValue string, dataType string) stri
 	case lower == "newsequentialid()":
 		return "gen_random_uuid()"
 
-	case lower == "sysdatetime()":
-		return "CURRENT_TIMESTAMP"
-
-	case lower == "sysutcdatetime()":
-		return "CURRENT_TIMESTAMP AT TIME ZONE 'UTC'"
-
 	case strings.HasPrefix(lower, "convert("):
 		// CONVERT functions need manual review
 		tm.warnings = append(tm.warnings,
@@ -267,7 +322,7 @@ func (tm *TypeMapper) MapDefaultValue(defaultValue string, dataType string) stri
 	}
 
 	// Handle boolean conversion for BIT type
-	if strings.ToLower(dataType) == "bit" {
+	if boolean {
 		if defaultValue == "1" || lower == "'1'" {
 			return "TRUE"
 		}
@@ -290,15 +345,16 @@ func (tm *TypeMapper) MapDefaultValue(defaultValue string, dataType string) stri
 	return defaultValue
 }
 
-// GenerateColumnDDL generates the PostgreSQL column definition
-func (tm *TypeMapper) GenerateColumnDDL(col types.ColumnInfo) string {
+// GenerateColumnDDL generates the PostgreSQL column definition of a column of table.
+// The type is mapped from the source column, the name is its target name.
+func (tm *TypeMapper) GenerateColumnDDL(table types.TableInfo, col types.ColumnInfo) string {
 	var parts []string
 
 	// Column name (quoted to preserve case and handle reserved words)
-	parts = append(parts, fmt.Sprintf("\"%s\"", col.Name))
+	parts = append(parts, fmt.Sprintf("\"%s\"", tm.names.Column(table.Schema, table.Name, col.Name)))
 
 	// Data type
-	pgType := tm.MapType(col)
+	pgType := tm.MapColumnType(table.Schema, table.Name, col)
 	parts = append(parts, pgType)
 
 	// NOT NULL constraint (skip for SERIAL types as they're implicitly NOT NULL)
@@ -309,7 +365,7 @@ func (tm *TypeMapper) GenerateColumnDDL(col types.ColumnInfo) string {
 
 	// Default value (skip for SERIAL types)
 	if col.DefaultValue != nil && !isSerial {
-		defaultVal := tm.MapDefaultValue(*col.DefaultValue, col.DataType)
+		defaultVal := tm.mapDefaultValue(*col.DefaultValue, col.DataType, pgType)
 		if defaultVal != "" {
 			// 多 token 的運算式（含空格或逗號）需用括號包成單一運算式，否則 PG 會報 syntax error（如 AT、,）
 			if strings.Contains(defaultVal, " ") || strings.Contains(defaultVal, ",") {
@@ -322,22 +378,28 @@ func (tm *TypeMapper) GenerateColumnDDL(col types.ColumnInfo) string {
 	return strings.Join(parts, " ")
 }
 
-// GenerateCreateTableDDL generates a CREATE TABLE statement
+// GenerateCreateTableDDL generates a CREATE TABLE statement under the target names of a source table
 func (tm *TypeMapper) GenerateCreateTableDDL(table types.TableInfo) string {
+	return tm.GenerateCreateTableDDLAs(table, tm.names.Table(table.Schema, table.Name))
+}
+
+// GenerateCreateTableDDLAs generates the CREATE TABLE statement of a source table created under
+// another target name, e.g. a staging copy. Columns are mapped as those of the source table.
+func (tm *TypeMapper) GenerateCreateTableDDLAs(table types.TableInfo, name string) string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("CREATE TABLE \"%s\".\"%s\" (\n", table.Schema, table.Name))
+	sb.WriteString(fmt.Sprintf("CREATE TABLE \"%s\".\"%s\" (\n", tm.names.Schema(table.Schema), name))
 
 	// Columns
 	var columnDefs []string
 	for _, col := range table.Columns {
-		columnDefs = append(columnDefs, "    "+tm.GenerateColumnDDL(col))
+		columnDefs = append(columnDefs, "    "+tm.GenerateColumnDDL(table, col))
 	}
 
 	// Primary key
 	if len(table.PrimaryKey) > 0 {
 		pkCols := make([]string, len(table.PrimaryKey))
-		for i, col := range table.PrimaryKey {
+		for i, col := range tm.names.Columns(table.Schema, table.Name, table.PrimaryKey) {
 			pkCols[i] = fmt.Sprintf("\"%s\"", col)
 		}
 		columnDefs = append(columnDefs,
@@ -350,8 +412,14 @@ func (tm *TypeMapper) GenerateCreateTableDDL(table types.TableInfo) string {
 	return sb.String()
 }
 
-// GenerateIndexDDL generates a CREATE INDEX statement
+// GenerateIndexDDL generates a CREATE INDEX statement under the target names of a source table and index
 func (tm *TypeMapper) GenerateIndexDDL(table types.TableInfo, index types.IndexInfo) string {
+	return tm.GenerateIndexDDLAs(table, index, tm.names.Table(table.Schema, table.Name), tm.names.Constraint(index.Name))
+}
+
+// GenerateIndexDDLAs generates the CREATE INDEX statement of an index of a source table created under
+// other target names, e.g. on a staging copy
+func (tm *TypeMapper) GenerateIndexDDLAs(table types.TableInfo, index types.IndexInfo, tableName, indexName string) string {
 	var sb strings.Builder
 
 	if index.IsUnique {
@@ -361,11 +429,11 @@ func (tm *TypeMapper) GenerateIndexDDL(table types.TableInfo, index types.IndexI
 	}
 
 	// Index name
-	sb.WriteString(fmt.Sprintf("\"%s\" ON \"%s\".\"%s\" (", index.Name, table.Schema, table.Name))
+	sb.WriteString(fmt.Sprintf("\"%s\" ON \"%s\".\"%s\" (", indexName, tm.names.Schema(table.Schema), tableName))
 
 	// Columns
 	cols := make([]string, len(index.Columns))
-	for i, col := range index.Columns {
+	for i, col := range tm.names.Columns(table.Schema, table.Name, index.Columns) {
 		cols[i] = fmt.Sprintf("\"%s\"", col)
 	}
 	sb.WriteString(strings.Join(cols, ", "))
@@ -374,12 +442,14 @@ func (tm *TypeMapper) GenerateIndexDDL(table types.TableInfo, index types.IndexI
 	return sb.String()
 }
 
-// GenerateForeignKeyDDL generates an ALTER TABLE ADD FOREIGN KEY statement
+// GenerateForeignKeyDDL generates an ALTER TABLE ADD FOREIGN KEY statement under the target names
+// of a source table and the table it references
 func (tm *TypeMapper) GenerateForeignKeyDDL(table types.TableInfo, fk types.ForeignKey) string {
 	var sb strings.Builder
+	fk = tm.names.ForeignKey(table.Schema, table.Name, fk)
 
 	sb.WriteString(fmt.Sprintf("ALTER TABLE \"%s\".\"%s\" ADD CONSTRAINT \"%s\" FOREIGN KEY (",
-		table.Schema, table.Name, fk.Name))
+		tm.names.Schema(table.Schema), tm.names.Table(table.Schema, table.Name), fk.Name))
 
 	// Source columns
 	cols := make([]string, len(fk.Columns))
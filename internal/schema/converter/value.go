@@ -0,0 +1,398 @@
+package converter
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"adaru-db-tool/internal/types"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ValueError is a source value that cannot be converted to the PostgreSQL type of its column
+type ValueError struct {
+	Column   string
+	DataType string
+	Err      error
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("column %s (%s): %v", e.Column, e.DataType, e.Err)
+}
+
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+// ErrUndecodableText is the error of a non-Unicode string rejected because its code page cannot decode it
+var ErrUndecodableText = errors.New("undecodable bytes")
+
+// valueFunc converts a non-NULL value scanned by go-mssqldb
+type valueFunc func(v interface{}) (interface{}, error)
+
+// TextReporter is told about a non-Unicode string of a source row that was decoded with a problem,
+// one of the TextIssue kinds. raw holds the source bytes and text what is written to the target.
+type TextReporter func(row []interface{}, col types.ColumnInfo, kind string, raw []byte, text string)
+
+// ValueConverter converts the values go-mssqldb scans from source rows into the values pgx
+// encodes as the PostgreSQL types MapType chooses. Each column is converted by its MSSQL data type.
+type ValueConverter struct {
+	columns   []types.ColumnInfo
+	funcs     []valueFunc
+	codePages []int // 以原始位元組讀取、由此解碼的字串欄位之字碼頁，0 表示不解碼
+
+	rejectText bool         // 無法解碼的字串回傳 ValueError，而非以 U+FFFD 取代
+	report     TextReporter // 為 nil 時不回報
+	timePolicy TimePolicy   // WithRules 將牆上時間換算為 TIMESTAMPTZ 時使用
+}
+
+// NewValueConverter creates a converter for rows holding the given columns in order
+func NewValueConverter(columns []types.ColumnInfo) *ValueConverter {
+	vc := &ValueConverter{columns: columns, funcs: make([]valueFunc, len(columns)), codePages: make([]int, len(columns))}
+	for i, col := range columns {
+		vc.funcs[i] = valueFuncFor(col.DataType)
+		vc.codePages[i] = textCodePage(col)
+	}
+	return vc
+}
+
+// WithText returns a copy of the converter that handles undecodable strings by the policy
+// (types.UndecodableTextReplace or types.UndecodableTextReject) and reports decoding problems to report
+func (vc *ValueConverter) WithText(policy string, report TextReporter) *ValueConverter {
+	c := *vc
+	c.rejectText = policy == types.UndecodableTextReject
+	c.report = report
+	return &c
+}
+
+// WithTimePolicy returns a copy of the converter that converts datetime, datetime2 and smalldatetime
+// values to instants when the policy maps them to TIMESTAMPTZ
+func (vc *ValueConverter) WithTimePolicy(policy TimePolicy) *ValueConverter {
+	c := *vc
+	c.funcs = append([]valueFunc(nil), vc.funcs...)
+	c.timePolicy = policy
+	for i, col := range c.columns {
+		if policy.TimestampTZ && isWallClock(col.DataType) {
+			c.funcs[i] = zonedTimestamp(policy)
+		}
+	}
+	return &c
+}
+
+// WithRules returns a copy of the converter that casts the values of the columns of schema.table matched
+// by a type mapping rule to the rule's target type. A matched column is no longer converted by the time
+// policy, so WithTimePolicy must come first.
+func (vc *ValueConverter) WithRules(rules *TypeRules, schema, table string) *ValueConverter {
+	c := *vc
+	c.funcs = append([]valueFunc(nil), vc.funcs...)
+	for i, col := range c.columns {
+		if rule := rules.Match(schema, table, col); rule != nil {
+			c.funcs[i] = castFunc(col, RuleCast(rule, col), c.timePolicy)
+		}
+	}
+	return &c
+}
+
+// Quiet returns a copy of the converter that does not report decoding problems,
+// for rows converted again after they were reported once
+func (vc *ValueConverter) Quiet() *ValueConverter {
+	c := *vc
+	c.report = nil
+	return &c
+}
+
+// Row returns the converted values of a source row.
+// The source row is left untouched: its key values are sent back to SQL Server to page through the table.
+func (vc *ValueConverter) Row(row []interface{}) ([]interface{}, error) {
+	out := make([]interface{}, len(row))
+	for i, v := range row {
+		if b, ok := v.([]byte); ok && vc.codePages[i] != 0 {
+			text, err := vc.decodeText(row, i, b)
+			if err != nil {
+				return nil, &ValueError{Column: vc.columns[i].Name, DataType: vc.columns[i].DataType, Err: err}
+			}
+			// 解碼後的字串仍須經過型別對應規則的轉換
+			v = text
+		}
+		if v == nil || vc.funcs[i] == nil {
+			out[i] = v
+			continue
+		}
+		converted, err := vc.funcs[i](v)
+		if err != nil {
+			return nil, &ValueError{Column: vc.columns[i].Name, DataType: vc.columns[i].DataType, Err: err}
+		}
+		out[i] = converted
+	}
+	return out, nil
+}
+
+// decodeText decodes the bytes of a non-Unicode string column by its code page and applies the text policy
+func (vc *ValueConverter) decodeText(row []interface{}, i int, b []byte) (string, error) {
+	codePage := vc.codePages[i]
+	text, issue := DecodeText(codePage, b)
+	if issue == TextIssueUndecodable && vc.rejectText {
+		return "", fmt.Errorf("%w in code page %d: 0x%X", ErrUndecodableText, codePage, b)
+	}
+	if issue != "" && vc.report != nil {
+		vc.report(row, vc.columns[i], issue, b, text)
+	}
+	return text, nil
+}
+
+// textCodePage returns the code page a char, varchar or text column is decoded from, or 0 when
+// the driver's own decoding is used (Unicode columns, or a code page DecodeText does not know)
+func textCodePage(col types.ColumnInfo) int {
+	switch strings.ToLower(col.DataType) {
+	case "char", "varchar", "text":
+		if col.CodePage != 0 && SupportsCodePage(col.CodePage) {
+			return col.CodePage
+		}
+	}
+	return 0
+}
+
+// ConvertValue converts a single source value of a column
+func ConvertValue(col types.ColumnInfo, v interface{}) (interface{}, error) {
+	row, err := NewValueConverter([]types.ColumnInfo{col}).Row([]interface{}{v})
+	if err != nil {
+		return nil, err
+	}
+	return row[0], nil
+}
+
+// valueFuncFor returns the conversion of a MSSQL data type, or nil when pgx accepts the driver value as is
+func valueFuncFor(dataType string) valueFunc {
+	switch strings.ToLower(dataType) {
+	case "uniqueidentifier":
+		return convertGUID
+	case "decimal", "numeric", "money", "smallmoney":
+		return convertNumeric
+	case "datetime2":
+		return convertTimestamp
+	case "datetimeoffset":
+		return convertTimestamptz
+	case "time":
+		return convertTime
+	case "geography", "geometry":
+		return convertSpatial
+	case "hierarchyid", "sql_variant":
+		return variantText
+	case "bigint", "int", "smallint", "tinyint", "bit", "float", "real",
+		"date", "datetime", "smalldatetime",
+		"char", "varchar", "text", "nchar", "nvarchar", "ntext", "sysname", "xml",
+		"binary", "varbinary", "image", "timestamp", "rowversion":
+		// go-mssqldb 回傳的 int64、float64、bool、time.Time、string、[]byte 可直接交給 pgx
+		return nil
+	default:
+		// 未知型別對應為 TEXT
+		return variantText
+	}
+}
+
+// convertGUID converts a uniqueidentifier. SQL Server sends the first three groups little-endian,
+// so the raw bytes of 6F9619FF-8B86-D011-... start with FF 19 96 6F 86 8B 11 D0.
+func convertGUID(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case []byte:
+		if len(val) != 16 {
+			return nil, fmt.Errorf("uniqueidentifier has %d bytes, expected 16", len(val))
+		}
+		var u pgtype.UUID
+		copy(u.Bytes[:], val)
+		binary.BigEndian.PutUint32(u.Bytes[0:4], binary.LittleEndian.Uint32(val[0:4]))
+		binary.BigEndian.PutUint16(u.Bytes[4:6], binary.LittleEndian.Uint16(val[4:6]))
+		binary.BigEndian.PutUint16(u.Bytes[6:8], binary.LittleEndian.Uint16(val[6:8]))
+		u.Valid = true
+		return u, nil
+	case string:
+		// 連線字串啟用 GUID 轉換，或 sql_variant 中的 GUID 已轉為字串
+		var u pgtype.UUID
+		if err := u.Scan(val); err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+	return nil, fmt.Errorf("unexpected %T for uniqueidentifier", v)
+}
+
+// convertNumeric converts decimal, numeric, money and smallmoney, which go-mssqldb returns
+// as the decimal digits in a []byte, into an exact numeric
+func convertNumeric(v interface{}) (interface{}, error) {
+	var s string
+	switch val := v.(type) {
+	case []byte:
+		s = string(val)
+	case string:
+		s = val
+	case int64, float64:
+		return val, nil
+	default:
+		return nil, fmt.Errorf("unexpected %T for a decimal value", v)
+	}
+	var n pgtype.Numeric
+	if err := n.Scan(s); err != nil {
+		return nil, fmt.Errorf("invalid decimal %q: %w", s, err)
+	}
+	return n, nil
+}
+
+// convertTimestamp rounds a datetime2 to the microsecond precision of PostgreSQL.
+// pgx truncates the 100ns digit while PostgreSQL rounds literals, so rounding here keeps both paths equal.
+func convertTimestamp(v interface{}) (interface{}, error) {
+	t, ok := v.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %T for a timestamp", v)
+	}
+	return t.Round(time.Microsecond), nil
+}
+
+// zonedTimestamp returns the conversion of a datetime mapped to TIMESTAMPTZ, which reads the wall-clock
+// time in the policy's zone and rounds it to microseconds
+func zonedTimestamp(policy TimePolicy) valueFunc {
+	return func(v interface{}) (interface{}, error) {
+		t, ok := v.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %T for a timestamp", v)
+		}
+		return policy.instant(t.Round(time.Microsecond)), nil
+	}
+}
+
+// convertTimestamptz converts a datetimeoffset to the instant it denotes, rounded to microseconds
+func convertTimestamptz(v interface{}) (interface{}, error) {
+	t, ok := v.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %T for a datetimeoffset", v)
+	}
+	return t.Round(time.Microsecond).UTC(), nil
+}
+
+// convertTime converts a time of day, which go-mssqldb returns as a time.Time on 0001-01-01
+func convertTime(v interface{}) (interface{}, error) {
+	t, ok := v.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %T for a time", v)
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	usec := t.Sub(midnight).Round(time.Microsecond).Microseconds()
+	// 23:59:59.9999996 四捨五入後為 24:00:00，PostgreSQL 的 time 可表示
+	return pgtype.Time{Microseconds: min(usec, int64(24*time.Hour/time.Microsecond)), Valid: true}, nil
+}
+
+// convertSpatial converts a geography or geometry read with SourceExpression, a 4-byte big-endian SRID
+// followed by the WKB, into the EWKB PostGIS accepts. The SRID is embedded so it survives the copy.
+func convertSpatial(v interface{}) (interface{}, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %T for a spatial value", v)
+	}
+	if len(b) < 4+5 {
+		return nil, fmt.Errorf("spatial value has %d bytes, expected an SRID and WKB", len(b))
+	}
+	srid := binary.BigEndian.Uint32(b[0:4])
+	wkb := b[4:]
+	if srid == 0 {
+		return wkb, nil
+	}
+
+	// WKB 第一個位元組為位元組順序：0 big-endian、1 little-endian
+	var order interface {
+		binary.ByteOrder
+		binary.AppendByteOrder
+	} = binary.LittleEndian
+	if wkb[0] == 0 {
+		order = binary.BigEndian
+	}
+	ewkb := make([]byte, 0, len(wkb)+4)
+	ewkb = append(ewkb, wkb[0])
+	ewkb = order.AppendUint32(ewkb, order.Uint32(wkb[1:5])|0x20000000) // EWKB 旗標：後接 SRID
+	ewkb = order.AppendUint32(ewkb, srid)
+	ewkb = append(ewkb, wkb[5:]...)
+	return ewkb, nil
+}
+
+// variantText formats a value of a column mapped to TEXT, such as sql_variant, whose base type varies per row
+func variantText(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case bool:
+		if val {
+			return "1", nil
+		}
+		return "0", nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case float64:
+		if math.IsInf(val, 0) || math.IsNaN(val) {
+			return nil, fmt.Errorf("invalid float %v", val)
+		}
+		return strconv.FormatFloat(val, 'g', -1, 64), nil
+	case time.Time:
+		// datetimeoffset 以 FixedZone("") 回傳，其餘日期時間型別沒有時區
+		if name, _ := val.Zone(); name == "" {
+			return val.Format("2006-01-02 15:04:05.9999999 -07:00"), nil
+		}
+		return val.Format("2006-01-02 15:04:05.9999999"), nil
+	case []byte:
+		// decimal 與 money 以數字字串回傳；binary 與 hierarchyid 等原始位元組以 SQL Server 的 0x 格式呈現
+		if isDecimalText(val) {
+			return string(val), nil
+		}
+		return "0x" + strings.ToUpper(hex.EncodeToString(val)), nil
+	}
+	return fmt.Sprintf("%v", v), nil
+}
+
+// isDecimalText reports whether b is a decimal literal such as -12.3400
+func isDecimalText(b []byte) bool {
+	if len(b) > 0 && b[0] == '-' {
+		b = b[1:]
+	}
+	digits, dots := 0, 0
+	for _, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.':
+			dots++
+		default:
+			return false
+		}
+	}
+	return digits > 0 && dots <= 1
+}
+
+// SourceExpression returns the T-SQL that reads a column in the form the value converter expects.
+// qualifier is a table alias with its trailing dot, or empty.
+//   - hierarchyid is read as its path, e.g. /1/3/
+//   - geography and geometry are read as the SRID followed by the WKB (SQL Server's own format is not portable)
+//   - a uniqueidentifier inside a sql_variant is read as its string, since its bytes are indistinguishable from binary(16)
+//   - char, varchar and text with a known code page are read as their raw bytes and decoded by the converter,
+//     since the driver silently replaces the bytes it cannot decode
+//
+// The expressions carry no alias: ORDER BY [col] would otherwise sort by the converted expression
+// instead of the column and its collation.
+func SourceExpression(col types.ColumnInfo, qualifier string) string {
+	ref := fmt.Sprintf("%s[%s]", qualifier, col.Name)
+	switch strings.ToLower(col.DataType) {
+	case "hierarchyid":
+		return fmt.Sprintf("CAST(%s AS nvarchar(4000))", ref)
+	case "geography", "geometry":
+		return fmt.Sprintf("CAST(%s.STSrid AS binary(4)) + %s.STAsBinary()", ref, ref)
+	case "sql_variant":
+		return fmt.Sprintf("CASE WHEN SQL_VARIANT_PROPERTY(%s, 'BaseType') = 'uniqueidentifier' THEN CAST(CAST(%s AS nchar(36)) AS sql_variant) ELSE %s END",
+			ref, ref, ref)
+	}
+	if textCodePage(col) != 0 {
+		return fmt.Sprintf("CAST(%s AS varbinary(max))", ref)
+	}
+	return ref
+}
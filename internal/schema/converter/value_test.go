@@ -0,0 +1,249 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"adaru-db-tool/internal/types"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// roundTrip converts a value as go-mssqldb returns it, encodes it the way COPY sends it to PostgreSQL,
+// decodes it back and returns its PostgreSQL text form
+func roundTrip(t *testing.T, dataType string, oid uint32, value interface{}) string {
+	t.Helper()
+	converted, err := ConvertValue(types.ColumnInfo{Name: "c", DataType: dataType}, value)
+	if err != nil {
+		t.Fatalf("ConvertValue(%s, %#v): %v", dataType, value, err)
+	}
+
+	m := pgtype.NewMap()
+	buf, err := m.Encode(oid, pgtype.BinaryFormatCode, converted, nil)
+	if err != nil {
+		t.Fatalf("encode %s %#v as OID %d: %v", dataType, converted, oid, err)
+	}
+	dt, ok := m.TypeForOID(oid)
+	if !ok {
+		t.Fatalf("unknown OID %d", oid)
+	}
+	decoded, err := dt.Codec.DecodeValue(m, oid, pgtype.BinaryFormatCode, buf)
+	if err != nil {
+		t.Fatalf("decode %s: %v", dataType, err)
+	}
+	text, err := m.Encode(oid, pgtype.TextFormatCode, decoded, nil)
+	if err != nil {
+		t.Fatalf("format %s %#v: %v", dataType, decoded, err)
+	}
+	return string(text)
+}
+
+func TestConvertValue_RoundTrip(t *testing.T) {
+	// 6F9619FF-8B86-D011-B42D-00C04FC964FF 在 TDS 中前三段為 little-endian
+	guid := []byte{0xFF, 0x19, 0x96, 0x6F, 0x86, 0x8B, 0x11, 0xD0, 0xB4, 0x2D, 0x00, 0xC0, 0x4F, 0xC9, 0x64, 0xFF}
+	taipei := time.FixedZone("", 8*3600) // go-mssqldb 以無名稱的 FixedZone 回傳 datetimeoffset
+
+	tests := []struct {
+		name     string
+		dataType string
+		oid      uint32
+		value    interface{}
+		want     string
+	}{
+		{"bigint", "bigint", pgtype.Int8OID, int64(9223372036854775807), "9223372036854775807"},
+		{"int", "int", pgtype.Int4OID, int64(-2147483648), "-2147483648"},
+		{"smallint", "smallint", pgtype.Int2OID, int64(-32768), "-32768"},
+		{"tinyint", "tinyint", pgtype.Int2OID, int64(255), "255"},
+		{"bit", "bit", pgtype.BoolOID, true, "t"},
+		{"decimal", "decimal", pgtype.NumericOID, []byte("-12345678901234567890.123456789"), "-12345678901234567890.123456789"},
+		{"numeric keeps scale", "numeric", pgtype.NumericOID, []byte("0.0100"), "0.0100"},
+		{"money", "money", pgtype.NumericOID, []byte("922337203685477.5807"), "922337203685477.5807"},
+		{"smallmoney", "smallmoney", pgtype.NumericOID, []byte("-214748.3648"), "-214748.3648"},
+		{"float", "float", pgtype.Float8OID, -123456.789012345, "-123456.789012345"},
+		{"real", "real", pgtype.Float4OID, float64(float32(3.14)), "3.14"},
+		{"date", "date", pgtype.DateOID, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), "2024-02-29"},
+		{"time", "time", pgtype.TimeOID, time.Date(1, 1, 1, 13, 45, 30, 123456700, time.UTC), "13:45:30.123457"},
+		{"time rounds up to 24:00", "time", pgtype.TimeOID, time.Date(1, 1, 1, 23, 59, 59, 999999900, time.UTC), "24:00:00.000000"},
+		{"datetime", "datetime", pgtype.TimestampOID, time.Date(2024, 1, 2, 3, 4, 5, 3000000, time.UTC), "2024-01-02 03:04:05.003"},
+		{"datetime2 rounds 100ns", "datetime2", pgtype.TimestampOID, time.Date(2024, 1, 2, 3, 4, 5, 123456700, time.UTC), "2024-01-02 03:04:05.123457"},
+		{"smalldatetime", "smalldatetime", pgtype.TimestampOID, time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC), "2024-01-02 03:04:00"},
+		{"datetimeoffset", "datetimeoffset", pgtype.TimestamptzOID, time.Date(2024, 1, 2, 3, 4, 5, 123456700, taipei), "2024-01-01 19:04:05.123457Z"},
+		{"char", "char", pgtype.BPCharOID, "ab", "ab"},
+		{"varchar", "varchar", pgtype.VarcharOID, "abc", "abc"},
+		{"text", "text", pgtype.TextOID, "long text", "long text"},
+		{"nchar", "nchar", pgtype.BPCharOID, "中文", "中文"},
+		{"nvarchar", "nvarchar", pgtype.VarcharOID, "繁體中文", "繁體中文"},
+		{"ntext", "ntext", pgtype.TextOID, "ü", "ü"},
+		{"sysname", "sysname", pgtype.VarcharOID, "dbo", "dbo"},
+		{"binary", "binary", pgtype.ByteaOID, []byte{0x00, 0x01, 0xFF}, `\x0001ff`},
+		{"varbinary", "varbinary", pgtype.ByteaOID, []byte{0xCA, 0xFE}, `\xcafe`},
+		{"image", "image", pgtype.ByteaOID, []byte{0x89, 0x50}, `\x8950`},
+		{"rowversion", "rowversion", pgtype.ByteaOID, []byte{0, 0, 0, 0, 0, 0, 0x07, 0xD1}, `\x00000000000007d1`},
+		{"timestamp", "timestamp", pgtype.ByteaOID, []byte{0, 0, 0, 0, 0, 0, 0x07, 0xD2}, `\x00000000000007d2`},
+		{"uniqueidentifier", "uniqueidentifier", pgtype.UUIDOID, guid, "6f9619ff-8b86-d011-b42d-00c04fc964ff"},
+		{"uniqueidentifier string", "uniqueidentifier", pgtype.UUIDOID, "6F9619FF-8B86-D011-B42D-00C04FC964FF", "6f9619ff-8b86-d011-b42d-00c04fc964ff"},
+		{"xml", "xml", pgtype.XMLOID, "<a>1</a>", "<a>1</a>"},
+		{"hierarchyid", "hierarchyid", pgtype.TextOID, "/1/3/", "/1/3/"},
+		{"sql_variant int", "sql_variant", pgtype.TextOID, int64(42), "42"},
+		{"sql_variant bit", "sql_variant", pgtype.TextOID, false, "0"},
+		{"sql_variant float", "sql_variant", pgtype.TextOID, 0.5, "0.5"},
+		{"sql_variant decimal", "sql_variant", pgtype.TextOID, []byte("12.3400"), "12.3400"},
+		{"sql_variant binary", "sql_variant", pgtype.TextOID, []byte{0x01, 0xAB}, "0x01AB"},
+		{"sql_variant string", "sql_variant", pgtype.TextOID, "abc", "abc"},
+		{"sql_variant datetime", "sql_variant", pgtype.TextOID, time.Date(2024, 1, 2, 3, 4, 5, 500000000, time.UTC), "2024-01-02 03:04:05.5"},
+		{"sql_variant datetimeoffset", "sql_variant", pgtype.TextOID, time.Date(2024, 1, 2, 3, 4, 5, 0, taipei), "2024-01-02 03:04:05 +08:00"},
+		{"unknown type", "cursor_like", pgtype.TextOID, int64(7), "7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roundTrip(t, tt.dataType, tt.oid, tt.value); got != tt.want {
+				t.Errorf("%s %#v round-trips to %q, want %q", tt.dataType, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertValue_Spatial(t *testing.T) {
+	// POINT(121.5 25.0) 的 little-endian WKB
+	wkb := []byte{0x01, 0x01, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x60, 0x5E, 0x40,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x39, 0x40}
+
+	tests := []struct {
+		name string
+		srid []byte
+		want []byte
+	}{
+		{
+			name: "SRID embedded as EWKB",
+			srid: []byte{0x00, 0x00, 0x10, 0xE6}, // 4326
+			want: append([]byte{0x01, 0x01, 0x00, 0x00, 0x20, 0xE6, 0x10, 0x00, 0x00}, wkb[5:]...),
+		},
+		{
+			name: "SRID 0 stays plain WKB",
+			srid: []byte{0, 0, 0, 0},
+			want: wkb,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, dataType := range []string{"geography", "geometry"} {
+				got, err := ConvertValue(types.ColumnInfo{Name: "g", DataType: dataType}, append(append([]byte{}, tt.srid...), wkb...))
+				if err != nil {
+					t.Fatalf("ConvertValue(%s): %v", dataType, err)
+				}
+				if !bytes.Equal(got.([]byte), tt.want) {
+					t.Errorf("ConvertValue(%s) = % x, want % x", dataType, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestValueConverter_Row(t *testing.T) {
+	columns := []types.ColumnInfo{
+		{Name: "id", DataType: "uniqueidentifier"},
+		{Name: "amount", DataType: "money"},
+		{Name: "note", DataType: "nvarchar"},
+	}
+	vc := NewValueConverter(columns)
+
+	guid := []byte{0xFF, 0x19, 0x96, 0x6F, 0x86, 0x8B, 0x11, 0xD0, 0xB4, 0x2D, 0x00, 0xC0, 0x4F, 0xC9, 0x64, 0xFF}
+	row := []interface{}{guid, nil, "x"}
+	out, err := vc.Row(row)
+	if err != nil {
+		t.Fatalf("Row: %v", err)
+	}
+	if _, ok := out[0].(pgtype.UUID); !ok {
+		t.Errorf("id converted to %T, want pgtype.UUID", out[0])
+	}
+	if out[1] != nil || out[2] != "x" {
+		t.Errorf("Row = %v, want NULL and string kept", out)
+	}
+	// 來源資料列不變：其主鍵值仍用於向 SQL Server 分頁
+	if _, ok := row[0].([]byte); !ok {
+		t.Errorf("source row modified: id is %T", row[0])
+	}
+
+	_, err = vc.Row([]interface{}{[]byte{1, 2, 3}, nil, nil})
+	var valueErr *ValueError
+	if !errors.As(err, &valueErr) || valueErr.Column != "id" {
+		t.Errorf("Row with a 3-byte uniqueidentifier: err = %v, want a ValueError on column id", err)
+	}
+}
+
+func TestValueConverter_Text(t *testing.T) {
+	columns := []types.ColumnInfo{
+		{Name: "id", DataType: "int"},
+		{Name: "name", DataType: "varchar", CodePage: 950},
+		{Name: "note", DataType: "nvarchar"},
+	}
+	bad := []byte{'A', 0x80, 'B'}
+
+	type report struct {
+		column, kind string
+		raw          []byte
+	}
+	var reports []report
+	vc := NewValueConverter(columns).WithText(types.UndecodableTextReplace, func(row []interface{}, col types.ColumnInfo, kind string, raw []byte, text string) {
+		reports = append(reports, report{col.Name, kind, raw})
+	})
+
+	out, err := vc.Row([]interface{}{int64(1), []byte{0xA4, 0xA4, 0xA4, 0xE5}, "x"})
+	if err != nil || out[1] != "中文" {
+		t.Fatalf("Row = %v, %v; want name decoded to 中文", out, err)
+	}
+	if len(reports) != 0 {
+		t.Errorf("clean text reported: %v", reports)
+	}
+
+	out, err = vc.Row([]interface{}{int64(2), bad, nil})
+	if err != nil || out[1] != "A\uFFFDB" {
+		t.Fatalf("Row = %q, %v; want the undecodable byte replaced", out, err)
+	}
+	if len(reports) != 1 || reports[0].column != "name" || reports[0].kind != TextIssueUndecodable || !bytes.Equal(reports[0].raw, bad) {
+		t.Errorf("reports = %v, want one undecodable report on name", reports)
+	}
+
+	// 回報過的資料列重寫時不再回報
+	if _, err := vc.Quiet().Row([]interface{}{int64(2), bad, nil}); err != nil || len(reports) != 1 {
+		t.Errorf("Quiet().Row: err = %v, %d reports, want no new report", err, len(reports))
+	}
+
+	_, err = NewValueConverter(columns).WithText(types.UndecodableTextReject, nil).Row([]interface{}{int64(3), bad, nil})
+	var valueErr *ValueError
+	if !errors.As(err, &valueErr) || valueErr.Column != "name" || !errors.Is(err, ErrUndecodableText) {
+		t.Errorf("rejecting Row: err = %v, want an undecodable ValueError on name", err)
+	}
+}
+
+func TestSourceExpression(t *testing.T) {
+	tests := []struct {
+		name      string
+		col       types.ColumnInfo
+		qualifier string
+		want      string
+	}{
+		{"int", types.ColumnInfo{Name: "c", DataType: "int"}, "", "[c]"},
+		{"nvarchar", types.ColumnInfo{Name: "c", DataType: "nvarchar"}, "t.", "t.[c]"},
+		{"hierarchyid", types.ColumnInfo{Name: "c", DataType: "hierarchyid"}, "", "CAST([c] AS nvarchar(4000))"},
+		{"geography", types.ColumnInfo{Name: "c", DataType: "geography"}, "t.", "CAST(t.[c].STSrid AS binary(4)) + t.[c].STAsBinary()"},
+		{"sql_variant", types.ColumnInfo{Name: "c", DataType: "sql_variant"}, "", "CASE WHEN SQL_VARIANT_PROPERTY([c], 'BaseType') = 'uniqueidentifier' THEN CAST(CAST([c] AS nchar(36)) AS sql_variant) ELSE [c] END"},
+		{"varchar CP950", types.ColumnInfo{Name: "c", DataType: "varchar", CodePage: 950}, "", "CAST([c] AS varbinary(max))"},
+		{"text UTF-8 collation", types.ColumnInfo{Name: "c", DataType: "text", CodePage: 65001}, "ct.", "CAST(ct.[c] AS varbinary(max))"},
+		{"varchar unknown code page", types.ColumnInfo{Name: "c", DataType: "varchar", CodePage: 1361}, "", "[c]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SourceExpression(tt.col, tt.qualifier)
+			if got != tt.want {
+				t.Errorf("SourceExpression(%s, %q) = %q, want %q", tt.col.DataType, tt.qualifier, got, tt.want)
+			}
+		})
+	}
+}
@@ -47,6 +47,9 @@ func New() (*Storage, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	// SQLite 同時只允許一個寫入者，限制為單一連線避免並行 worker 寫 log 時出現 database is locked
+	db.SetMaxOpenConns(1)
+
 	s := &Storage{db: db}
 	if err := s.migrate(); err != nil {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
@@ -94,7 +97,8 @@ func (s *Storage) migrate() error {
 			migrated_rows INTEGER DEFAULT 0,
 			started_at DATETIME,
 			completed_at DATETIME,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			parent_migration_id TEXT NOT NULL DEFAULT ''
 		)`,
 		`CREATE TABLE IF NOT EXISTS migration_tables (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -102,6 +106,7 @@ func (s *Storage) migrate() error {
 			table_name TEXT NOT NULL,
 			schema_name TEXT,
 			migrate_order INTEGER DEFAULT 0,
+			read_strategy TEXT NOT NULL DEFAULT '',
 			FOREIGN KEY (migration_id) REFERENCES migrations(id)
 		)`,
 		`CREATE TABLE IF NOT EXISTS migration_logs (
@@ -128,10 +133,118 @@ func (s *Storage) migrate() error {
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (migration_id) REFERENCES migrations(id)
 		)`,
+		`CREATE TABLE IF NOT EXISTS table_checkpoints (
+			migration_id TEXT NOT NULL,
+			schema_name TEXT NOT NULL,
+			table_name TEXT NOT NULL,
+			part INTEGER NOT NULL DEFAULT 0,
+			phase TEXT NOT NULL,
+			range_from TEXT,
+			range_to TEXT,
+			last_key TEXT,
+			rows_copied INTEGER DEFAULT 0,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			row_version TEXT NOT NULL DEFAULT '',
+			repl_start TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (migration_id, schema_name, table_name, part),
+			FOREIGN KEY (migration_id) REFERENCES migrations(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS sync_watermarks (
+			source_database TEXT NOT NULL,
+			target_database TEXT NOT NULL,
+			schema_name TEXT NOT NULL,
+			table_name TEXT NOT NULL,
+			version_column TEXT NOT NULL,
+			high_water TEXT NOT NULL,
+			migration_id TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (source_database, target_database, schema_name, table_name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS replication_positions (
+			source_database TEXT NOT NULL,
+			target_database TEXT NOT NULL,
+			schema_name TEXT NOT NULL,
+			table_name TEXT NOT NULL,
+			method TEXT NOT NULL,
+			position TEXT NOT NULL,
+			migration_id TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (source_database, target_database, schema_name, table_name, method)
+		)`,
+		`CREATE TABLE IF NOT EXISTS ddl_scripts (
+			migration_id TEXT NOT NULL,
+			position INTEGER NOT NULL,
+			file_name TEXT NOT NULL,
+			content TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (migration_id, position),
+			FOREIGN KEY (migration_id) REFERENCES migrations(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS quarantined_rows (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			migration_id TEXT NOT NULL,
+			schema_name TEXT NOT NULL,
+			table_name TEXT NOT NULL,
+			source_key TEXT,
+			row_data TEXT NOT NULL,
+			error TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (migration_id) REFERENCES migrations(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS text_issues (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			migration_id TEXT NOT NULL,
+			schema_name TEXT NOT NULL,
+			table_name TEXT NOT NULL,
+			column_name TEXT NOT NULL,
+			source_key TEXT NOT NULL DEFAULT '',
+			kind TEXT NOT NULL,
+			code_page INTEGER NOT NULL DEFAULT 0,
+			raw_bytes TEXT NOT NULL,
+			text TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (migration_id) REFERENCES migrations(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS index_builds (
+			migration_id TEXT NOT NULL,
+			schema_name TEXT NOT NULL,
+			table_name TEXT NOT NULL,
+			index_name TEXT NOT NULL,
+			duration_ms INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (migration_id, schema_name, table_name, index_name),
+			FOREIGN KEY (migration_id) REFERENCES migrations(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS foreign_key_checks (
+			migration_id TEXT NOT NULL,
+			schema_name TEXT NOT NULL,
+			table_name TEXT NOT NULL,
+			constraint_name TEXT NOT NULL,
+			referenced_table TEXT NOT NULL,
+			valid INTEGER NOT NULL DEFAULT 0,
+			orphan_count INTEGER NOT NULL DEFAULT 0,
+			sample_keys TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			duration_ms INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (migration_id, schema_name, table_name, constraint_name),
+			FOREIGN KEY (migration_id) REFERENCES migrations(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS type_mapping_rule_sets (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			description TEXT NOT NULL DEFAULT '',
+			rules_json TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
 		`CREATE INDEX IF NOT EXISTS idx_migrations_status ON migrations(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_migration_tables_migration_id ON migration_tables(migration_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_migration_logs_migration_id ON migration_logs(migration_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_migration_logs_level ON migration_logs(level)`,
+		`CREATE INDEX IF NOT EXISTS idx_quarantined_rows_migration_id ON quarantined_rows(migration_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_text_issues_migration_id ON text_issues(migration_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_connections_type ON connections(type)`,
 		`CREATE INDEX IF NOT EXISTS idx_connections_deleted ON connections(deleted_at)`,
 		`CREATE UNIQUE INDEX IF NOT EXISTS idx_connections_unique ON connections(type, connection_string, database_name) WHERE deleted_at IS NULL`,
@@ -143,9 +256,36 @@ func (s *Storage) migrate() error {
 		}
 	}
 
+	// 舊版資料庫補上新增的欄位
+	if err := s.addColumnIfMissing("migrations", "parent_migration_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	if err := s.addColumnIfMissing("table_checkpoints", "row_version", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	if err := s.addColumnIfMissing("table_checkpoints", "repl_start", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	if err := s.addColumnIfMissing("migration_tables", "read_strategy", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
 	return nil
 }
 
+// addColumnIfMissing adds a column to a table created by an older version
+func (s *Storage) addColumnIfMissing(table, column, definition string) error {
+	var count int
+	if err := s.db.Get(&count, "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
 // Close closes the database connection
 func (s *Storage) Close() error {
 	return s.db.Close()
@@ -265,8 +405,8 @@ func (s *Storage) CreateMigration(record *types.MigrationRecord) error {
 	record.CreatedAt = time.Now()
 
 	_, err := s.db.NamedExec(`
-		INSERT INTO migrations (id, name, source_database, target_database, status, config_json, created_at)
-		VALUES (:id, :name, :source_database, :target_database, :status, :config_json, :created_at)
+		INSERT INTO migrations (id, name, source_database, target_database, status, config_json, created_at, parent_migration_id)
+		VALUES (:id, :name, :source_database, :target_database, :status, :config_json, :created_at, :parent_migration_id)
 	`, record)
 	return err
 }
@@ -336,6 +476,15 @@ func (s *Storage) CreateTableMigration(state *types.TableMigrationState) error {
 	return nil
 }
 
+// SetTableReadStrategy records how the source rows of a table were read
+func (s *Storage) SetTableReadStrategy(migrationID, schemaName, tableName, strategy string) error {
+	_, err := s.db.Exec(`
+		UPDATE migration_tables SET read_strategy = ?
+		WHERE migration_id = ? AND schema_name = ? AND table_name = ?
+	`, strategy, migrationID, schemaName, tableName)
+	return err
+}
+
 // GetTableMigrations retrieves table migrations for a migration
 func (s *Storage) GetTableMigrations(migrationID string) ([]types.TableMigrationState, error) {
 	var states []types.TableMigrationState
@@ -343,6 +492,290 @@ func (s *Storage) GetTableMigrations(migrationID string) ([]types.TableMigration
 	return states, err
 }
 
+// GetTableResults returns the last status logged for each table of a migration, in first-logged order
+func (s *Storage) GetTableResults(migrationID string) ([]types.TableResult, error) {
+	var entries []types.TableResult
+	err := s.db.Select(&entries, `
+		SELECT table_name, status, COALESCE(error_message, '') AS error_message
+		FROM migration_logs
+		WHERE migration_id = ? AND COALESCE(table_name, '') <> '' AND COALESCE(status, '') <> ''
+		ORDER BY id
+	`, migrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一表格可能有多筆（續傳、不同階段），保留最後一筆狀態
+	var results []types.TableResult
+	index := make(map[string]int)
+	for _, entry := range entries {
+		if i, ok := index[entry.TableName]; ok {
+			results[i] = entry
+			continue
+		}
+		index[entry.TableName] = len(results)
+		results = append(results, entry)
+	}
+
+	counts, err := s.GetQuarantineCounts(migrationID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].QuarantinedRows = counts[results[i].TableName]
+	}
+	return results, nil
+}
+
+// AddQuarantinedRow stores a source row that could not be written to the target
+func (s *Storage) AddQuarantinedRow(row *types.QuarantinedRow) error {
+	row.CreatedAt = time.Now()
+	_, err := s.db.NamedExec(`
+		INSERT INTO quarantined_rows (migration_id, schema_name, table_name, source_key, row_data, error, created_at)
+		VALUES (:migration_id, :schema_name, :table_name, :source_key, :row_data, :error, :created_at)
+	`, row)
+	return err
+}
+
+// GetQuarantinedRows returns the quarantined rows of a migration, oldest first
+func (s *Storage) GetQuarantinedRows(migrationID string, limit int) ([]types.QuarantinedRow, error) {
+	var rows []types.QuarantinedRow
+	err := s.db.Select(&rows, `
+		SELECT id, migration_id, schema_name, table_name, COALESCE(source_key, '') AS source_key, row_data, error, created_at
+		FROM quarantined_rows WHERE migration_id = ? ORDER BY id LIMIT ?
+	`, migrationID, limit)
+	return rows, err
+}
+
+// GetQuarantineCounts returns the number of quarantined rows per table (schema.table) of a migration
+func (s *Storage) GetQuarantineCounts(migrationID string) (map[string]int64, error) {
+	var entries []struct {
+		TableName string `db:"table_name"`
+		Count     int64  `db:"count"`
+	}
+	err := s.db.Select(&entries, `
+		SELECT schema_name || '.' || table_name AS table_name, COUNT(*) AS count
+		FROM quarantined_rows WHERE migration_id = ?
+		GROUP BY schema_name, table_name
+	`, migrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(entries))
+	for _, e := range entries {
+		counts[e.TableName] = e.Count
+	}
+	return counts, nil
+}
+
+// AddTextIssue stores a non-Unicode string value that was decoded with a problem
+func (s *Storage) AddTextIssue(issue *types.TextIssue) error {
+	issue.CreatedAt = time.Now()
+	_, err := s.db.NamedExec(`
+		INSERT INTO text_issues (migration_id, schema_name, table_name, column_name, source_key, kind, code_page, raw_bytes, text, created_at)
+		VALUES (:migration_id, :schema_name, :table_name, :column_name, :source_key, :kind, :code_page, :raw_bytes, :text, :created_at)
+	`, issue)
+	return err
+}
+
+// GetTextIssues returns the text decoding issues of a migration, oldest first
+func (s *Storage) GetTextIssues(migrationID string, limit int) ([]types.TextIssue, error) {
+	var issues []types.TextIssue
+	err := s.db.Select(&issues, `
+		SELECT id, migration_id, schema_name, table_name, column_name, source_key, kind, code_page, raw_bytes, text, created_at
+		FROM text_issues WHERE migration_id = ? ORDER BY id LIMIT ?
+	`, migrationID, limit)
+	return issues, err
+}
+
+// SaveIndexBuild records the outcome of a deferred index build, replacing the record of an earlier attempt
+func (s *Storage) SaveIndexBuild(build *types.IndexBuild) error {
+	build.CreatedAt = time.Now()
+	_, err := s.db.NamedExec(`
+		INSERT INTO index_builds (migration_id, schema_name, table_name, index_name, duration_ms, error, created_at)
+		VALUES (:migration_id, :schema_name, :table_name, :index_name, :duration_ms, :error, :created_at)
+		ON CONFLICT (migration_id, schema_name, table_name, index_name) DO UPDATE SET
+			duration_ms = excluded.duration_ms,
+			error = excluded.error,
+			created_at = excluded.created_at
+	`, build)
+	return err
+}
+
+// GetIndexBuilds returns the deferred index builds of a migration in the order they finished
+func (s *Storage) GetIndexBuilds(migrationID string) ([]types.IndexBuild, error) {
+	var builds []types.IndexBuild
+	err := s.db.Select(&builds, `
+		SELECT migration_id, schema_name, table_name, index_name, duration_ms, error, created_at
+		FROM index_builds WHERE migration_id = ? ORDER BY created_at
+	`, migrationID)
+	return builds, err
+}
+
+// SaveForeignKeyCheck records the outcome of creating or validating a foreign key, replacing an earlier record
+func (s *Storage) SaveForeignKeyCheck(check *types.ForeignKeyCheck) error {
+	check.CreatedAt = time.Now()
+	_, err := s.db.NamedExec(`
+		INSERT INTO foreign_key_checks (migration_id, schema_name, table_name, constraint_name, referenced_table, valid, orphan_count, sample_keys, error, duration_ms, created_at)
+		VALUES (:migration_id, :schema_name, :table_name, :constraint_name, :referenced_table, :valid, :orphan_count, :sample_keys, :error, :duration_ms, :created_at)
+		ON CONFLICT (migration_id, schema_name, table_name, constraint_name) DO UPDATE SET
+			referenced_table = excluded.referenced_table,
+			valid = excluded.valid,
+			orphan_count = excluded.orphan_count,
+			sample_keys = excluded.sample_keys,
+			error = excluded.error,
+			duration_ms = excluded.duration_ms,
+			created_at = excluded.created_at
+	`, check)
+	return err
+}
+
+// GetForeignKeyChecks returns the foreign key checks of a migration, invalid constraints first
+func (s *Storage) GetForeignKeyChecks(migrationID string) ([]types.ForeignKeyCheck, error) {
+	var checks []types.ForeignKeyCheck
+	err := s.db.Select(&checks, `
+		SELECT migration_id, schema_name, table_name, constraint_name, referenced_table, valid, orphan_count, sample_keys, error, duration_ms, created_at
+		FROM foreign_key_checks WHERE migration_id = ? ORDER BY valid, schema_name, table_name, constraint_name
+	`, migrationID)
+	return checks, err
+}
+
+// Checkpoint methods
+
+// SaveTableCheckpoint inserts or replaces the checkpoint of a table part
+func (s *Storage) SaveTableCheckpoint(cp *types.TableCheckpoint) error {
+	cp.UpdatedAt = time.Now()
+	_, err := s.db.NamedExec(`
+		INSERT INTO table_checkpoints (migration_id, schema_name, table_name, part, phase, range_from, range_to, last_key, rows_copied, row_version, repl_start, updated_at)
+		VALUES (:migration_id, :schema_name, :table_name, :part, :phase, :range_from, :range_to, :last_key, :rows_copied, :row_version, :repl_start, :updated_at)
+		ON CONFLICT (migration_id, schema_name, table_name, part) DO UPDATE SET
+			phase = excluded.phase,
+			range_from = excluded.range_from,
+			range_to = excluded.range_to,
+			last_key = excluded.last_key,
+			rows_copied = excluded.rows_copied,
+			row_version = CASE WHEN excluded.row_version = '' THEN table_checkpoints.row_version ELSE excluded.row_version END,
+			repl_start = CASE WHEN excluded.repl_start = '' THEN table_checkpoints.repl_start ELSE excluded.repl_start END,
+			updated_at = excluded.updated_at
+	`, cp)
+	return err
+}
+
+// SetCheckpointStart records the change positions captured before a table's full copy in its part 0 checkpoint
+func (s *Storage) SetCheckpointStart(migrationID, schema, tableName, rowVersion, replStart string) error {
+	_, err := s.db.Exec(`
+		UPDATE table_checkpoints SET row_version = ?, repl_start = ?
+		WHERE migration_id = ? AND schema_name = ? AND table_name = ? AND part = 0
+	`, rowVersion, replStart, migrationID, schema, tableName)
+	return err
+}
+
+// GetTableCheckpoints retrieves all checkpoints of a migration
+func (s *Storage) GetTableCheckpoints(migrationID string) ([]types.TableCheckpoint, error) {
+	var cps []types.TableCheckpoint
+	err := s.db.Select(&cps, `
+		SELECT migration_id, schema_name, table_name, part, phase,
+			COALESCE(range_from, '') AS range_from, COALESCE(range_to, '') AS range_to,
+			COALESCE(last_key, '') AS last_key, rows_copied, row_version, repl_start, updated_at
+		FROM table_checkpoints
+		WHERE migration_id = ?
+		ORDER BY schema_name, table_name, part
+	`, migrationID)
+	return cps, err
+}
+
+// Watermark methods
+
+// SaveSyncWatermark inserts or replaces the rowversion high-water mark of a table
+func (s *Storage) SaveSyncWatermark(wm *types.SyncWatermark) error {
+	wm.UpdatedAt = time.Now()
+	_, err := s.db.NamedExec(`
+		INSERT INTO sync_watermarks (source_database, target_database, schema_name, table_name, version_column, high_water, migration_id, updated_at)
+		VALUES (:source_database, :target_database, :schema_name, :table_name, :version_column, :high_water, :migration_id, :updated_at)
+		ON CONFLICT (source_database, target_database, schema_name, table_name) DO UPDATE SET
+			version_column = excluded.version_column,
+			high_water = excluded.high_water,
+			migration_id = excluded.migration_id,
+			updated_at = excluded.updated_at
+	`, wm)
+	return err
+}
+
+// GetSyncWatermark retrieves the high-water mark of a table, or nil if none was recorded
+func (s *Storage) GetSyncWatermark(sourceDatabase, targetDatabase, schema, tableName string) (*types.SyncWatermark, error) {
+	var wm types.SyncWatermark
+	err := s.db.Get(&wm, `
+		SELECT source_database, target_database, schema_name, table_name, version_column, high_water,
+			COALESCE(migration_id, '') AS migration_id, updated_at
+		FROM sync_watermarks
+		WHERE source_database = ? AND target_database = ? AND schema_name = ? AND table_name = ?
+	`, sourceDatabase, targetDatabase, schema, tableName)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &wm, err
+}
+
+// Replication methods
+
+// SaveReplicationPosition inserts or replaces the replication position of a table
+func (s *Storage) SaveReplicationPosition(pos *types.ReplicationPosition) error {
+	pos.UpdatedAt = time.Now()
+	_, err := s.db.NamedExec(`
+		INSERT INTO replication_positions (source_database, target_database, schema_name, table_name, method, position, migration_id, updated_at)
+		VALUES (:source_database, :target_database, :schema_name, :table_name, :method, :position, :migration_id, :updated_at)
+		ON CONFLICT (source_database, target_database, schema_name, table_name, method) DO UPDATE SET
+			position = excluded.position,
+			migration_id = excluded.migration_id,
+			updated_at = excluded.updated_at
+	`, pos)
+	return err
+}
+
+// GetReplicationPosition retrieves the replication position of a table, or nil if none was recorded
+func (s *Storage) GetReplicationPosition(sourceDatabase, targetDatabase, schema, tableName, method string) (*types.ReplicationPosition, error) {
+	var pos types.ReplicationPosition
+	err := s.db.Get(&pos, `
+		SELECT source_database, target_database, schema_name, table_name, method, position,
+			COALESCE(migration_id, '') AS migration_id, updated_at
+		FROM replication_positions
+		WHERE source_database = ? AND target_database = ? AND schema_name = ? AND table_name = ? AND method = ?
+	`, sourceDatabase, targetDatabase, schema, tableName, method)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &pos, err
+}
+
+// SaveDDLScript stores the files of a dry-run DDL script, replacing any earlier script of the migration
+func (s *Storage) SaveDDLScript(migrationID string, files []types.ScriptFile) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM ddl_scripts WHERE migration_id = ?", migrationID); err != nil {
+		return err
+	}
+	for i, f := range files {
+		_, err := tx.Exec("INSERT INTO ddl_scripts (migration_id, position, file_name, content) VALUES (?, ?, ?, ?)",
+			migrationID, i, f.Name, f.Content)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetDDLScript returns the files of a migration's dry-run DDL script in execution order
+func (s *Storage) GetDDLScript(migrationID string) ([]types.ScriptFile, error) {
+	var files []types.ScriptFile
+	err := s.db.Select(&files, "SELECT file_name, content FROM ddl_scripts WHERE migration_id = ? ORDER BY position", migrationID)
+	return files, err
+}
+
 // Log methods
 
 // AddLog adds a log entry
@@ -379,6 +812,89 @@ func (s *Storage) GetLogsByLevel(migrationID string, level types.LogLevel, limit
 	return logs, err
 }
 
+// Type mapping rule set methods
+
+// SaveTypeMappingRuleSet stores a rule set, replacing the one with the same name
+func (s *Storage) SaveTypeMappingRuleSet(set *types.TypeMappingRuleSet) error {
+	rulesJSON, err := json.Marshal(set.Rules)
+	if err != nil {
+		return err
+	}
+
+	// 同名規則集視為更新，保留原 ID，讓選用它的遷移設定仍然有效
+	var existingID string
+	err = s.db.Get(&existingID, "SELECT id FROM type_mapping_rule_sets WHERE name = ?", set.Name)
+	switch {
+	case err == nil:
+		set.ID = existingID
+	case err != sql.ErrNoRows:
+		return err
+	case set.ID == "":
+		set.ID = uuid.New().String()
+	}
+	now := time.Now()
+	set.UpdatedAt = now
+
+	_, err = s.db.Exec(`
+		INSERT INTO type_mapping_rule_sets (id, name, description, rules_json, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			rules_json = excluded.rules_json,
+			updated_at = excluded.updated_at
+	`, set.ID, set.Name, set.Description, string(rulesJSON), now, now)
+	if err != nil {
+		return err
+	}
+	return s.db.Get(&set.CreatedAt, "SELECT created_at FROM type_mapping_rule_sets WHERE id = ?", set.ID)
+}
+
+// GetTypeMappingRuleSet retrieves a rule set by ID, or nil when it does not exist
+func (s *Storage) GetTypeMappingRuleSet(id string) (*types.TypeMappingRuleSet, error) {
+	sets, err := s.queryTypeMappingRuleSets("WHERE id = ?", id)
+	if err != nil || len(sets) == 0 {
+		return nil, err
+	}
+	return &sets[0], nil
+}
+
+// GetTypeMappingRuleSets retrieves all rule sets ordered by name
+func (s *Storage) GetTypeMappingRuleSets() ([]types.TypeMappingRuleSet, error) {
+	return s.queryTypeMappingRuleSets("ORDER BY name")
+}
+
+// queryTypeMappingRuleSets reads the rule sets selected by a WHERE or ORDER BY clause
+func (s *Storage) queryTypeMappingRuleSets(clause string, args ...interface{}) ([]types.TypeMappingRuleSet, error) {
+	rows, err := s.db.Query(`
+		SELECT id, name, description, rules_json, created_at, updated_at
+		FROM type_mapping_rule_sets `+clause, args...)
+	if err != nil {
+		return []types.TypeMappingRuleSet{}, err
+	}
+	defer rows.Close()
+
+	sets := []types.TypeMappingRuleSet{}
+	for rows.Next() {
+		var set types.TypeMappingRuleSet
+		var rulesJSON string
+		if err := rows.Scan(&set.ID, &set.Name, &set.Description, &rulesJSON, &set.CreatedAt, &set.UpdatedAt); err != nil {
+			return []types.TypeMappingRuleSet{}, err
+		}
+		if err := json.Unmarshal([]byte(rulesJSON), &set.Rules); err != nil {
+			return []types.TypeMappingRuleSet{}, err
+		}
+		sets = append(sets, set)
+	}
+	return sets, rows.Err()
+}
+
+// DeleteTypeMappingRuleSet deletes a rule set. Migrations that used it keep a copy of its rules.
+func (s *Storage) DeleteTypeMappingRuleSet(id string) error {
+	_, err := s.db.Exec("DELETE FROM type_mapping_rule_sets WHERE id = ?", id)
+	return err
+}
+
 // Validation methods
 
 // CreateValidation creates a validation record
@@ -33,13 +33,15 @@ type ConnectionTestResult struct {
 
 // TableInfo represents metadata about a database table
 type TableInfo struct {
-	Schema      string       `json:"schema"`
-	Name        string       `json:"name"`
-	RowCount    int64        `json:"rowCount"`
-	Columns     []ColumnInfo `json:"columns"`
-	PrimaryKey  []string     `json:"primaryKey"`
-	ForeignKeys []ForeignKey `json:"foreignKeys"`
-	Indexes     []IndexInfo  `json:"indexes"`
+	Schema              string       `json:"schema"`
+	Name                string       `json:"name"`
+	RowCount            int64        `json:"rowCount"`
+	Columns             []ColumnInfo `json:"columns"`
+	PrimaryKey          []string     `json:"primaryKey"`
+	PrimaryKeyClustered bool         `json:"primaryKeyClustered"` // 來源主鍵為叢集索引
+	UniqueKey           *IndexInfo   `json:"uniqueKey,omitempty"` // 無主鍵時可唯一識別資料列的唯一索引（鍵欄位皆不可為 NULL、無篩選條件）
+	ForeignKeys         []ForeignKey `json:"foreignKeys"`
+	Indexes             []IndexInfo  `json:"indexes"`
 }
 
 // ColumnInfo represents metadata about a database column
@@ -53,6 +55,8 @@ type ColumnInfo struct {
 	IsIdentity   bool    `json:"isIdentity"`
 	DefaultValue *string `json:"defaultValue"`
 	IsPrimaryKey bool    `json:"isPrimaryKey"`
+	Collation    string  `json:"collation,omitempty"` // 字串欄位的定序，如 Chinese_Taiwan_Stroke_CI_AS
+	CodePage     int     `json:"codePage,omitempty"`  // char/varchar/text 的字碼頁，0 表示 Unicode 或非字串欄位
 }
 
 // ForeignKey represents a foreign key constraint
@@ -155,8 +159,140 @@ type MigrationConfig struct {
 	BatchSize              int      `json:"batchSize"`
 	ParallelTables         int      `json:"parallelTables"`
 	DropTargetIfExists     bool     `json:"dropTargetIfExists"`
+	SplitTableRows         int64    `json:"splitTableRows"`              // 行數達此門檻的表格依主鍵範圍切分並行複製
+	RangeParallelism       int      `json:"rangeParallelism"`            // 單一表格的範圍並行數（<= 1 表示不切分）
+	ParentMigrationID      string   `json:"parentMigrationId,omitempty"` // 重跑時的原始遷移 ID
+	SyncMode               string   `json:"syncMode"`                    // full（完整複製）、delta（依 rowversion 增量同步）或 replicate（持續複寫）
+	ReplicationMethod      string   `json:"replicationMethod"`           // 持續複寫的變更來源：ct（Change Tracking）或 cdc
+	ReplicationPollSeconds int      `json:"replicationPollSeconds"`      // 持續複寫的輪詢間隔（秒）
+	DryRun                 bool     `json:"dryRun"`                      // 只產生 DDL 腳本供審閱，不連線也不變更目標資料庫
+	LoadMode               string   `json:"loadMode"`                    // 目標表格已有資料時的載入方式：append、truncate 或 upsert
+	StagingSwap            bool     `json:"stagingSwap"`                 // 先載入隱藏的暫存表並建立索引，完成後在單一交易內換成正式表格
+	QuarantineBadRows      bool     `json:"quarantineBadRows"`           // 批次寫入失敗時以二分法找出問題資料列並隔離，其餘資料繼續載入
+	QuarantineMaxRows      int      `json:"quarantineMaxRows"`           // 單一表格隔離超過此筆數即視為失敗
+	TableOrder             string   `json:"tableOrder"`                  // 表格處理順序：manual（使用者排序）或 fk（依外鍵相依排序）
+	DeferIndexes           bool     `json:"deferIndexes"`                // 只在建表時建立主鍵，次要索引於資料載入後再建立
+	IndexParallelism       int      `json:"indexParallelism"`            // 延後建立索引時同時處理的表格數
+	MaintenanceWorkMemMB   int      `json:"maintenanceWorkMemMB"`        // 建立索引時的 maintenance_work_mem（MB，0 表示使用伺服器設定）
+	ForeignKeysNotValid    bool     `json:"foreignKeysNotValid"`         // 外鍵先以 NOT VALID 建立，再並行執行 VALIDATE CONSTRAINT
+	ValidateParallelism    int      `json:"validateParallelism"`         // 同時驗證外鍵的表格數
+	BulkLoadProfile        bool     `json:"bulkLoadProfile"`             // 資料載入連線使用 synchronous_commit=off 並提高 maintenance_work_mem
+	UnloggedTables         bool     `json:"unloggedTables"`              // 以 UNLOGGED 建立表格，載入完成後再切換為 LOGGED
+	SkipForeignKeyChecks   bool     `json:"skipForeignKeyChecks"`        // 資料載入連線設定 session_replication_role=replica，略過既有外鍵（需 superuser）
+	AnalyzeTables          bool     `json:"analyzeTables"`               // 載入完成後對每張表執行 ANALYZE，讓查詢規劃器取得統計資訊
+	VacuumTables           bool     `json:"vacuumTables"`                // 載入完成後對每張表執行 VACUUM
+	ClusterTables          bool     `json:"clusterTables"`               // 依來源的叢集索引執行 CLUSTER，重排資料的實體順序
+	ThrottleRowsPerSec     int      `json:"throttleRowsPerSec"`          // 來源讀取速率上限（列/秒，0 表示不限），執行中可調整
+	ThrottleMBPerSec       float64  `json:"throttleMBPerSec"`            // 來源讀取速率上限（MB/秒，0 表示不限），執行中可調整
+	TimeWindows            string   `json:"timeWindows"`                 // 允許執行的時段（本地時間），例如 "22:00-06:00"；時段外自動暫停
+	SnapshotMode           string   `json:"snapshotMode"`                // 來源一致性讀取：off、isolation（SNAPSHOT 隔離交易）或 database（資料庫快照）
+	UndecodableText        string   `json:"undecodableText"`             // 舊字碼頁（如 CP950）字串含無法解碼的位元組時：replace（以 U+FFFD 取代並記錄）或 reject（隔離該列）
+	SourceTimeZone         string   `json:"sourceTimeZone"`              // datetime/datetime2/smalldatetime 所屬時區（IANA 名稱，如 Asia/Taipei），用於預設值與 TIMESTAMPTZ 換算
+	TimestampTZ            bool     `json:"timestampTz"`                 // datetime 類型對應為 TIMESTAMPTZ，資料依 SourceTimeZone 換算為時間點
+	TypeMappingRuleSet     string   `json:"typeMappingRuleSet"`          // 型別對應規則集 ID，空白表示使用內建對應
+	NamingPolicy           string   `json:"namingPolicy"`                // 目標識別字的命名方式：preserve（沿用來源）、lowercase 或 snake_case
+
+	// 開始遷移時複製的規則集內容；續傳與驗證沿用這份規則，不受規則集之後的修改影響
+	TypeMappingRules []TypeMappingRule `json:"typeMappingRules,omitempty"`
+
+	// 個別識別字的目標名稱，優先於 NamingPolicy；鍵為來源的 schema、schema.table 或 schema.table.column（不分大小寫）
+	IdentifierRenames map[string]string `json:"identifierRenames,omitempty"`
+}
+
+// ScriptFile is one .sql file of a dry-run DDL script
+type ScriptFile struct {
+	Name    string `json:"name" db:"file_name"`
+	Content string `json:"content" db:"content"`
+}
+
+// Sync modes of a migration
+const (
+	SyncModeFull      = "full"      // 完整複製所有資料
+	SyncModeDelta     = "delta"     // 只複製 rowversion 高於上次水位的資料並 upsert 至目標
+	SyncModeReplicate = "replicate" // 持續輪詢 Change Tracking / CDC 並套用變更，直到切換（cutover）
+)
+
+// Load modes for target tables that already exist
+const (
+	LoadModeAppend   = "append"   // 直接 COPY 附加資料，重複主鍵會失敗
+	LoadModeTruncate = "truncate" // 複製前先 TRUNCATE 目標表格，保留表格與相依物件
+	LoadModeUpsert   = "upsert"   // COPY 至暫存表後以 INSERT ... ON CONFLICT (pk) DO UPDATE 合併
+)
+
+// Table orders of a migration
+const (
+	TableOrderManual     = "manual" // 依使用者拖曳的順序（未指定時依 sys.tables 順序）
+	TableOrderForeignKey = "fk"     // 依外鍵拓撲排序，被參照的表格先載入
+)
+
+// Snapshot modes for consistent source reads across tables
+const (
+	SnapshotModeOff       = "off"       // 每個分頁各自讀取最新資料
+	SnapshotModeIsolation = "isolation" // 所有資料在單一 SNAPSHOT 隔離交易中讀取（逐表進行）
+	SnapshotModeDatabase  = "database"  // 從本工具建立、完成後刪除的資料庫快照讀取
+)
+
+// Policies for non-Unicode strings holding bytes their code page cannot decode
+const (
+	UndecodableTextReplace = "replace" // 以 U+FFFD 取代無法解碼的位元組，並記錄受影響的資料列
+	UndecodableTextReject  = "reject"  // 視為資料列錯誤，交由隔離（quarantine）處理
+)
+
+// Naming policies for the identifiers created on the target
+const (
+	NamingPolicyPreserve  = "preserve"   // 與來源相同（含大小寫），查詢時須加引號
+	NamingPolicyLowercase = "lowercase"  // 轉為小寫，如 OrderDetails -> orderdetails
+	NamingPolicySnakeCase = "snake_case" // 依字詞邊界轉為小寫底線分隔，如 OrderDetails -> order_details、CustomerID -> customer_id
+)
+
+// NameMapping is one entry of the mapping document between source and target identifiers
+type NameMapping struct {
+	Kind   string `json:"kind"`   // schema、table、column、index 或 foreign_key
+	Source string `json:"source"` // 來源的完整名稱，如 dbo.OrderDetails.CustomerID
+	Target string `json:"target"` // 目標的完整名稱，如 dbo.order_details.customer_id
+}
+
+// Value casts of a type mapping rule, applied to the converted source value before it is written
+const (
+	TypeCastNone        = "none"        // 不轉換，沿用來源型別的轉換結果
+	TypeCastText        = "text"        // 格式化為字串
+	TypeCastInteger     = "integer"     // 轉為整數，bit 為 0/1，小數須為整數值
+	TypeCastNumeric     = "numeric"     // 轉為精確數值
+	TypeCastBoolean     = "boolean"     // 非 0 為 true
+	TypeCastMoney       = "money"       // 四捨五入至分，以 PostgreSQL money 的內部格式寫入
+	TypeCastTimestampTZ = "timestamptz" // 牆上時間依來源時區換算為時間點
+)
+
+// TypeMappingRule overrides the PostgreSQL type of the columns it matches.
+// Empty criteria match anything; when several rules match a column, the one naming a column wins over
+// one naming a table, which wins over a type-only rule, and among equals the first one listed wins.
+type TypeMappingRule struct {
+	SourceType string `json:"sourceType,omitempty" yaml:"sourceType,omitempty"` // MSSQL 型別，如 nvarchar（不分大小寫）
+	Length     *int   `json:"length,omitempty" yaml:"length,omitempty"`         // 宣告的字元或位元組長度，-1 表示 (max)
+	Precision  *int   `json:"precision,omitempty" yaml:"precision,omitempty"`
+	Scale      *int   `json:"scale,omitempty" yaml:"scale,omitempty"`
+	Table      string `json:"table,omitempty" yaml:"table,omitempty"`   // schema.table 或 table 樣式，* 與 ? 為萬用字元（不分大小寫）
+	Column     string `json:"column,omitempty" yaml:"column,omitempty"` // 欄位名稱樣式，* 與 ? 為萬用字元（不分大小寫）
+	TargetType string `json:"targetType" yaml:"targetType"`             // PostgreSQL 型別，如 VARCHAR、TIMESTAMPTZ(3)、MONEY
+	Cast       string `json:"cast,omitempty" yaml:"cast,omitempty"`     // 值的轉換（TypeCast*）；空白時依來源與目標型別推斷
+}
+
+// TypeMappingRuleSet is a named list of type mapping rules a migration can select
+type TypeMappingRuleSet struct {
+	ID          string            `json:"id" yaml:"-"`
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description" yaml:"description,omitempty"`
+	Rules       []TypeMappingRule `json:"rules" yaml:"rules"`
+	CreatedAt   time.Time         `json:"createdAt" yaml:"-"`
+	UpdatedAt   time.Time         `json:"updatedAt" yaml:"-"`
 }
 
+// Replication methods used by SyncModeReplicate
+const (
+	ReplicationMethodCT  = "ct"  // SQL Server Change Tracking
+	ReplicationMethodCDC = "cdc" // SQL Server Change Data Capture
+)
+
 // MigrationRecord represents a migration job record
 type MigrationRecord struct {
 	ID              string          `json:"id" db:"id"`
@@ -172,6 +308,7 @@ type MigrationRecord struct {
 	CompletedTables int             `json:"completedTables" db:"completed_tables"`
 	TotalRows       int64           `json:"totalRows" db:"total_rows"`
 	MigratedRows    int64           `json:"migratedRows" db:"migrated_rows"`
+	ParentID        string          `json:"parentId" db:"parent_migration_id"` // 重跑來源的遷移 ID（空字串表示非重跑）
 }
 
 // TableMigrationState represents the migration config of a single table
@@ -182,6 +319,118 @@ type TableMigrationState struct {
 	TableName    string `json:"tableName" db:"table_name"`
 	SchemaName   string `json:"schemaName" db:"schema_name"`
 	MigrateOrder int    `json:"migrateOrder" db:"migrate_order"`
+	ReadStrategy string `json:"readStrategy" db:"read_strategy"` // 讀取來源所用的分頁策略（keyset / unique-index / single-pass）
+}
+
+// TableResult is the last status logged for a table in a migration run
+type TableResult struct {
+	TableName       string `json:"tableName" db:"table_name"`
+	Status          string `json:"status" db:"status"`
+	ErrorMessage    string `json:"errorMessage" db:"error_message"`
+	QuarantinedRows int64  `json:"quarantinedRows" db:"-"` // 隔離的問題資料列數
+}
+
+// QuarantinedRow is a source row that could not be written to the target
+type QuarantinedRow struct {
+	ID          int64     `json:"id" db:"id"`
+	MigrationID string    `json:"migrationId" db:"migration_id"`
+	SchemaName  string    `json:"schemaName" db:"schema_name"`
+	TableName   string    `json:"tableName" db:"table_name"`
+	SourceKey   string    `json:"sourceKey" db:"source_key"` // 來源主鍵（JSON，無主鍵時為空）
+	RowData     string    `json:"rowData" db:"row_data"`     // 整列資料（JSON，欄位名稱對應值）
+	Error       string    `json:"error" db:"error"`          // PostgreSQL 回傳的錯誤
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
+
+// TextIssue is a non-Unicode string value that held undecodable bytes or end-user-defined characters
+type TextIssue struct {
+	ID          int64     `json:"id" db:"id"`
+	MigrationID string    `json:"migrationId" db:"migration_id"`
+	SchemaName  string    `json:"schemaName" db:"schema_name"`
+	TableName   string    `json:"tableName" db:"table_name"`
+	ColumnName  string    `json:"columnName" db:"column_name"`
+	SourceKey   string    `json:"sourceKey" db:"source_key"` // 來源主鍵（JSON，無主鍵時為空）
+	Kind        string    `json:"kind" db:"kind"`            // undecodable 或 private-use
+	CodePage    int       `json:"codePage" db:"code_page"`
+	RawBytes    string    `json:"rawBytes" db:"raw_bytes"` // 原始位元組（十六進位）
+	Text        string    `json:"text" db:"text"`          // 解碼後寫入目標的字串
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
+
+// IndexBuild records how a deferred index build went
+type IndexBuild struct {
+	MigrationID string    `json:"migrationId" db:"migration_id"`
+	SchemaName  string    `json:"schemaName" db:"schema_name"`
+	TableName   string    `json:"tableName" db:"table_name"`
+	IndexName   string    `json:"indexName" db:"index_name"`
+	DurationMs  int64     `json:"durationMs" db:"duration_ms"`
+	Error       string    `json:"error" db:"error"` // 建立失敗時的錯誤，成功為空
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
+
+// ForeignKeyCheck records the outcome of creating or validating a foreign key on the target
+type ForeignKeyCheck struct {
+	MigrationID     string    `json:"migrationId" db:"migration_id"`
+	SchemaName      string    `json:"schemaName" db:"schema_name"`
+	TableName       string    `json:"tableName" db:"table_name"`
+	ConstraintName  string    `json:"constraintName" db:"constraint_name"`
+	ReferencedTable string    `json:"referencedTable" db:"referenced_table"` // 被參照的表格（schema.table）
+	Valid           bool      `json:"valid" db:"valid"`
+	OrphanCount     int64     `json:"orphanCount" db:"orphan_count"` // 找不到被參照資料的資料列數
+	SampleKeys      string    `json:"sampleKeys" db:"sample_keys"`   // 孤兒資料的外鍵值範例（JSON 陣列）
+	Error           string    `json:"error" db:"error"`
+	DurationMs      int64     `json:"durationMs" db:"duration_ms"`
+	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Checkpoint phases of a table
+const (
+	CheckpointPhaseSchema    = "schema"    // 目標表已建立，尚未開始複製資料
+	CheckpointPhaseData      = "data"      // 資料複製中，LastKey / RowsCopied 為最後一次提交的位置
+	CheckpointPhaseCompleted = "completed" // 資料複製完成
+)
+
+// TableCheckpoint records how far a table, or one key range of it, has been migrated
+// 用於中斷續傳：Part 0 代表整張表，範圍切分時 Part 1..N 代表各主鍵範圍
+type TableCheckpoint struct {
+	MigrationID string    `json:"migrationId" db:"migration_id"`
+	SchemaName  string    `json:"schemaName" db:"schema_name"`
+	TableName   string    `json:"tableName" db:"table_name"`
+	Part        int       `json:"part" db:"part"`
+	Phase       string    `json:"phase" db:"phase"`
+	RangeFrom   string    `json:"rangeFrom" db:"range_from"` // 編碼後的範圍下界（僅範圍）
+	RangeTo     string    `json:"rangeTo" db:"range_to"`     // 編碼後的範圍上界（僅範圍）
+	LastKey     string    `json:"lastKey" db:"last_key"`     // 編碼後的最後提交主鍵
+	RowsCopied  int64     `json:"rowsCopied" db:"rows_copied"`
+	RowVersion  string    `json:"rowVersion" db:"row_version"` // 開始完整複製前的 MIN_ACTIVE_ROWVERSION（十六進位），完成後成為增量同步水位
+	ReplStart   string    `json:"replStart" db:"repl_start"`   // 開始完整複製前的 CT / CDC 位置（JSON），完成後成為持續複寫起點
+	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// SyncWatermark is the rowversion high-water mark of a table synced from a source to a target database.
+// Every source row with a rowversion below HighWater has been copied to the target.
+type SyncWatermark struct {
+	SourceDatabase string    `json:"sourceDatabase" db:"source_database"`
+	TargetDatabase string    `json:"targetDatabase" db:"target_database"`
+	SchemaName     string    `json:"schemaName" db:"schema_name"`
+	TableName      string    `json:"tableName" db:"table_name"`
+	VersionColumn  string    `json:"versionColumn" db:"version_column"`
+	HighWater      string    `json:"highWater" db:"high_water"`     // rowversion 水位（十六進位）
+	MigrationID    string    `json:"migrationId" db:"migration_id"` // 最後更新水位的遷移
+	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// ReplicationPosition is the last change position of a table applied to a target by continuous replication.
+// Position is a Change Tracking version (decimal) or a CDC LSN (hex) depending on Method.
+type ReplicationPosition struct {
+	SourceDatabase string    `json:"sourceDatabase" db:"source_database"`
+	TargetDatabase string    `json:"targetDatabase" db:"target_database"`
+	SchemaName     string    `json:"schemaName" db:"schema_name"`
+	TableName      string    `json:"tableName" db:"table_name"`
+	Method         string    `json:"method" db:"method"`
+	Position       string    `json:"position" db:"position"`
+	MigrationID    string    `json:"migrationId" db:"migration_id"`
+	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
 }
 
 // LogLevel represents the log level
@@ -228,6 +477,12 @@ type ValidationConfig struct {
 	SampleComparison   bool     `json:"sampleComparison"`
 	SampleSize         int      `json:"sampleSize"`
 	Tables             []string `json:"tables,omitempty"` // Empty means all tables
+	SourceTimeZone     string   `json:"sourceTimeZone"`   // 與遷移相同的時區設定；指定 MigrationID 時沿用該次遷移的設定
+	TimestampTZ        bool     `json:"timestampTz"`
+	TypeMappingRuleSet string   `json:"typeMappingRuleSet"` // 型別對應規則集 ID；指定 MigrationID 時沿用該次遷移的規則
+	NamingPolicy       string   `json:"namingPolicy"`       // 目標識別字的命名方式；指定 MigrationID 時沿用該次遷移的命名與更名
+
+	IdentifierRenames map[string]string `json:"identifierRenames,omitempty"`
 }
 
 // ValidationResult represents the result of validating a table
@@ -5,14 +5,17 @@ import (
 	"crypto/md5"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"adaru-db-tool/internal/connection"
+	"adaru-db-tool/internal/schema/converter"
 	"adaru-db-tool/internal/storage"
 	"adaru-db-tool/internal/types"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
@@ -23,6 +26,9 @@ type Validator struct {
 	targetConn *connection.PostgresConnection
 	storage    *storage.Storage
 	config     *types.ValidationConfig
+	timePolicy converter.TimePolicy // 與遷移相同的 datetime 時區處理，來源值依此轉換後再比較
+	typeRules  *converter.TypeRules // 與遷移相同的型別對應規則
+	names      *converter.Namer     // 與遷移相同的目標識別字命名，目標端查詢使用目標名稱
 }
 
 // NewValidator creates a new Validator
@@ -37,6 +43,52 @@ func NewValidator(ctx context.Context, storage *storage.Storage) *Validator {
 func (v *Validator) Configure(sourceConnString, targetConnString string, config *types.ValidationConfig) error {
 	v.config = config
 
+	// 驗證遷移結果時沿用該次遷移的時區設定、型別對應規則與命名，來源值與目標名稱才會與寫入時一致
+	var rules []types.TypeMappingRule
+	if config.MigrationID != "" {
+		record, err := v.storage.GetMigration(config.MigrationID)
+		if err != nil {
+			return fmt.Errorf("failed to load migration %s: %w", config.MigrationID, err)
+		}
+		if record != nil {
+			var migrationConfig types.MigrationConfig
+			if err := json.Unmarshal([]byte(record.Config), &migrationConfig); err != nil {
+				return fmt.Errorf("invalid migration config: %w", err)
+			}
+			config.SourceTimeZone = migrationConfig.SourceTimeZone
+			config.TimestampTZ = migrationConfig.TimestampTZ
+			config.TypeMappingRuleSet = migrationConfig.TypeMappingRuleSet
+			rules = migrationConfig.TypeMappingRules
+			config.NamingPolicy = migrationConfig.NamingPolicy
+			config.IdentifierRenames = migrationConfig.IdentifierRenames
+		}
+	}
+	timePolicy, err := converter.NewTimePolicy(config.SourceTimeZone, config.TimestampTZ)
+	if err != nil {
+		return err
+	}
+	v.timePolicy = timePolicy
+	if rules == nil && config.TypeMappingRuleSet != "" {
+		set, err := v.storage.GetTypeMappingRuleSet(config.TypeMappingRuleSet)
+		if err != nil {
+			return fmt.Errorf("failed to load type mapping rule set: %w", err)
+		}
+		if set == nil {
+			return fmt.Errorf("type mapping rule set %s not found", config.TypeMappingRuleSet)
+		}
+		rules = set.Rules
+	}
+	typeRules, err := converter.NewTypeRules(rules)
+	if err != nil {
+		return fmt.Errorf("invalid type mapping rules: %w", err)
+	}
+	v.typeRules = typeRules
+	names, err := converter.NewNamer(config.NamingPolicy, config.IdentifierRenames)
+	if err != nil {
+		return fmt.Errorf("invalid identifier naming: %w", err)
+	}
+	v.names = names
+
 	// Connect to source
 	v.sourceConn = connection.NewMSSQLConnection(sourceConnString)
 	if err := v.sourceConn.Connect(v.ctx); err != nil {
@@ -183,7 +235,7 @@ func (v *Validator) validateRowCount(ctx context.Context, table types.TableInfo,
 	result.SourceRowCount = sourceCount
 
 	// Get target row count
-	targetCount, err := v.targetConn.GetRowCount(ctx, table.Schema, table.Name)
+	targetCount, err := v.targetConn.GetRowCount(ctx, v.names.Schema(table.Schema), v.names.Table(table.Schema, table.Name))
 	if err != nil {
 		return err
 	}
@@ -225,7 +277,8 @@ func (v *Validator) validateChecksum(ctx context.Context, table *types.TableInfo
 	result.SourceChecksum = sourceChecksum
 
 	// Calculate target checksum
-	targetChecksum, err := v.targetConn.GetTableChecksum(ctx, table.Schema, table.Name, columns, orderByCol)
+	targetChecksum, err := v.targetConn.GetTableChecksum(ctx, v.names.Schema(table.Schema), v.names.Table(table.Schema, table.Name),
+		v.names.Columns(table.Schema, table.Name, columns), v.names.Column(table.Schema, table.Name, orderByCol))
 	if err != nil {
 		return err
 	}
@@ -317,19 +370,41 @@ func (v *Validator) validateSampleData(ctx context.Context, table *types.TableIn
 		}
 	}
 
+	// 無主鍵時改用唯一索引定位資料列；都沒有時無法逐列比對，以第一欄比對可能對到錯誤的列
+	if len(pkColumns) == 0 && table.UniqueKey != nil {
+		pkColumns = table.UniqueKey.Columns
+	}
 	if len(pkColumns) == 0 {
-		pkColumns = append(pkColumns, columns[0])
+		return fmt.Errorf("%s.%s has no primary key or unique index to locate sample rows", table.Schema, table.Name)
+	}
+
+	// 來源值先經過與寫入時相同的轉換，再與目標值比較
+	byName := make(map[string]types.ColumnInfo)
+	for _, col := range table.Columns {
+		byName[col.Name] = col
+	}
+	keyInfos := make([]types.ColumnInfo, len(pkColumns))
+	for i, pk := range pkColumns {
+		keyInfos[i] = byName[pk]
 	}
+	values := converter.NewValueConverter(table.Columns).
+		WithTimePolicy(v.timePolicy).
+		WithRules(v.typeRules, table.Schema, table.Name)
+	keys := converter.NewValueConverter(keyInfos).
+		WithTimePolicy(v.timePolicy).
+		WithRules(v.typeRules, table.Schema, table.Name)
 
 	// Get sample primary keys from source
 	pkList := make([]string, len(pkColumns))
+	keyList := make([]string, len(pkColumns))
 	for i, pk := range pkColumns {
 		pkList[i] = fmt.Sprintf("[%s]", pk)
+		keyList[i] = converter.SourceExpression(keyInfos[i], "")
 	}
 
 	query := fmt.Sprintf(`
 		SELECT TOP %d %s FROM [%s].[%s] ORDER BY %s
-	`, sampleSize, strings.Join(pkList, ", "), table.Schema, table.Name, pkList[0])
+	`, sampleSize, strings.Join(keyList, ", "), table.Schema, table.Name, strings.Join(pkList, ", "))
 
 	rows, err := v.sourceConn.DB().QueryContext(ctx, query)
 	if err != nil {
@@ -356,7 +431,7 @@ func (v *Validator) validateSampleData(ctx context.Context, table *types.TableIn
 	var mismatchDetails []types.MismatchDetail
 
 	for _, keyValues := range sampleKeys {
-		match, detail := v.compareRow(ctx, table, columns, pkColumns, keyValues)
+		match, detail := v.compareRow(ctx, table, columns, pkColumns, keyValues, values, keys)
 		if match {
 			matches++
 		} else {
@@ -379,7 +454,7 @@ func (v *Validator) validateSampleData(ctx context.Context, table *types.TableIn
 }
 
 // compareRow compares a single row between source and target
-func (v *Validator) compareRow(ctx context.Context, table *types.TableInfo, columns, pkColumns []string, keyValues []interface{}) (bool, *types.MismatchDetail) {
+func (v *Validator) compareRow(ctx context.Context, table *types.TableInfo, columns, pkColumns []string, keyValues []interface{}, values, keys *converter.ValueConverter) (bool, *types.MismatchDetail) {
 	// Build WHERE clause
 	whereParts := make([]string, len(pkColumns))
 	for i, pk := range pkColumns {
@@ -387,9 +462,9 @@ func (v *Validator) compareRow(ctx context.Context, table *types.TableInfo, colu
 	}
 
 	// Get source row
-	colList := make([]string, len(columns))
-	for i, col := range columns {
-		colList[i] = fmt.Sprintf("[%s]", col)
+	colList := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		colList[i] = converter.SourceExpression(col, "")
 	}
 
 	sourceQuery := fmt.Sprintf(`
@@ -415,15 +490,30 @@ func (v *Validator) compareRow(ctx context.Context, table *types.TableInfo, colu
 		}
 	}
 
+	sourceValues, err := values.Row(sourceValues)
+	if err != nil {
+		return false, &types.MismatchDetail{
+			PrimaryKey: keyValues,
+			Type:       "source_error",
+		}
+	}
+	targetKey, err := keys.Row(keyValues)
+	if err != nil {
+		return false, &types.MismatchDetail{
+			PrimaryKey: keyValues,
+			Type:       "source_error",
+		}
+	}
+
 	// Get target row
-	targetQuery := v.buildPostgresQuery(table.Schema, table.Name, columns, pkColumns, keyValues)
+	targetQuery := v.buildPostgresQuery(table, pkColumns)
 	targetValues := make([]interface{}, len(columns))
 	targetPtrs := make([]interface{}, len(columns))
 	for i := range targetValues {
 		targetPtrs[i] = &targetValues[i]
 	}
 
-	if err := v.targetConn.Pool().QueryRow(ctx, targetQuery, keyValues...).Scan(targetPtrs...); err != nil {
+	if err := v.targetConn.Pool().QueryRow(ctx, targetQuery, targetKey...).Scan(targetPtrs...); err != nil {
 		return false, &types.MismatchDetail{
 			PrimaryKey: keyValues,
 			Type:       "missing",
@@ -453,21 +543,28 @@ func (v *Validator) compareRow(ctx context.Context, table *types.TableInfo, colu
 	return true, nil
 }
 
-// buildPostgresQuery builds a SELECT query for PostgreSQL
-func (v *Validator) buildPostgresQuery(schema, tableName string, columns, pkColumns []string, keyValues []interface{}) string {
+// buildPostgresQuery builds a SELECT query for PostgreSQL that reads the columns of a source table
+// by its key, under their target names
+func (v *Validator) buildPostgresQuery(table *types.TableInfo, pkColumns []string) string {
 	whereParts := make([]string, len(pkColumns))
-	for i, pk := range pkColumns {
+	for i, pk := range v.names.Columns(table.Schema, table.Name, pkColumns) {
 		whereParts[i] = fmt.Sprintf("\"%s\" = $%d", pk, i+1)
 	}
 
-	colList := make([]string, len(columns))
-	for i, col := range columns {
-		colList[i] = fmt.Sprintf("\"%s\"", col)
+	target := v.names.TargetTable(*table)
+	colList := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		colList[i] = fmt.Sprintf("\"%s\"", target.Columns[i].Name)
+		// pgx 沒有 money 的解碼器，以 numeric 讀取再與來源換算的金額比較
+		rule := v.typeRules.Match(table.Schema, table.Name, col)
+		if converter.RuleCast(rule, col) == types.TypeCastMoney {
+			colList[i] += "::numeric"
+		}
 	}
 
 	return fmt.Sprintf(`
 		SELECT %s FROM "%s"."%s" WHERE %s
-	`, strings.Join(colList, ", "), schema, tableName, strings.Join(whereParts, " AND "))
+	`, strings.Join(colList, ", "), target.Schema, target.Name, strings.Join(whereParts, " AND "))
 }
 
 // valuesEqual compares two values, handling type differences
@@ -481,8 +578,8 @@ func (v *Validator) valuesEqual(a, b interface{}) bool {
 	}
 
 	// Convert to strings for comparison (handles most type differences)
-	aStr := fmt.Sprintf("%v", a)
-	bStr := fmt.Sprintf("%v", b)
+	aStr := comparableText(a)
+	bStr := comparableText(b)
 
 	// Handle common type conversions
 	// Boolean: MSSQL uses 0/1, PostgreSQL uses true/false
@@ -502,6 +599,34 @@ func (v *Validator) valuesEqual(a, b interface{}) bool {
 	return aStr == bStr
 }
 
+// comparableText formats a converted source value or a value scanned by pgx so equal values read the same
+func comparableText(v interface{}) string {
+	switch val := v.(type) {
+	case pgtype.UUID:
+		return uuidText(val.Bytes)
+	case [16]byte:
+		return uuidText(val)
+	case pgtype.Numeric:
+		text, err := val.Value()
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return fmt.Sprintf("%v", text)
+	case pgtype.Time:
+		return time.Duration(val.Microseconds * int64(time.Microsecond)).String()
+	case time.Time:
+		return val.UTC().Format(time.RFC3339Nano)
+	case []byte:
+		return hex.EncodeToString(val)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// uuidText formats UUID bytes in the canonical 8-4-4-4-12 form
+func uuidText(b [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
 // emitEvent emits an event to the frontend
 func (v *Validator) emitEvent(eventName string, data interface{}) {
 	runtime.EventsEmit(v.ctx, eventName, data)